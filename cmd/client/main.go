@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"time"
 
@@ -8,7 +10,6 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 const (
@@ -37,23 +38,44 @@ func main() {
 
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 
-	// cc1, err := grpc.Dial(serverAddress, grpc.WithInsecure())
-	cc1, err := grpc.Dial(serverAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// The server certificate is checked against TLS_CA_FILE. A client
+	// certificate in TLS_CERT_FILE and TLS_KEY_FILE stands in for the login.
+	certFile := os.Getenv("TLS_CERT_FILE")
+	creds, err := client.TransportCredentials(os.Getenv("TLS_CA_FILE"), certFile, os.Getenv("TLS_KEY_FILE"))
 	if err != nil {
-		log.Fatal().Msg("cannot not dial to the server.")
+		log.Fatal().Err(err).Msg("cannot load TLS credentials.")
 	}
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
 
-	authClient := client.NewAuthClient(cc1, username, password)
-	interceptor, err := client.NewAuthInterceptor(authClient, authMethods(), refreshDuration)
-	if err != nil {
-		log.Fatal().Msg("cannot create auth interceptor.")
+	if certFile == "" {
+		// cc1, err := grpc.Dial(serverAddress, grpc.WithInsecure())
+		cc1, err := grpc.Dial(serverAddress, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			log.Fatal().Msg("cannot not dial to the server.")
+		}
+
+		authClient := client.NewAuthClient(cc1)
+		_, err = authClient.Login(username, password)
+		if errors.Is(err, client.ErrSecondFactorRequired) {
+			var code string
+			fmt.Fprint(os.Stderr, "Authentication code: ")
+			_, err = fmt.Fscanln(os.Stdin, &code)
+			if err == nil {
+				_, err = authClient.CompleteLogin(code)
+			}
+		}
+		if err != nil {
+			log.Fatal().Err(err).Msg("cannot log in.")
+		}
+
+		interceptor, err := client.NewAuthInterceptor(authClient, authMethods(), refreshDuration)
+		if err != nil {
+			log.Fatal().Msg("cannot create auth interceptor.")
+		}
+		dialOptions = append(dialOptions, grpc.WithUnaryInterceptor(interceptor.Unary()))
 	}
 
-	cc2, err := grpc.Dial(
-		serverAddress,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithUnaryInterceptor(interceptor.Unary()),
-	)
+	cc2, err := grpc.Dial(serverAddress, dialOptions...)
 	if err != nil {
 		log.Fatal().Msg("cannot not dial to the server.")
 	}
@@ -19,6 +19,15 @@ import (
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	// "server fsck [flags]" checks the file storage once and exits,
+	// "server backfill-legacy-files [flags]" moves the content of the files
+	// uploaded before the blob store into it.
+	command := ""
+	if len(os.Args) > 1 && (os.Args[1] == "fsck" || os.Args[1] == "backfill-legacy-files") {
+		command = os.Args[1]
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
 	cfg, err := server.GetConfig()
 	if err != nil {
 		log.Fatal().Msg(fmt.Sprintf("Error while getting config. %s", err.Error()))
@@ -33,6 +42,8 @@ func main() {
 		syscall.SIGINT,
 		syscall.SIGTERM,
 		syscall.SIGQUIT)
+	reloadChan := make(chan os.Signal, 1)
+	signal.Notify(reloadChan, syscall.SIGHUP)
 
 	conn, err := sql.Open("postgres", cfg.DBAddress)
 	if err != nil {
@@ -41,6 +52,13 @@ func main() {
 
 	store := db.NewStore(conn)
 
+	switch command {
+	case "fsck":
+		os.Exit(runFsck(ctx, cfg, store))
+	case "backfill-legacy-files":
+		os.Exit(runBackfillLegacyFiles(ctx, cfg, store))
+	}
+
 	s, err := server.NewServer(ctx, cfg, store)
 	if err != nil {
 		log.Fatal().Msg(fmt.Sprintf("Could not get server. Error: %s", err))
@@ -49,6 +67,58 @@ func main() {
 	go s.StartServer(ctx)
 
 	log.Info().Msg(fmt.Sprintf("Listening socket: %s", cfg.Address))
-	<-sigChan
-	s.StopServer(ctx, cancel)
+	for {
+		select {
+		case <-reloadChan:
+			err := s.ReloadKeys()
+			if err != nil {
+				log.Error().Err(err).Msg("cannot reload keys")
+			}
+		case <-sigChan:
+			s.StopServer(ctx, cancel)
+			return
+		}
+	}
+}
+
+// runFsck prints the storage check report and returns the exit code, 1 when
+// problems are left unrepaired.
+func runFsck(ctx context.Context, cfg *server.Config, store db.Store) int {
+	report, err := server.Fsck(ctx, cfg, store)
+	if err != nil {
+		log.Error().Err(err).Msg("cannot check file storage")
+		return 2
+	}
+
+	fmt.Printf("stale files:     %d\n", len(report.StaleFiles))
+	fmt.Printf("partial content: %d\n", len(report.PartialContent))
+	fmt.Printf("orphan content:  %d\n", len(report.OrphanContent))
+	fmt.Printf("missing content: %d\n", len(report.MissingContent))
+	fmt.Printf("repaired:        %d\n", report.Repaired)
+
+	if report.Problems() > report.Repaired {
+		return 1
+	}
+	return 0
+}
+
+// runBackfillLegacyFiles prints the backfill report and returns the exit code,
+// 1 when the content of some legacy files was not found.
+func runBackfillLegacyFiles(ctx context.Context, cfg *server.Config, store db.Store) int {
+	report, err := server.BackfillLegacyFiles(ctx, cfg, store, server.LegacyStoragePath)
+	if err != nil {
+		log.Error().Err(err).Msg("cannot backfill legacy files")
+		return 2
+	}
+
+	fmt.Printf("migrated: %d\n", report.Migrated)
+	fmt.Printf("missing:  %d\n", len(report.Missing))
+	for _, file := range report.Missing {
+		fmt.Printf("  account %d: /%s/%s\n", file.AccountID, file.Filepath, file.Filename)
+	}
+
+	if len(report.Missing) > 0 {
+		return 1
+	}
+	return 0
 }
@@ -0,0 +1,16 @@
+CREATE TABLE "blobs" (
+  "hash" varchar PRIMARY KEY,
+  "size" bigint NOT NULL,
+  "refcount" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "files" ADD COLUMN "blob_hash" varchar;
+
+CREATE INDEX ON "files" ("blob_hash");
+
+COMMENT ON COLUMN "blobs"."hash" IS 'hex encoded sha256 of the content';
+
+COMMENT ON COLUMN "blobs"."refcount" IS 'number of files rows pointing to the blob';
+
+ALTER TABLE "files" ADD FOREIGN KEY ("blob_hash") REFERENCES "blobs" ("hash");
@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS account DROP COLUMN IF EXISTS quota_secrets;
+ALTER TABLE IF EXISTS account DROP COLUMN IF EXISTS quota_files;
+ALTER TABLE IF EXISTS account DROP COLUMN IF EXISTS quota_bytes;
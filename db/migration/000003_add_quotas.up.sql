@@ -0,0 +1,11 @@
+ALTER TABLE "account" ADD COLUMN "quota_bytes" bigint;
+
+ALTER TABLE "account" ADD COLUMN "quota_files" bigint;
+
+ALTER TABLE "account" ADD COLUMN "quota_secrets" bigint;
+
+COMMENT ON COLUMN "account"."quota_bytes" IS 'overrides the server default when set, zero means unlimited';
+
+COMMENT ON COLUMN "account"."quota_files" IS 'overrides the server default when set, zero means unlimited';
+
+COMMENT ON COLUMN "account"."quota_secrets" IS 'overrides the server default when set, zero means unlimited';
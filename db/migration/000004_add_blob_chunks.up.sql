@@ -0,0 +1,8 @@
+CREATE TABLE "blob_chunks" (
+  "hash" varchar NOT NULL,
+  "seq" int NOT NULL,
+  "data" bytea NOT NULL,
+  PRIMARY KEY ("hash", "seq")
+);
+
+COMMENT ON TABLE "blob_chunks" IS 'file content for the postgres storage backend';
@@ -0,0 +1,3 @@
+ALTER TABLE "blobs" ADD COLUMN "codec" varchar NOT NULL DEFAULT 'identity';
+
+COMMENT ON COLUMN "blobs"."codec" IS 'compression codec of the stored content: identity, gzip or zstd';
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS blobs DROP COLUMN IF EXISTS wrapped_key;
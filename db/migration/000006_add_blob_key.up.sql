@@ -0,0 +1,3 @@
+ALTER TABLE "blobs" ADD COLUMN "wrapped_key" bytea;
+
+COMMENT ON COLUMN "blobs"."wrapped_key" IS 'data key of the stored content wrapped with the server key, NULL for plaintext content';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS file_versions;
+ALTER TABLE IF EXISTS files DROP COLUMN IF EXISTS version;
+COMMENT ON COLUMN "blobs"."refcount" IS 'number of files rows pointing to the blob';
@@ -0,0 +1,23 @@
+CREATE TABLE "file_versions" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "file_id" bigint NOT NULL,
+  "version" bigint NOT NULL,
+  "blob_hash" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "files" ADD COLUMN "version" bigint NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX ON "file_versions" ("file_id", "version");
+
+CREATE INDEX ON "file_versions" ("created_at");
+
+COMMENT ON COLUMN "files"."version" IS 'number of the current content version, 0 before the first upload';
+
+COMMENT ON COLUMN "file_versions"."created_at" IS 'time the version was replaced by a newer one';
+
+COMMENT ON COLUMN "blobs"."refcount" IS 'number of files and file_versions rows pointing to the blob';
+
+ALTER TABLE "file_versions" ADD FOREIGN KEY ("file_id") REFERENCES "files" ("id");
+
+ALTER TABLE "file_versions" ADD FOREIGN KEY ("blob_hash") REFERENCES "blobs" ("hash");
@@ -0,0 +1,3 @@
+-- The file paths stay in the canonical form, it cannot be told what the
+-- clients sent before.
+DROP TABLE IF EXISTS directories;
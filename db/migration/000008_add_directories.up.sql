@@ -0,0 +1,41 @@
+CREATE TABLE "directories" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "path" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "directories" ("account_id", "path");
+
+COMMENT ON COLUMN "directories"."path" IS 'slash separated path without leading and trailing slashes';
+
+ALTER TABLE "directories" ADD FOREIGN KEY ("account_id") REFERENCES "account" ("id");
+
+-- The file paths were stored as the clients sent them. They are put in the
+-- canonical form first: names separated by single slashes, without leading or
+-- trailing slashes and without "." names. Files whose paths only differ in
+-- that form would collide, all but the oldest one get their id appended to
+-- the name.
+WITH canonical AS (
+  SELECT id, account_id, filename,
+    array_to_string(array_remove(array_remove(string_to_array(filepath, '/'), ''), '.'), '/') AS path
+  FROM files
+), ranked AS (
+  SELECT id, row_number() OVER (PARTITION BY account_id, path, filename ORDER BY id) AS n
+  FROM canonical
+)
+UPDATE files f
+SET filename = f.filename || '~' || f.id
+FROM ranked r
+WHERE r.id = f.id AND r.n > 1;
+
+UPDATE files
+SET filepath = array_to_string(array_remove(array_remove(string_to_array(filepath, '/'), ''), '.'), '/')
+WHERE filepath <> array_to_string(array_remove(array_remove(string_to_array(filepath, '/'), ''), '.'), '/');
+
+INSERT INTO directories (account_id, path)
+SELECT DISTINCT f.account_id, array_to_string((string_to_array(f.filepath, '/'))[1:n], '/')
+FROM files f,
+  generate_series(1, array_length(string_to_array(f.filepath, '/'), 1)) AS n
+WHERE f.filepath <> ''
+ON CONFLICT DO NOTHING;
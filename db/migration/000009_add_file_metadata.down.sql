@@ -0,0 +1,7 @@
+ALTER TABLE "files_metadata" DROP CONSTRAINT "files_metadata_file_id_fkey";
+
+ALTER TABLE "files_metadata" ADD FOREIGN KEY ("file_id") REFERENCES "files" ("id");
+
+DELETE FROM files_metadata WHERE system;
+
+ALTER TABLE "files_metadata" DROP COLUMN IF EXISTS "system";
@@ -0,0 +1,9 @@
+ALTER TABLE "files_metadata" ADD COLUMN "system" bool NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN "files_metadata"."system" IS 'recorded by the server on upload, the user cannot edit it';
+
+CREATE UNIQUE INDEX ON "files_metadata" ("file_id", "system", "key");
+
+ALTER TABLE "files_metadata" DROP CONSTRAINT "files_metadata_file_id_fkey";
+
+ALTER TABLE "files_metadata" ADD FOREIGN KEY ("file_id") REFERENCES "files" ("id") ON DELETE CASCADE;
@@ -0,0 +1,24 @@
+CREATE TABLE "share_links" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "file_id" bigint NOT NULL,
+  "token_id" varchar UNIQUE NOT NULL,
+  "password_hash" varchar,
+  "max_downloads" bigint NOT NULL DEFAULT 0,
+  "downloads" bigint NOT NULL DEFAULT 0,
+  "revoked" bool NOT NULL DEFAULT false,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "share_links" ("account_id");
+
+COMMENT ON COLUMN "share_links"."token_id" IS 'random part of the signed token, the token itself is not stored';
+
+COMMENT ON COLUMN "share_links"."password_hash" IS 'bcrypt hash of the link password, NULL for links without one';
+
+COMMENT ON COLUMN "share_links"."max_downloads" IS 'zero means unlimited';
+
+ALTER TABLE "share_links" ADD FOREIGN KEY ("account_id") REFERENCES "account" ("id");
+
+ALTER TABLE "share_links" ADD FOREIGN KEY ("file_id") REFERENCES "files" ("id") ON DELETE CASCADE;
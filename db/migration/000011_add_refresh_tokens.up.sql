@@ -0,0 +1,20 @@
+CREATE TABLE "refresh_tokens" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "family_id" varchar NOT NULL,
+  "token_hash" varchar UNIQUE NOT NULL,
+  "used" bool NOT NULL DEFAULT false,
+  "revoked" bool NOT NULL DEFAULT false,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "refresh_tokens" ("family_id");
+
+COMMENT ON COLUMN "refresh_tokens"."family_id" IS 'shared by the tokens issued by rotation from one login';
+
+COMMENT ON COLUMN "refresh_tokens"."token_hash" IS 'hex encoded sha256 of the token, the token itself is not stored';
+
+COMMENT ON COLUMN "refresh_tokens"."used" IS 'the token was exchanged for a new one, using it again revokes the family';
+
+ALTER TABLE "refresh_tokens" ADD FOREIGN KEY ("account_id") REFERENCES "account" ("id") ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS revoked_tokens;
+
+ALTER TABLE "account" DROP COLUMN IF EXISTS "session_epoch";
@@ -0,0 +1,16 @@
+ALTER TABLE "account" ADD COLUMN "session_epoch" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "account"."session_epoch" IS 'access tokens issued with a lower epoch are rejected';
+
+CREATE TABLE "revoked_tokens" (
+  "jti" varchar PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "revoked_tokens" ("expires_at");
+
+COMMENT ON COLUMN "revoked_tokens"."expires_at" IS 'expiry of the revoked token, the row is useless after it';
+
+ALTER TABLE "revoked_tokens" ADD FOREIGN KEY ("account_id") REFERENCES "account" ("id") ON DELETE CASCADE;
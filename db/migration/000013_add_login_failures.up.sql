@@ -0,0 +1,16 @@
+CREATE TABLE "login_failures" (
+  "scope" varchar NOT NULL,
+  "key" varchar NOT NULL,
+  "failures" integer NOT NULL,
+  "last_failure_at" timestamptz NOT NULL,
+  "locked_until" timestamptz NOT NULL,
+  PRIMARY KEY ("scope", "key")
+);
+
+CREATE INDEX ON "login_failures" ("last_failure_at");
+
+COMMENT ON COLUMN "login_failures"."scope" IS 'what the key is: username, ip or share_link';
+
+COMMENT ON COLUMN "login_failures"."failures" IS 'failed logins since the last success or the last quiet period';
+
+COMMENT ON COLUMN "login_failures"."locked_until" IS 'logins of the key are refused until this time';
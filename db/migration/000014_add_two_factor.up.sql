@@ -0,0 +1,43 @@
+CREATE TABLE "account_totp" (
+  "account_id" bigint PRIMARY KEY,
+  "secret" bytea NOT NULL,
+  "sealed" boolean NOT NULL,
+  "enabled" boolean NOT NULL DEFAULT false,
+  "last_step" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "account_totp"."secret" IS 'TOTP secret, sealed with the server key when sealed is set';
+
+COMMENT ON COLUMN "account_totp"."enabled" IS 'set once the first code is confirmed';
+
+COMMENT ON COLUMN "account_totp"."last_step" IS 'time step of the last accepted code, codes cannot be replayed';
+
+CREATE TABLE "recovery_codes" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "code_hash" varchar NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "recovery_codes" ("account_id", "code_hash");
+
+COMMENT ON COLUMN "recovery_codes"."code_hash" IS 'hex encoded sha256 of the code, the code itself is not stored';
+
+CREATE TABLE "login_challenges" (
+  "id" bigserial PRIMARY KEY,
+  "challenge_hash" varchar UNIQUE NOT NULL,
+  "account_id" bigint NOT NULL,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "login_challenges"."challenge_hash" IS 'hex encoded sha256 of the challenge handed out by Login';
+
+ALTER TABLE "account_totp" ADD FOREIGN KEY ("account_id") REFERENCES "account" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "recovery_codes" ADD FOREIGN KEY ("account_id") REFERENCES "account" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "login_challenges" ADD FOREIGN KEY ("account_id") REFERENCES "account" ("id") ON DELETE CASCADE;
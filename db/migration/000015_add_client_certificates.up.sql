@@ -0,0 +1,12 @@
+CREATE TABLE "client_certificates" (
+  "id" bigserial PRIMARY KEY,
+  "identity" varchar UNIQUE NOT NULL,
+  "account_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "client_certificates" ("account_id");
+
+COMMENT ON COLUMN "client_certificates"."identity" IS 'subject:<distinguished name>, dns:<name>, email:<address> or uri:<uri> of the verified client certificate';
+
+ALTER TABLE "client_certificates" ADD FOREIGN KEY ("account_id") REFERENCES "account" ("id") ON DELETE CASCADE;
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS files DROP COLUMN IF EXISTS touched_at;
@@ -0,0 +1,3 @@
+ALTER TABLE "files" ADD COLUMN "touched_at" timestamptz NOT NULL DEFAULT (now());
+
+COMMENT ON COLUMN "files"."touched_at" IS 'last sign of life of the upload while the file is not ready';
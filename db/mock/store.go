@@ -6,7 +6,9 @@ package mockdb
 
 import (
 	context "context"
+	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
 	gomock "github.com/golang/mock/gomock"
@@ -35,6 +37,51 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AttachFileBlobTx mocks base method.
+func (m *MockStore) AttachFileBlobTx(arg0 context.Context, arg1 db.AttachFileBlobTxParams) (db.Blob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachFileBlobTx", arg0, arg1)
+	ret0, _ := ret[0].(db.Blob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AttachFileBlobTx indicates an expected call of AttachFileBlobTx.
+func (mr *MockStoreMockRecorder) AttachFileBlobTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachFileBlobTx", reflect.TypeOf((*MockStore)(nil).AttachFileBlobTx), arg0, arg1)
+}
+
+// AttemptLoginChallenge mocks base method.
+func (m *MockStore) AttemptLoginChallenge(arg0 context.Context, arg1 db.AttemptLoginChallengeParams) (db.LoginChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttemptLoginChallenge", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AttemptLoginChallenge indicates an expected call of AttemptLoginChallenge.
+func (mr *MockStoreMockRecorder) AttemptLoginChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttemptLoginChallenge", reflect.TypeOf((*MockStore)(nil).AttemptLoginChallenge), arg0, arg1)
+}
+
+// BlobChunksExist mocks base method.
+func (m *MockStore) BlobChunksExist(arg0 context.Context, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlobChunksExist", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlobChunksExist indicates an expected call of BlobChunksExist.
+func (mr *MockStoreMockRecorder) BlobChunksExist(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlobChunksExist", reflect.TypeOf((*MockStore)(nil).BlobChunksExist), arg0, arg1)
+}
+
 // BlockAccount mocks base method.
 func (m *MockStore) BlockAccount(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -43,274 +90,1764 @@ func (m *MockStore) BlockAccount(arg0 context.Context, arg1 string) error {
 	return ret0
 }
 
-// BlockAccount indicates an expected call of BlockAccount.
-func (mr *MockStoreMockRecorder) BlockAccount(arg0, arg1 interface{}) *gomock.Call {
+// BlockAccount indicates an expected call of BlockAccount.
+func (mr *MockStoreMockRecorder) BlockAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockAccount", reflect.TypeOf((*MockStore)(nil).BlockAccount), arg0, arg1)
+}
+
+// BumpAccountSessionEpoch mocks base method.
+func (m *MockStore) BumpAccountSessionEpoch(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BumpAccountSessionEpoch", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BumpAccountSessionEpoch indicates an expected call of BumpAccountSessionEpoch.
+func (mr *MockStoreMockRecorder) BumpAccountSessionEpoch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BumpAccountSessionEpoch", reflect.TypeOf((*MockStore)(nil).BumpAccountSessionEpoch), arg0, arg1)
+}
+
+// ChangeAccountPassword mocks base method.
+func (m *MockStore) ChangeAccountPassword(arg0 context.Context, arg1 db.ChangeAccountPasswordParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeAccountPassword", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeAccountPassword indicates an expected call of ChangeAccountPassword.
+func (mr *MockStoreMockRecorder) ChangeAccountPassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeAccountPassword", reflect.TypeOf((*MockStore)(nil).ChangeAccountPassword), arg0, arg1)
+}
+
+// ChangePasswordTx mocks base method.
+func (m *MockStore) ChangePasswordTx(arg0 context.Context, arg1 db.ChangePasswordTxParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePasswordTx", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePasswordTx indicates an expected call of ChangePasswordTx.
+func (mr *MockStoreMockRecorder) ChangePasswordTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePasswordTx", reflect.TypeOf((*MockStore)(nil).ChangePasswordTx), arg0, arg1)
+}
+
+// CountDirectoryEntries mocks base method.
+func (m *MockStore) CountDirectoryEntries(arg0 context.Context, arg1 db.CountDirectoryEntriesParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountDirectoryEntries", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountDirectoryEntries indicates an expected call of CountDirectoryEntries.
+func (mr *MockStoreMockRecorder) CountDirectoryEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDirectoryEntries", reflect.TypeOf((*MockStore)(nil).CountDirectoryEntries), arg0, arg1)
+}
+
+// CountSecrets mocks base method.
+func (m *MockStore) CountSecrets(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSecrets", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountSecrets indicates an expected call of CountSecrets.
+func (mr *MockStoreMockRecorder) CountSecrets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSecrets", reflect.TypeOf((*MockStore)(nil).CountSecrets), arg0, arg1)
+}
+
+// CountShareLinkDownload mocks base method.
+func (m *MockStore) CountShareLinkDownload(arg0 context.Context, arg1 int64) (db.ShareLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountShareLinkDownload", arg0, arg1)
+	ret0, _ := ret[0].(db.ShareLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountShareLinkDownload indicates an expected call of CountShareLinkDownload.
+func (mr *MockStoreMockRecorder) CountShareLinkDownload(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountShareLinkDownload", reflect.TypeOf((*MockStore)(nil).CountShareLinkDownload), arg0, arg1)
+}
+
+// CountUserFileMetadata mocks base method.
+func (m *MockStore) CountUserFileMetadata(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUserFileMetadata", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUserFileMetadata indicates an expected call of CountUserFileMetadata.
+func (mr *MockStoreMockRecorder) CountUserFileMetadata(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserFileMetadata", reflect.TypeOf((*MockStore)(nil).CountUserFileMetadata), arg0, arg1)
+}
+
+// CreateAccount mocks base method.
+func (m *MockStore) CreateAccount(arg0 context.Context, arg1 db.CreateAccountParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockStoreMockRecorder) CreateAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), arg0, arg1)
+}
+
+// CreateAccountTotp mocks base method.
+func (m *MockStore) CreateAccountTotp(arg0 context.Context, arg1 db.CreateAccountTotpParams) (db.AccountTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountTotp", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountTotp indicates an expected call of CreateAccountTotp.
+func (mr *MockStoreMockRecorder) CreateAccountTotp(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountTotp", reflect.TypeOf((*MockStore)(nil).CreateAccountTotp), arg0, arg1)
+}
+
+// CreateBlob mocks base method.
+func (m *MockStore) CreateBlob(arg0 context.Context, arg1 db.CreateBlobParams) (db.Blob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBlob", arg0, arg1)
+	ret0, _ := ret[0].(db.Blob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBlob indicates an expected call of CreateBlob.
+func (mr *MockStoreMockRecorder) CreateBlob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBlob", reflect.TypeOf((*MockStore)(nil).CreateBlob), arg0, arg1)
+}
+
+// CreateBlobChunk mocks base method.
+func (m *MockStore) CreateBlobChunk(arg0 context.Context, arg1 db.CreateBlobChunkParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBlobChunk", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBlobChunk indicates an expected call of CreateBlobChunk.
+func (mr *MockStoreMockRecorder) CreateBlobChunk(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBlobChunk", reflect.TypeOf((*MockStore)(nil).CreateBlobChunk), arg0, arg1)
+}
+
+// CreateClientCertificate mocks base method.
+func (m *MockStore) CreateClientCertificate(arg0 context.Context, arg1 db.CreateClientCertificateParams) (db.ClientCertificate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateClientCertificate", arg0, arg1)
+	ret0, _ := ret[0].(db.ClientCertificate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateClientCertificate indicates an expected call of CreateClientCertificate.
+func (mr *MockStoreMockRecorder) CreateClientCertificate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClientCertificate", reflect.TypeOf((*MockStore)(nil).CreateClientCertificate), arg0, arg1)
+}
+
+// CreateDirectory mocks base method.
+func (m *MockStore) CreateDirectory(arg0 context.Context, arg1 db.CreateDirectoryParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDirectory", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDirectory indicates an expected call of CreateDirectory.
+func (mr *MockStoreMockRecorder) CreateDirectory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDirectory", reflect.TypeOf((*MockStore)(nil).CreateDirectory), arg0, arg1)
+}
+
+// CreateFile mocks base method.
+func (m *MockStore) CreateFile(arg0 context.Context, arg1 db.CreateFileParams) (db.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFile", arg0, arg1)
+	ret0, _ := ret[0].(db.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFile indicates an expected call of CreateFile.
+func (mr *MockStoreMockRecorder) CreateFile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFile", reflect.TypeOf((*MockStore)(nil).CreateFile), arg0, arg1)
+}
+
+// CreateFileMetadata mocks base method.
+func (m *MockStore) CreateFileMetadata(arg0 context.Context, arg1 db.CreateFileMetadataParams) (db.FilesMetadatum, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFileMetadata", arg0, arg1)
+	ret0, _ := ret[0].(db.FilesMetadatum)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFileMetadata indicates an expected call of CreateFileMetadata.
+func (mr *MockStoreMockRecorder) CreateFileMetadata(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFileMetadata", reflect.TypeOf((*MockStore)(nil).CreateFileMetadata), arg0, arg1)
+}
+
+// CreateFileTx mocks base method.
+func (m *MockStore) CreateFileTx(arg0 context.Context, arg1 db.CreateFileTxParams) (db.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFileTx", arg0, arg1)
+	ret0, _ := ret[0].(db.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFileTx indicates an expected call of CreateFileTx.
+func (mr *MockStoreMockRecorder) CreateFileTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFileTx", reflect.TypeOf((*MockStore)(nil).CreateFileTx), arg0, arg1)
+}
+
+// CreateFileVersion mocks base method.
+func (m *MockStore) CreateFileVersion(arg0 context.Context, arg1 db.CreateFileVersionParams) (db.FileVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFileVersion", arg0, arg1)
+	ret0, _ := ret[0].(db.FileVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFileVersion indicates an expected call of CreateFileVersion.
+func (mr *MockStoreMockRecorder) CreateFileVersion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFileVersion", reflect.TypeOf((*MockStore)(nil).CreateFileVersion), arg0, arg1)
+}
+
+// CreateLoginChallenge mocks base method.
+func (m *MockStore) CreateLoginChallenge(arg0 context.Context, arg1 db.CreateLoginChallengeParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoginChallenge", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLoginChallenge indicates an expected call of CreateLoginChallenge.
+func (mr *MockStoreMockRecorder) CreateLoginChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoginChallenge", reflect.TypeOf((*MockStore)(nil).CreateLoginChallenge), arg0, arg1)
+}
+
+// CreateRecoveryCode mocks base method.
+func (m *MockStore) CreateRecoveryCode(arg0 context.Context, arg1 db.CreateRecoveryCodeParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRecoveryCode indicates an expected call of CreateRecoveryCode.
+func (mr *MockStoreMockRecorder) CreateRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecoveryCode", reflect.TypeOf((*MockStore)(nil).CreateRecoveryCode), arg0, arg1)
+}
+
+// CreateRefreshToken mocks base method.
+func (m *MockStore) CreateRefreshToken(arg0 context.Context, arg1 db.CreateRefreshTokenParams) (db.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(db.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockStoreMockRecorder) CreateRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockStore)(nil).CreateRefreshToken), arg0, arg1)
+}
+
+// CreateRevokedToken mocks base method.
+func (m *MockStore) CreateRevokedToken(arg0 context.Context, arg1 db.CreateRevokedTokenParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRevokedToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRevokedToken indicates an expected call of CreateRevokedToken.
+func (mr *MockStoreMockRecorder) CreateRevokedToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRevokedToken", reflect.TypeOf((*MockStore)(nil).CreateRevokedToken), arg0, arg1)
+}
+
+// CreateSecret mocks base method.
+func (m *MockStore) CreateSecret(arg0 context.Context, arg1 db.CreateSecretParams) (db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSecret", arg0, arg1)
+	ret0, _ := ret[0].(db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSecret indicates an expected call of CreateSecret.
+func (mr *MockStoreMockRecorder) CreateSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecret", reflect.TypeOf((*MockStore)(nil).CreateSecret), arg0, arg1)
+}
+
+// CreateSecretMetadata mocks base method.
+func (m *MockStore) CreateSecretMetadata(arg0 context.Context, arg1 db.CreateSecretMetadataParams) (db.SecretsMetadatum, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSecretMetadata", arg0, arg1)
+	ret0, _ := ret[0].(db.SecretsMetadatum)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSecretMetadata indicates an expected call of CreateSecretMetadata.
+func (mr *MockStoreMockRecorder) CreateSecretMetadata(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecretMetadata", reflect.TypeOf((*MockStore)(nil).CreateSecretMetadata), arg0, arg1)
+}
+
+// CreateSecretTx mocks base method.
+func (m *MockStore) CreateSecretTx(arg0 context.Context, arg1 db.CreateSecretTxParams) (db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSecretTx", arg0, arg1)
+	ret0, _ := ret[0].(db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSecretTx indicates an expected call of CreateSecretTx.
+func (mr *MockStoreMockRecorder) CreateSecretTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecretTx", reflect.TypeOf((*MockStore)(nil).CreateSecretTx), arg0, arg1)
+}
+
+// CreateShareLink mocks base method.
+func (m *MockStore) CreateShareLink(arg0 context.Context, arg1 db.CreateShareLinkParams) (db.ShareLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateShareLink", arg0, arg1)
+	ret0, _ := ret[0].(db.ShareLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateShareLink indicates an expected call of CreateShareLink.
+func (mr *MockStoreMockRecorder) CreateShareLink(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShareLink", reflect.TypeOf((*MockStore)(nil).CreateShareLink), arg0, arg1)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockStore) DeleteAccount(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockStoreMockRecorder) DeleteAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStore)(nil).DeleteAccount), arg0, arg1)
+}
+
+// DeleteAccountDirectories mocks base method.
+func (m *MockStore) DeleteAccountDirectories(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccountDirectories", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccountDirectories indicates an expected call of DeleteAccountDirectories.
+func (mr *MockStoreMockRecorder) DeleteAccountDirectories(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccountDirectories", reflect.TypeOf((*MockStore)(nil).DeleteAccountDirectories), arg0, arg1)
+}
+
+// DeleteAccountSecrets mocks base method.
+func (m *MockStore) DeleteAccountSecrets(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccountSecrets", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAccountSecrets indicates an expected call of DeleteAccountSecrets.
+func (mr *MockStoreMockRecorder) DeleteAccountSecrets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccountSecrets", reflect.TypeOf((*MockStore)(nil).DeleteAccountSecrets), arg0, arg1)
+}
+
+// DeleteAccountSecretsMetadata mocks base method.
+func (m *MockStore) DeleteAccountSecretsMetadata(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccountSecretsMetadata", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccountSecretsMetadata indicates an expected call of DeleteAccountSecretsMetadata.
+func (mr *MockStoreMockRecorder) DeleteAccountSecretsMetadata(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccountSecretsMetadata", reflect.TypeOf((*MockStore)(nil).DeleteAccountSecretsMetadata), arg0, arg1)
+}
+
+// DeleteAccountTotp mocks base method.
+func (m *MockStore) DeleteAccountTotp(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccountTotp", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccountTotp indicates an expected call of DeleteAccountTotp.
+func (mr *MockStoreMockRecorder) DeleteAccountTotp(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccountTotp", reflect.TypeOf((*MockStore)(nil).DeleteAccountTotp), arg0, arg1)
+}
+
+// DeleteAccountTx mocks base method.
+func (m *MockStore) DeleteAccountTx(arg0 context.Context, arg1 db.DeleteAccountTxParams) (db.DeleteAccountTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccountTx", arg0, arg1)
+	ret0, _ := ret[0].(db.DeleteAccountTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAccountTx indicates an expected call of DeleteAccountTx.
+func (mr *MockStoreMockRecorder) DeleteAccountTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccountTx", reflect.TypeOf((*MockStore)(nil).DeleteAccountTx), arg0, arg1)
+}
+
+// DeleteBlob mocks base method.
+func (m *MockStore) DeleteBlob(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBlob", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBlob indicates an expected call of DeleteBlob.
+func (mr *MockStoreMockRecorder) DeleteBlob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBlob", reflect.TypeOf((*MockStore)(nil).DeleteBlob), arg0, arg1)
+}
+
+// DeleteBlobChunks mocks base method.
+func (m *MockStore) DeleteBlobChunks(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBlobChunks", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBlobChunks indicates an expected call of DeleteBlobChunks.
+func (mr *MockStoreMockRecorder) DeleteBlobChunks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBlobChunks", reflect.TypeOf((*MockStore)(nil).DeleteBlobChunks), arg0, arg1)
+}
+
+// DeleteClientCertificate mocks base method.
+func (m *MockStore) DeleteClientCertificate(arg0 context.Context, arg1 string) (db.ClientCertificate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteClientCertificate", arg0, arg1)
+	ret0, _ := ret[0].(db.ClientCertificate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteClientCertificate indicates an expected call of DeleteClientCertificate.
+func (mr *MockStoreMockRecorder) DeleteClientCertificate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteClientCertificate", reflect.TypeOf((*MockStore)(nil).DeleteClientCertificate), arg0, arg1)
+}
+
+// DeleteDirectories mocks base method.
+func (m *MockStore) DeleteDirectories(arg0 context.Context, arg1 db.DeleteDirectoriesParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDirectories", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteDirectories indicates an expected call of DeleteDirectories.
+func (mr *MockStoreMockRecorder) DeleteDirectories(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDirectories", reflect.TypeOf((*MockStore)(nil).DeleteDirectories), arg0, arg1)
+}
+
+// DeleteExpiredLoginChallenges mocks base method.
+func (m *MockStore) DeleteExpiredLoginChallenges(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredLoginChallenges", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredLoginChallenges indicates an expected call of DeleteExpiredLoginChallenges.
+func (mr *MockStoreMockRecorder) DeleteExpiredLoginChallenges(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredLoginChallenges", reflect.TypeOf((*MockStore)(nil).DeleteExpiredLoginChallenges), arg0, arg1)
+}
+
+// DeleteExpiredRefreshTokens mocks base method.
+func (m *MockStore) DeleteExpiredRefreshTokens(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredRefreshTokens", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredRefreshTokens indicates an expected call of DeleteExpiredRefreshTokens.
+func (mr *MockStoreMockRecorder) DeleteExpiredRefreshTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredRefreshTokens", reflect.TypeOf((*MockStore)(nil).DeleteExpiredRefreshTokens), arg0, arg1)
+}
+
+// DeleteExpiredRevokedTokens mocks base method.
+func (m *MockStore) DeleteExpiredRevokedTokens(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredRevokedTokens", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredRevokedTokens indicates an expected call of DeleteExpiredRevokedTokens.
+func (mr *MockStoreMockRecorder) DeleteExpiredRevokedTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredRevokedTokens", reflect.TypeOf((*MockStore)(nil).DeleteExpiredRevokedTokens), arg0, arg1)
+}
+
+// DeleteFile mocks base method.
+func (m *MockStore) DeleteFile(arg0 context.Context, arg1 db.DeleteFileParams) (db.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFile", arg0, arg1)
+	ret0, _ := ret[0].(db.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFile indicates an expected call of DeleteFile.
+func (mr *MockStoreMockRecorder) DeleteFile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFile", reflect.TypeOf((*MockStore)(nil).DeleteFile), arg0, arg1)
+}
+
+// DeleteFileMetadata mocks base method.
+func (m *MockStore) DeleteFileMetadata(arg0 context.Context, arg1 db.DeleteFileMetadataParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFileMetadata", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFileMetadata indicates an expected call of DeleteFileMetadata.
+func (mr *MockStoreMockRecorder) DeleteFileMetadata(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFileMetadata", reflect.TypeOf((*MockStore)(nil).DeleteFileMetadata), arg0, arg1)
+}
+
+// DeleteFileTx mocks base method.
+func (m *MockStore) DeleteFileTx(arg0 context.Context, arg1 db.DeleteFileTxParams) (db.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFileTx", arg0, arg1)
+	ret0, _ := ret[0].(db.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFileTx indicates an expected call of DeleteFileTx.
+func (mr *MockStoreMockRecorder) DeleteFileTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFileTx", reflect.TypeOf((*MockStore)(nil).DeleteFileTx), arg0, arg1)
+}
+
+// DeleteFileVersion mocks base method.
+func (m *MockStore) DeleteFileVersion(arg0 context.Context, arg1 int64) (db.FileVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFileVersion", arg0, arg1)
+	ret0, _ := ret[0].(db.FileVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFileVersion indicates an expected call of DeleteFileVersion.
+func (mr *MockStoreMockRecorder) DeleteFileVersion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFileVersion", reflect.TypeOf((*MockStore)(nil).DeleteFileVersion), arg0, arg1)
+}
+
+// DeleteFileVersionTx mocks base method.
+func (m *MockStore) DeleteFileVersionTx(arg0 context.Context, arg1 db.DeleteFileVersionTxParams) (db.FileVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFileVersionTx", arg0, arg1)
+	ret0, _ := ret[0].(db.FileVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFileVersionTx indicates an expected call of DeleteFileVersionTx.
+func (mr *MockStoreMockRecorder) DeleteFileVersionTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFileVersionTx", reflect.TypeOf((*MockStore)(nil).DeleteFileVersionTx), arg0, arg1)
+}
+
+// DeleteFileVersions mocks base method.
+func (m *MockStore) DeleteFileVersions(arg0 context.Context, arg1 int64) ([]db.FileVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFileVersions", arg0, arg1)
+	ret0, _ := ret[0].([]db.FileVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFileVersions indicates an expected call of DeleteFileVersions.
+func (mr *MockStoreMockRecorder) DeleteFileVersions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFileVersions", reflect.TypeOf((*MockStore)(nil).DeleteFileVersions), arg0, arg1)
+}
+
+// DeleteLoginChallenge mocks base method.
+func (m *MockStore) DeleteLoginChallenge(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoginChallenge", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLoginChallenge indicates an expected call of DeleteLoginChallenge.
+func (mr *MockStoreMockRecorder) DeleteLoginChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginChallenge", reflect.TypeOf((*MockStore)(nil).DeleteLoginChallenge), arg0, arg1)
+}
+
+// DeleteLoginFailures mocks base method.
+func (m *MockStore) DeleteLoginFailures(arg0 context.Context, arg1 db.DeleteLoginFailuresParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoginFailures", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLoginFailures indicates an expected call of DeleteLoginFailures.
+func (mr *MockStoreMockRecorder) DeleteLoginFailures(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginFailures", reflect.TypeOf((*MockStore)(nil).DeleteLoginFailures), arg0, arg1)
+}
+
+// DeleteOrphanContentTx mocks base method.
+func (m *MockStore) DeleteOrphanContentTx(arg0 context.Context, arg1 db.DeleteOrphanContentTxParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrphanContentTx", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOrphanContentTx indicates an expected call of DeleteOrphanContentTx.
+func (mr *MockStoreMockRecorder) DeleteOrphanContentTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrphanContentTx", reflect.TypeOf((*MockStore)(nil).DeleteOrphanContentTx), arg0, arg1)
+}
+
+// DeletePendingFile mocks base method.
+func (m *MockStore) DeletePendingFile(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePendingFile", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePendingFile indicates an expected call of DeletePendingFile.
+func (mr *MockStoreMockRecorder) DeletePendingFile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePendingFile", reflect.TypeOf((*MockStore)(nil).DeletePendingFile), arg0, arg1)
+}
+
+// DeleteRecoveryCodes mocks base method.
+func (m *MockStore) DeleteRecoveryCodes(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecoveryCodes", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecoveryCodes indicates an expected call of DeleteRecoveryCodes.
+func (mr *MockStoreMockRecorder) DeleteRecoveryCodes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecoveryCodes", reflect.TypeOf((*MockStore)(nil).DeleteRecoveryCodes), arg0, arg1)
+}
+
+// DeleteSecret mocks base method.
+func (m *MockStore) DeleteSecret(arg0 context.Context, arg1 db.DeleteSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSecret indicates an expected call of DeleteSecret.
+func (mr *MockStoreMockRecorder) DeleteSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecret", reflect.TypeOf((*MockStore)(nil).DeleteSecret), arg0, arg1)
+}
+
+// DeleteSecretMetadata mocks base method.
+func (m *MockStore) DeleteSecretMetadata(arg0 context.Context, arg1 db.DeleteSecretMetadataParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSecretMetadata", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSecretMetadata indicates an expected call of DeleteSecretMetadata.
+func (mr *MockStoreMockRecorder) DeleteSecretMetadata(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecretMetadata", reflect.TypeOf((*MockStore)(nil).DeleteSecretMetadata), arg0, arg1)
+}
+
+// DeleteStaleFile mocks base method.
+func (m *MockStore) DeleteStaleFile(arg0 context.Context, arg1 db.DeleteStaleFileParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStaleFile", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteStaleFile indicates an expected call of DeleteStaleFile.
+func (mr *MockStoreMockRecorder) DeleteStaleFile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStaleFile", reflect.TypeOf((*MockStore)(nil).DeleteStaleFile), arg0, arg1)
+}
+
+// DeleteStaleLoginFailures mocks base method.
+func (m *MockStore) DeleteStaleLoginFailures(arg0 context.Context, arg1 db.DeleteStaleLoginFailuresParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStaleLoginFailures", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteStaleLoginFailures indicates an expected call of DeleteStaleLoginFailures.
+func (mr *MockStoreMockRecorder) DeleteStaleLoginFailures(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStaleLoginFailures", reflect.TypeOf((*MockStore)(nil).DeleteStaleLoginFailures), arg0, arg1)
+}
+
+// DisableTotpTx mocks base method.
+func (m *MockStore) DisableTotpTx(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTotpTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTotpTx indicates an expected call of DisableTotpTx.
+func (mr *MockStoreMockRecorder) DisableTotpTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTotpTx", reflect.TypeOf((*MockStore)(nil).DisableTotpTx), arg0, arg1)
+}
+
+// EnableAccountTotp mocks base method.
+func (m *MockStore) EnableAccountTotp(arg0 context.Context, arg1 db.EnableAccountTotpParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableAccountTotp", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableAccountTotp indicates an expected call of EnableAccountTotp.
+func (mr *MockStoreMockRecorder) EnableAccountTotp(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableAccountTotp", reflect.TypeOf((*MockStore)(nil).EnableAccountTotp), arg0, arg1)
+}
+
+// EnrollTotpTx mocks base method.
+func (m *MockStore) EnrollTotpTx(arg0 context.Context, arg1 db.EnrollTotpTxParams) (db.AccountTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTotpTx", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTotpTx indicates an expected call of EnrollTotpTx.
+func (mr *MockStoreMockRecorder) EnrollTotpTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTotpTx", reflect.TypeOf((*MockStore)(nil).EnrollTotpTx), arg0, arg1)
+}
+
+// GetAccount mocks base method.
+func (m *MockStore) GetAccount(arg0 context.Context, arg1 string) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount.
+func (mr *MockStoreMockRecorder) GetAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), arg0, arg1)
+}
+
+// GetAccountByID mocks base method.
+func (m *MockStore) GetAccountByID(arg0 context.Context, arg1 int64) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountByID", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountByID indicates an expected call of GetAccountByID.
+func (mr *MockStoreMockRecorder) GetAccountByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByID", reflect.TypeOf((*MockStore)(nil).GetAccountByID), arg0, arg1)
+}
+
+// GetAccountForUpdate mocks base method.
+func (m *MockStore) GetAccountForUpdate(arg0 context.Context, arg1 int64) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
+func (mr *MockStoreMockRecorder) GetAccountForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), arg0, arg1)
+}
+
+// GetAccountTotp mocks base method.
+func (m *MockStore) GetAccountTotp(arg0 context.Context, arg1 int64) (db.AccountTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountTotp", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountTotp indicates an expected call of GetAccountTotp.
+func (mr *MockStoreMockRecorder) GetAccountTotp(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountTotp", reflect.TypeOf((*MockStore)(nil).GetAccountTotp), arg0, arg1)
+}
+
+// GetAccountTotpForUpdate mocks base method.
+func (m *MockStore) GetAccountTotpForUpdate(arg0 context.Context, arg1 int64) (db.AccountTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountTotpForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountTotpForUpdate indicates an expected call of GetAccountTotpForUpdate.
+func (mr *MockStoreMockRecorder) GetAccountTotpForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountTotpForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountTotpForUpdate), arg0, arg1)
+}
+
+// GetBlob mocks base method.
+func (m *MockStore) GetBlob(arg0 context.Context, arg1 string) (db.Blob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlob", arg0, arg1)
+	ret0, _ := ret[0].(db.Blob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlob indicates an expected call of GetBlob.
+func (mr *MockStoreMockRecorder) GetBlob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlob", reflect.TypeOf((*MockStore)(nil).GetBlob), arg0, arg1)
+}
+
+// GetBlobChunk mocks base method.
+func (m *MockStore) GetBlobChunk(arg0 context.Context, arg1 db.GetBlobChunkParams) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlobChunk", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlobChunk indicates an expected call of GetBlobChunk.
+func (mr *MockStoreMockRecorder) GetBlobChunk(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlobChunk", reflect.TypeOf((*MockStore)(nil).GetBlobChunk), arg0, arg1)
+}
+
+// GetClientCertificateAccount mocks base method.
+func (m *MockStore) GetClientCertificateAccount(arg0 context.Context, arg1 string) (db.GetClientCertificateAccountRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClientCertificateAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.GetClientCertificateAccountRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClientCertificateAccount indicates an expected call of GetClientCertificateAccount.
+func (mr *MockStoreMockRecorder) GetClientCertificateAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClientCertificateAccount", reflect.TypeOf((*MockStore)(nil).GetClientCertificateAccount), arg0, arg1)
+}
+
+// GetDirectory mocks base method.
+func (m *MockStore) GetDirectory(arg0 context.Context, arg1 db.GetDirectoryParams) (db.Directory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDirectory", arg0, arg1)
+	ret0, _ := ret[0].(db.Directory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDirectory indicates an expected call of GetDirectory.
+func (mr *MockStoreMockRecorder) GetDirectory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDirectory", reflect.TypeOf((*MockStore)(nil).GetDirectory), arg0, arg1)
+}
+
+// GetFile mocks base method.
+func (m *MockStore) GetFile(arg0 context.Context, arg1 db.GetFileParams) (db.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFile", arg0, arg1)
+	ret0, _ := ret[0].(db.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFile indicates an expected call of GetFile.
+func (mr *MockStoreMockRecorder) GetFile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFile", reflect.TypeOf((*MockStore)(nil).GetFile), arg0, arg1)
+}
+
+// GetFileByID mocks base method.
+func (m *MockStore) GetFileByID(arg0 context.Context, arg1 int64) (db.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileByID", arg0, arg1)
+	ret0, _ := ret[0].(db.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileByID indicates an expected call of GetFileByID.
+func (mr *MockStoreMockRecorder) GetFileByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileByID", reflect.TypeOf((*MockStore)(nil).GetFileByID), arg0, arg1)
+}
+
+// GetFileForUpdate mocks base method.
+func (m *MockStore) GetFileForUpdate(arg0 context.Context, arg1 int64) (db.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileForUpdate indicates an expected call of GetFileForUpdate.
+func (mr *MockStoreMockRecorder) GetFileForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileForUpdate", reflect.TypeOf((*MockStore)(nil).GetFileForUpdate), arg0, arg1)
+}
+
+// GetFileVersion mocks base method.
+func (m *MockStore) GetFileVersion(arg0 context.Context, arg1 db.GetFileVersionParams) (db.FileVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileVersion", arg0, arg1)
+	ret0, _ := ret[0].(db.FileVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileVersion indicates an expected call of GetFileVersion.
+func (mr *MockStoreMockRecorder) GetFileVersion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileVersion", reflect.TypeOf((*MockStore)(nil).GetFileVersion), arg0, arg1)
+}
+
+// GetFilesUsage mocks base method.
+func (m *MockStore) GetFilesUsage(arg0 context.Context, arg1 int64) (db.GetFilesUsageRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFilesUsage", arg0, arg1)
+	ret0, _ := ret[0].(db.GetFilesUsageRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFilesUsage indicates an expected call of GetFilesUsage.
+func (mr *MockStoreMockRecorder) GetFilesUsage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilesUsage", reflect.TypeOf((*MockStore)(nil).GetFilesUsage), arg0, arg1)
+}
+
+// GetLoginFailureForUpdate mocks base method.
+func (m *MockStore) GetLoginFailureForUpdate(arg0 context.Context, arg1 db.GetLoginFailureForUpdateParams) (db.LoginFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginFailureForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginFailureForUpdate indicates an expected call of GetLoginFailureForUpdate.
+func (mr *MockStoreMockRecorder) GetLoginFailureForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginFailureForUpdate", reflect.TypeOf((*MockStore)(nil).GetLoginFailureForUpdate), arg0, arg1)
+}
+
+// GetRefreshTokenAccountForUpdate mocks base method.
+func (m *MockStore) GetRefreshTokenAccountForUpdate(arg0 context.Context, arg1 string) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshTokenAccountForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshTokenAccountForUpdate indicates an expected call of GetRefreshTokenAccountForUpdate.
+func (mr *MockStoreMockRecorder) GetRefreshTokenAccountForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetRefreshTokenAccountForUpdate), arg0, arg1)
+}
+
+// GetRefreshTokenForUpdate mocks base method.
+func (m *MockStore) GetRefreshTokenForUpdate(arg0 context.Context, arg1 string) (db.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshTokenForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshTokenForUpdate indicates an expected call of GetRefreshTokenForUpdate.
+func (mr *MockStoreMockRecorder) GetRefreshTokenForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenForUpdate", reflect.TypeOf((*MockStore)(nil).GetRefreshTokenForUpdate), arg0, arg1)
+}
+
+// GetSecret mocks base method.
+func (m *MockStore) GetSecret(arg0 context.Context, arg1 db.GetSecretParams) (db.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecret", arg0, arg1)
+	ret0, _ := ret[0].(db.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecret indicates an expected call of GetSecret.
+func (mr *MockStoreMockRecorder) GetSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecret", reflect.TypeOf((*MockStore)(nil).GetSecret), arg0, arg1)
+}
+
+// GetShareLink mocks base method.
+func (m *MockStore) GetShareLink(arg0 context.Context, arg1 string) (db.GetShareLinkRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShareLink", arg0, arg1)
+	ret0, _ := ret[0].(db.GetShareLinkRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShareLink indicates an expected call of GetShareLink.
+func (mr *MockStoreMockRecorder) GetShareLink(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShareLink", reflect.TypeOf((*MockStore)(nil).GetShareLink), arg0, arg1)
+}
+
+// ListAccounts mocks base method.
+func (m *MockStore) ListAccounts(arg0 context.Context, arg1 db.ListAccountsParams) ([]db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccounts indicates an expected call of ListAccounts.
+func (mr *MockStoreMockRecorder) ListAccounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockStore)(nil).ListAccounts), arg0, arg1)
+}
+
+// ListBlobChunkHashes mocks base method.
+func (m *MockStore) ListBlobChunkHashes(arg0 context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBlobChunkHashes", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBlobChunkHashes indicates an expected call of ListBlobChunkHashes.
+func (mr *MockStoreMockRecorder) ListBlobChunkHashes(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBlobChunkHashes", reflect.TypeOf((*MockStore)(nil).ListBlobChunkHashes), arg0)
+}
+
+// ListBlobFileVersions mocks base method.
+func (m *MockStore) ListBlobFileVersions(arg0 context.Context, arg1 string) ([]db.ListBlobFileVersionsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBlobFileVersions", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListBlobFileVersionsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBlobFileVersions indicates an expected call of ListBlobFileVersions.
+func (mr *MockStoreMockRecorder) ListBlobFileVersions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBlobFileVersions", reflect.TypeOf((*MockStore)(nil).ListBlobFileVersions), arg0, arg1)
+}
+
+// ListBlobFiles mocks base method.
+func (m *MockStore) ListBlobFiles(arg0 context.Context, arg1 sql.NullString) ([]db.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBlobFiles", arg0, arg1)
+	ret0, _ := ret[0].([]db.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBlobFiles indicates an expected call of ListBlobFiles.
+func (mr *MockStoreMockRecorder) ListBlobFiles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBlobFiles", reflect.TypeOf((*MockStore)(nil).ListBlobFiles), arg0, arg1)
+}
+
+// ListBlobs mocks base method.
+func (m *MockStore) ListBlobs(arg0 context.Context, arg1 db.ListBlobsParams) ([]db.Blob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBlobs", arg0, arg1)
+	ret0, _ := ret[0].([]db.Blob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBlobs indicates an expected call of ListBlobs.
+func (mr *MockStoreMockRecorder) ListBlobs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBlobs", reflect.TypeOf((*MockStore)(nil).ListBlobs), arg0, arg1)
+}
+
+// ListClientCertificates mocks base method.
+func (m *MockStore) ListClientCertificates(arg0 context.Context, arg1 int64) ([]db.ClientCertificate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListClientCertificates", arg0, arg1)
+	ret0, _ := ret[0].([]db.ClientCertificate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListClientCertificates indicates an expected call of ListClientCertificates.
+func (mr *MockStoreMockRecorder) ListClientCertificates(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClientCertificates", reflect.TypeOf((*MockStore)(nil).ListClientCertificates), arg0, arg1)
+}
+
+// ListDirectories mocks base method.
+func (m *MockStore) ListDirectories(arg0 context.Context, arg1 int64) ([]db.Directory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDirectories", arg0, arg1)
+	ret0, _ := ret[0].([]db.Directory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDirectories indicates an expected call of ListDirectories.
+func (mr *MockStoreMockRecorder) ListDirectories(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDirectories", reflect.TypeOf((*MockStore)(nil).ListDirectories), arg0, arg1)
+}
+
+// ListDirectoryFiles mocks base method.
+func (m *MockStore) ListDirectoryFiles(arg0 context.Context, arg1 db.ListDirectoryFilesParams) ([]db.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDirectoryFiles", arg0, arg1)
+	ret0, _ := ret[0].([]db.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDirectoryFiles indicates an expected call of ListDirectoryFiles.
+func (mr *MockStoreMockRecorder) ListDirectoryFiles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDirectoryFiles", reflect.TypeOf((*MockStore)(nil).ListDirectoryFiles), arg0, arg1)
+}
+
+// ListExpiredFileVersions mocks base method.
+func (m *MockStore) ListExpiredFileVersions(arg0 context.Context, arg1 db.ListExpiredFileVersionsParams) ([]db.ListExpiredFileVersionsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiredFileVersions", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListExpiredFileVersionsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpiredFileVersions indicates an expected call of ListExpiredFileVersions.
+func (mr *MockStoreMockRecorder) ListExpiredFileVersions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiredFileVersions", reflect.TypeOf((*MockStore)(nil).ListExpiredFileVersions), arg0, arg1)
+}
+
+// ListFileMetadata mocks base method.
+func (m *MockStore) ListFileMetadata(arg0 context.Context, arg1 int64) ([]db.FilesMetadatum, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFileMetadata", arg0, arg1)
+	ret0, _ := ret[0].([]db.FilesMetadatum)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFileMetadata indicates an expected call of ListFileMetadata.
+func (mr *MockStoreMockRecorder) ListFileMetadata(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFileMetadata", reflect.TypeOf((*MockStore)(nil).ListFileMetadata), arg0, arg1)
+}
+
+// ListFileVersions mocks base method.
+func (m *MockStore) ListFileVersions(arg0 context.Context, arg1 int64) ([]db.FileVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFileVersions", arg0, arg1)
+	ret0, _ := ret[0].([]db.FileVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFileVersions indicates an expected call of ListFileVersions.
+func (mr *MockStoreMockRecorder) ListFileVersions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFileVersions", reflect.TypeOf((*MockStore)(nil).ListFileVersions), arg0, arg1)
+}
+
+// ListFiles mocks base method.
+func (m *MockStore) ListFiles(arg0 context.Context, arg1 int64) ([]db.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFiles", arg0, arg1)
+	ret0, _ := ret[0].([]db.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFiles indicates an expected call of ListFiles.
+func (mr *MockStoreMockRecorder) ListFiles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFiles", reflect.TypeOf((*MockStore)(nil).ListFiles), arg0, arg1)
+}
+
+// ListFilesMetadata mocks base method.
+func (m *MockStore) ListFilesMetadata(arg0 context.Context, arg1 []int64) ([]db.FilesMetadatum, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFilesMetadata", arg0, arg1)
+	ret0, _ := ret[0].([]db.FilesMetadatum)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFilesMetadata indicates an expected call of ListFilesMetadata.
+func (mr *MockStoreMockRecorder) ListFilesMetadata(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFilesMetadata", reflect.TypeOf((*MockStore)(nil).ListFilesMetadata), arg0, arg1)
+}
+
+// ListLegacyFiles mocks base method.
+func (m *MockStore) ListLegacyFiles(arg0 context.Context, arg1 db.ListLegacyFilesParams) ([]db.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLegacyFiles", arg0, arg1)
+	ret0, _ := ret[0].([]db.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLegacyFiles indicates an expected call of ListLegacyFiles.
+func (mr *MockStoreMockRecorder) ListLegacyFiles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLegacyFiles", reflect.TypeOf((*MockStore)(nil).ListLegacyFiles), arg0, arg1)
+}
+
+// ListLoginFailures mocks base method.
+func (m *MockStore) ListLoginFailures(arg0 context.Context, arg1 db.ListLoginFailuresParams) ([]db.LoginFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLoginFailures", arg0, arg1)
+	ret0, _ := ret[0].([]db.LoginFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLoginFailures indicates an expected call of ListLoginFailures.
+func (mr *MockStoreMockRecorder) ListLoginFailures(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLoginFailures", reflect.TypeOf((*MockStore)(nil).ListLoginFailures), arg0, arg1)
+}
+
+// ListRevokedTokens mocks base method.
+func (m *MockStore) ListRevokedTokens(arg0 context.Context, arg1 time.Time) ([]db.RevokedToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRevokedTokens", arg0, arg1)
+	ret0, _ := ret[0].([]db.RevokedToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRevokedTokens indicates an expected call of ListRevokedTokens.
+func (mr *MockStoreMockRecorder) ListRevokedTokens(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockAccount", reflect.TypeOf((*MockStore)(nil).BlockAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRevokedTokens", reflect.TypeOf((*MockStore)(nil).ListRevokedTokens), arg0, arg1)
 }
 
-// CreateAccount mocks base method.
-func (m *MockStore) CreateAccount(arg0 context.Context, arg1 db.CreateAccountParams) (db.Account, error) {
+// ListSecretMetadata mocks base method.
+func (m *MockStore) ListSecretMetadata(arg0 context.Context, arg1 int64) ([]db.SecretsMetadatum, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateAccount", arg0, arg1)
-	ret0, _ := ret[0].(db.Account)
+	ret := m.ctrl.Call(m, "ListSecretMetadata", arg0, arg1)
+	ret0, _ := ret[0].([]db.SecretsMetadatum)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateAccount indicates an expected call of CreateAccount.
-func (mr *MockStoreMockRecorder) CreateAccount(arg0, arg1 interface{}) *gomock.Call {
+// ListSecretMetadata indicates an expected call of ListSecretMetadata.
+func (mr *MockStoreMockRecorder) ListSecretMetadata(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecretMetadata", reflect.TypeOf((*MockStore)(nil).ListSecretMetadata), arg0, arg1)
 }
 
-// CreateFile mocks base method.
-func (m *MockStore) CreateFile(arg0 context.Context, arg1 db.CreateFileParams) (db.File, error) {
+// ListSecrets mocks base method.
+func (m *MockStore) ListSecrets(arg0 context.Context, arg1 int64) ([]db.Secret, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateFile", arg0, arg1)
-	ret0, _ := ret[0].(db.File)
+	ret := m.ctrl.Call(m, "ListSecrets", arg0, arg1)
+	ret0, _ := ret[0].([]db.Secret)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateFile indicates an expected call of CreateFile.
-func (mr *MockStoreMockRecorder) CreateFile(arg0, arg1 interface{}) *gomock.Call {
+// ListSecrets indicates an expected call of ListSecrets.
+func (mr *MockStoreMockRecorder) ListSecrets(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFile", reflect.TypeOf((*MockStore)(nil).CreateFile), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecrets", reflect.TypeOf((*MockStore)(nil).ListSecrets), arg0, arg1)
 }
 
-// CreateFileMetadata mocks base method.
-func (m *MockStore) CreateFileMetadata(arg0 context.Context, arg1 db.CreateFileMetadataParams) (db.FilesMetadatum, error) {
+// ListShareLinks mocks base method.
+func (m *MockStore) ListShareLinks(arg0 context.Context, arg1 db.ListShareLinksParams) ([]db.ListShareLinksRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateFileMetadata", arg0, arg1)
-	ret0, _ := ret[0].(db.FilesMetadatum)
+	ret := m.ctrl.Call(m, "ListShareLinks", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListShareLinksRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateFileMetadata indicates an expected call of CreateFileMetadata.
-func (mr *MockStoreMockRecorder) CreateFileMetadata(arg0, arg1 interface{}) *gomock.Call {
+// ListShareLinks indicates an expected call of ListShareLinks.
+func (mr *MockStoreMockRecorder) ListShareLinks(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFileMetadata", reflect.TypeOf((*MockStore)(nil).CreateFileMetadata), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShareLinks", reflect.TypeOf((*MockStore)(nil).ListShareLinks), arg0, arg1)
 }
 
-// CreateSecret mocks base method.
-func (m *MockStore) CreateSecret(arg0 context.Context, arg1 db.CreateSecretParams) (db.Secret, error) {
+// ListStaleFiles mocks base method.
+func (m *MockStore) ListStaleFiles(arg0 context.Context, arg1 time.Time) ([]db.File, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSecret", arg0, arg1)
-	ret0, _ := ret[0].(db.Secret)
+	ret := m.ctrl.Call(m, "ListStaleFiles", arg0, arg1)
+	ret0, _ := ret[0].([]db.File)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateSecret indicates an expected call of CreateSecret.
-func (mr *MockStoreMockRecorder) CreateSecret(arg0, arg1 interface{}) *gomock.Call {
+// ListStaleFiles indicates an expected call of ListStaleFiles.
+func (mr *MockStoreMockRecorder) ListStaleFiles(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecret", reflect.TypeOf((*MockStore)(nil).CreateSecret), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStaleFiles", reflect.TypeOf((*MockStore)(nil).ListStaleFiles), arg0, arg1)
 }
 
-// CreateSecretMetadata mocks base method.
-func (m *MockStore) CreateSecretMetadata(arg0 context.Context, arg1 db.CreateSecretMetadataParams) (db.SecretsMetadatum, error) {
+// LockBlob mocks base method.
+func (m *MockStore) LockBlob(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSecretMetadata", arg0, arg1)
-	ret0, _ := ret[0].(db.SecretsMetadatum)
+	ret := m.ctrl.Call(m, "LockBlob", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockBlob indicates an expected call of LockBlob.
+func (mr *MockStoreMockRecorder) LockBlob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockBlob", reflect.TypeOf((*MockStore)(nil).LockBlob), arg0, arg1)
+}
+
+// LockLogin mocks base method.
+func (m *MockStore) LockLogin(arg0 context.Context, arg1 db.LockLoginParams) (db.LoginFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockLogin", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginFailure)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateSecretMetadata indicates an expected call of CreateSecretMetadata.
-func (mr *MockStoreMockRecorder) CreateSecretMetadata(arg0, arg1 interface{}) *gomock.Call {
+// LockLogin indicates an expected call of LockLogin.
+func (mr *MockStoreMockRecorder) LockLogin(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecretMetadata", reflect.TypeOf((*MockStore)(nil).CreateSecretMetadata), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLogin", reflect.TypeOf((*MockStore)(nil).LockLogin), arg0, arg1)
 }
 
-// DeleteAccount mocks base method.
-func (m *MockStore) DeleteAccount(arg0 context.Context, arg1 string) error {
+// MakeDirectoryTx mocks base method.
+func (m *MockStore) MakeDirectoryTx(arg0 context.Context, arg1 db.MakeDirectoryTxParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
+	ret := m.ctrl.Call(m, "MakeDirectoryTx", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteAccount indicates an expected call of DeleteAccount.
-func (mr *MockStoreMockRecorder) DeleteAccount(arg0, arg1 interface{}) *gomock.Call {
+// MakeDirectoryTx indicates an expected call of MakeDirectoryTx.
+func (mr *MockStoreMockRecorder) MakeDirectoryTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStore)(nil).DeleteAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MakeDirectoryTx", reflect.TypeOf((*MockStore)(nil).MakeDirectoryTx), arg0, arg1)
 }
 
-// DeleteFile mocks base method.
-func (m *MockStore) DeleteFile(arg0 context.Context, arg1 db.DeleteFileParams) error {
+// MarkFileReady mocks base method.
+func (m *MockStore) MarkFileReady(arg0 context.Context, arg1 db.MarkFileReadyParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteFile", arg0, arg1)
+	ret := m.ctrl.Call(m, "MarkFileReady", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteFile indicates an expected call of DeleteFile.
-func (mr *MockStoreMockRecorder) DeleteFile(arg0, arg1 interface{}) *gomock.Call {
+// MarkFileReady indicates an expected call of MarkFileReady.
+func (mr *MockStoreMockRecorder) MarkFileReady(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFile", reflect.TypeOf((*MockStore)(nil).DeleteFile), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFileReady", reflect.TypeOf((*MockStore)(nil).MarkFileReady), arg0, arg1)
 }
 
-// DeleteFileMetadata mocks base method.
-func (m *MockStore) DeleteFileMetadata(arg0 context.Context, arg1 db.DeleteFileMetadataParams) error {
+// MarkRefreshTokenUsed mocks base method.
+func (m *MockStore) MarkRefreshTokenUsed(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteFileMetadata", arg0, arg1)
+	ret := m.ctrl.Call(m, "MarkRefreshTokenUsed", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteFileMetadata indicates an expected call of DeleteFileMetadata.
-func (mr *MockStoreMockRecorder) DeleteFileMetadata(arg0, arg1 interface{}) *gomock.Call {
+// MarkRefreshTokenUsed indicates an expected call of MarkRefreshTokenUsed.
+func (mr *MockStoreMockRecorder) MarkRefreshTokenUsed(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFileMetadata", reflect.TypeOf((*MockStore)(nil).DeleteFileMetadata), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRefreshTokenUsed", reflect.TypeOf((*MockStore)(nil).MarkRefreshTokenUsed), arg0, arg1)
 }
 
-// DeleteSecret mocks base method.
-func (m *MockStore) DeleteSecret(arg0 context.Context, arg1 db.DeleteSecretParams) error {
+// MoveFileTx mocks base method.
+func (m *MockStore) MoveFileTx(arg0 context.Context, arg1 db.MoveFileTxParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteSecret", arg0, arg1)
+	ret := m.ctrl.Call(m, "MoveFileTx", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteSecret indicates an expected call of DeleteSecret.
-func (mr *MockStoreMockRecorder) DeleteSecret(arg0, arg1 interface{}) *gomock.Call {
+// MoveFileTx indicates an expected call of MoveFileTx.
+func (mr *MockStoreMockRecorder) MoveFileTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecret", reflect.TypeOf((*MockStore)(nil).DeleteSecret), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveFileTx", reflect.TypeOf((*MockStore)(nil).MoveFileTx), arg0, arg1)
 }
 
-// DeleteSecretMetadata mocks base method.
-func (m *MockStore) DeleteSecretMetadata(arg0 context.Context, arg1 db.DeleteSecretMetadataParams) error {
+// RecordLoginFailure mocks base method.
+func (m *MockStore) RecordLoginFailure(arg0 context.Context, arg1 db.RecordLoginFailureParams) (db.LoginFailure, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteSecretMetadata", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "RecordLoginFailure", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteSecretMetadata indicates an expected call of DeleteSecretMetadata.
-func (mr *MockStoreMockRecorder) DeleteSecretMetadata(arg0, arg1 interface{}) *gomock.Call {
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockStoreMockRecorder) RecordLoginFailure(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecretMetadata", reflect.TypeOf((*MockStore)(nil).DeleteSecretMetadata), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockStore)(nil).RecordLoginFailure), arg0, arg1)
 }
 
-// GetAccount mocks base method.
-func (m *MockStore) GetAccount(arg0 context.Context, arg1 string) (db.Account, error) {
+// ReferenceBlob mocks base method.
+func (m *MockStore) ReferenceBlob(arg0 context.Context, arg1 string) (db.Blob, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
-	ret0, _ := ret[0].(db.Account)
+	ret := m.ctrl.Call(m, "ReferenceBlob", arg0, arg1)
+	ret0, _ := ret[0].(db.Blob)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccount indicates an expected call of GetAccount.
-func (mr *MockStoreMockRecorder) GetAccount(arg0, arg1 interface{}) *gomock.Call {
+// ReferenceBlob indicates an expected call of ReferenceBlob.
+func (mr *MockStoreMockRecorder) ReferenceBlob(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReferenceBlob", reflect.TypeOf((*MockStore)(nil).ReferenceBlob), arg0, arg1)
 }
 
-// GetFile mocks base method.
-func (m *MockStore) GetFile(arg0 context.Context, arg1 db.GetFileParams) (db.File, error) {
+// ReleaseBlob mocks base method.
+func (m *MockStore) ReleaseBlob(arg0 context.Context, arg1 string) (db.Blob, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFile", arg0, arg1)
-	ret0, _ := ret[0].(db.File)
+	ret := m.ctrl.Call(m, "ReleaseBlob", arg0, arg1)
+	ret0, _ := ret[0].(db.Blob)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetFile indicates an expected call of GetFile.
-func (mr *MockStoreMockRecorder) GetFile(arg0, arg1 interface{}) *gomock.Call {
+// ReleaseBlob indicates an expected call of ReleaseBlob.
+func (mr *MockStoreMockRecorder) ReleaseBlob(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFile", reflect.TypeOf((*MockStore)(nil).GetFile), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseBlob", reflect.TypeOf((*MockStore)(nil).ReleaseBlob), arg0, arg1)
 }
 
-// GetSecret mocks base method.
-func (m *MockStore) GetSecret(arg0 context.Context, arg1 db.GetSecretParams) (db.Secret, error) {
+// RemoveDirectoryTx mocks base method.
+func (m *MockStore) RemoveDirectoryTx(arg0 context.Context, arg1 db.RemoveDirectoryTxParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSecret", arg0, arg1)
-	ret0, _ := ret[0].(db.Secret)
+	ret := m.ctrl.Call(m, "RemoveDirectoryTx", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSecret indicates an expected call of GetSecret.
-func (mr *MockStoreMockRecorder) GetSecret(arg0, arg1 interface{}) *gomock.Call {
+// RemoveDirectoryTx indicates an expected call of RemoveDirectoryTx.
+func (mr *MockStoreMockRecorder) RemoveDirectoryTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecret", reflect.TypeOf((*MockStore)(nil).GetSecret), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveDirectoryTx", reflect.TypeOf((*MockStore)(nil).RemoveDirectoryTx), arg0, arg1)
 }
 
-// ListFileMetadata mocks base method.
-func (m *MockStore) ListFileMetadata(arg0 context.Context, arg1 int64) ([]db.FilesMetadatum, error) {
+// ReplaceRecoveryCodesTx mocks base method.
+func (m *MockStore) ReplaceRecoveryCodesTx(arg0 context.Context, arg1 db.ReplaceRecoveryCodesTxParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListFileMetadata", arg0, arg1)
-	ret0, _ := ret[0].([]db.FilesMetadatum)
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodesTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodesTx indicates an expected call of ReplaceRecoveryCodesTx.
+func (mr *MockStoreMockRecorder) ReplaceRecoveryCodesTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodesTx", reflect.TypeOf((*MockStore)(nil).ReplaceRecoveryCodesTx), arg0, arg1)
+}
+
+// ReserveLoginAttemptTx mocks base method.
+func (m *MockStore) ReserveLoginAttemptTx(arg0 context.Context, arg1 db.ReserveLoginAttemptTxParams) (db.ReserveLoginAttemptTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveLoginAttemptTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ReserveLoginAttemptTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListFileMetadata indicates an expected call of ListFileMetadata.
-func (mr *MockStoreMockRecorder) ListFileMetadata(arg0, arg1 interface{}) *gomock.Call {
+// ReserveLoginAttemptTx indicates an expected call of ReserveLoginAttemptTx.
+func (mr *MockStoreMockRecorder) ReserveLoginAttemptTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFileMetadata", reflect.TypeOf((*MockStore)(nil).ListFileMetadata), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveLoginAttemptTx", reflect.TypeOf((*MockStore)(nil).ReserveLoginAttemptTx), arg0, arg1)
 }
 
-// ListFiles mocks base method.
-func (m *MockStore) ListFiles(arg0 context.Context, arg1 int64) ([]db.File, error) {
+// RevokeAccountRefreshTokens mocks base method.
+func (m *MockStore) RevokeAccountRefreshTokens(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListFiles", arg0, arg1)
-	ret0, _ := ret[0].([]db.File)
+	ret := m.ctrl.Call(m, "RevokeAccountRefreshTokens", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccountRefreshTokens indicates an expected call of RevokeAccountRefreshTokens.
+func (mr *MockStoreMockRecorder) RevokeAccountRefreshTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccountRefreshTokens", reflect.TypeOf((*MockStore)(nil).RevokeAccountRefreshTokens), arg0, arg1)
+}
+
+// RevokeAllSessionsTx mocks base method.
+func (m *MockStore) RevokeAllSessionsTx(arg0 context.Context, arg1 db.RevokeAllSessionsTxParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllSessionsTx", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListFiles indicates an expected call of ListFiles.
-func (mr *MockStoreMockRecorder) ListFiles(arg0, arg1 interface{}) *gomock.Call {
+// RevokeAllSessionsTx indicates an expected call of RevokeAllSessionsTx.
+func (mr *MockStoreMockRecorder) RevokeAllSessionsTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFiles", reflect.TypeOf((*MockStore)(nil).ListFiles), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllSessionsTx", reflect.TypeOf((*MockStore)(nil).RevokeAllSessionsTx), arg0, arg1)
 }
 
-// ListSecretMetadata mocks base method.
-func (m *MockStore) ListSecretMetadata(arg0 context.Context, arg1 int64) ([]db.SecretsMetadatum, error) {
+// RevokeRefreshTokenFamily mocks base method.
+func (m *MockStore) RevokeRefreshTokenFamily(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListSecretMetadata", arg0, arg1)
-	ret0, _ := ret[0].([]db.SecretsMetadatum)
+	ret := m.ctrl.Call(m, "RevokeRefreshTokenFamily", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshTokenFamily indicates an expected call of RevokeRefreshTokenFamily.
+func (mr *MockStoreMockRecorder) RevokeRefreshTokenFamily(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokenFamily", reflect.TypeOf((*MockStore)(nil).RevokeRefreshTokenFamily), arg0, arg1)
+}
+
+// RevokeRefreshTokenFamilyOfToken mocks base method.
+func (m *MockStore) RevokeRefreshTokenFamilyOfToken(arg0 context.Context, arg1 db.RevokeRefreshTokenFamilyOfTokenParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshTokenFamilyOfToken", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListSecretMetadata indicates an expected call of ListSecretMetadata.
-func (mr *MockStoreMockRecorder) ListSecretMetadata(arg0, arg1 interface{}) *gomock.Call {
+// RevokeRefreshTokenFamilyOfToken indicates an expected call of RevokeRefreshTokenFamilyOfToken.
+func (mr *MockStoreMockRecorder) RevokeRefreshTokenFamilyOfToken(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecretMetadata", reflect.TypeOf((*MockStore)(nil).ListSecretMetadata), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokenFamilyOfToken", reflect.TypeOf((*MockStore)(nil).RevokeRefreshTokenFamilyOfToken), arg0, arg1)
 }
 
-// ListSecrets mocks base method.
-func (m *MockStore) ListSecrets(arg0 context.Context, arg1 int64) ([]db.Secret, error) {
+// RevokeShareLink mocks base method.
+func (m *MockStore) RevokeShareLink(arg0 context.Context, arg1 db.RevokeShareLinkParams) (db.ShareLink, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListSecrets", arg0, arg1)
-	ret0, _ := ret[0].([]db.Secret)
+	ret := m.ctrl.Call(m, "RevokeShareLink", arg0, arg1)
+	ret0, _ := ret[0].(db.ShareLink)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListSecrets indicates an expected call of ListSecrets.
-func (mr *MockStoreMockRecorder) ListSecrets(arg0, arg1 interface{}) *gomock.Call {
+// RevokeShareLink indicates an expected call of RevokeShareLink.
+func (mr *MockStoreMockRecorder) RevokeShareLink(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecrets", reflect.TypeOf((*MockStore)(nil).ListSecrets), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeShareLink", reflect.TypeOf((*MockStore)(nil).RevokeShareLink), arg0, arg1)
 }
 
-// MarkFileReady mocks base method.
-func (m *MockStore) MarkFileReady(arg0 context.Context, arg1 db.MarkFileReadyParams) error {
+// RotateRefreshTokenTx mocks base method.
+func (m *MockStore) RotateRefreshTokenTx(arg0 context.Context, arg1 db.RotateRefreshTokenTxParams) (db.RotateRefreshTokenTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "MarkFileReady", arg0, arg1)
+	ret := m.ctrl.Call(m, "RotateRefreshTokenTx", arg0, arg1)
+	ret0, _ := ret[0].(db.RotateRefreshTokenTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateRefreshTokenTx indicates an expected call of RotateRefreshTokenTx.
+func (mr *MockStoreMockRecorder) RotateRefreshTokenTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshTokenTx", reflect.TypeOf((*MockStore)(nil).RotateRefreshTokenTx), arg0, arg1)
+}
+
+// SetAccountQuota mocks base method.
+func (m *MockStore) SetAccountQuota(arg0 context.Context, arg1 db.SetAccountQuotaParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAccountQuota", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// MarkFileReady indicates an expected call of MarkFileReady.
-func (mr *MockStoreMockRecorder) MarkFileReady(arg0, arg1 interface{}) *gomock.Call {
+// SetAccountQuota indicates an expected call of SetAccountQuota.
+func (mr *MockStoreMockRecorder) SetAccountQuota(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFileReady", reflect.TypeOf((*MockStore)(nil).MarkFileReady), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountQuota", reflect.TypeOf((*MockStore)(nil).SetAccountQuota), arg0, arg1)
+}
+
+// SetFileBlob mocks base method.
+func (m *MockStore) SetFileBlob(arg0 context.Context, arg1 db.SetFileBlobParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFileBlob", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetFileBlob indicates an expected call of SetFileBlob.
+func (mr *MockStoreMockRecorder) SetFileBlob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFileBlob", reflect.TypeOf((*MockStore)(nil).SetFileBlob), arg0, arg1)
+}
+
+// SetFileMetadata mocks base method.
+func (m *MockStore) SetFileMetadata(arg0 context.Context, arg1 db.SetFileMetadataParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFileMetadata", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetFileMetadata indicates an expected call of SetFileMetadata.
+func (mr *MockStoreMockRecorder) SetFileMetadata(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFileMetadata", reflect.TypeOf((*MockStore)(nil).SetFileMetadata), arg0, arg1)
+}
+
+// TouchFile mocks base method.
+func (m *MockStore) TouchFile(arg0 context.Context, arg1 db.TouchFileParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchFile", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchFile indicates an expected call of TouchFile.
+func (mr *MockStoreMockRecorder) TouchFile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchFile", reflect.TypeOf((*MockStore)(nil).TouchFile), arg0, arg1)
+}
+
+// UnblockAccount mocks base method.
+func (m *MockStore) UnblockAccount(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnblockAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnblockAccount indicates an expected call of UnblockAccount.
+func (mr *MockStoreMockRecorder) UnblockAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnblockAccount", reflect.TypeOf((*MockStore)(nil).UnblockAccount), arg0, arg1)
+}
+
+// UndoLoginFailure mocks base method.
+func (m *MockStore) UndoLoginFailure(arg0 context.Context, arg1 db.UndoLoginFailureParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UndoLoginFailure", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UndoLoginFailure indicates an expected call of UndoLoginFailure.
+func (mr *MockStoreMockRecorder) UndoLoginFailure(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UndoLoginFailure", reflect.TypeOf((*MockStore)(nil).UndoLoginFailure), arg0, arg1)
 }
 
 // UpdateFileMetadata mocks base method.
@@ -327,12 +1864,28 @@ func (mr *MockStoreMockRecorder) UpdateFileMetadata(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFileMetadata", reflect.TypeOf((*MockStore)(nil).UpdateFileMetadata), arg0, arg1)
 }
 
+// UpdateFileMetadataTx mocks base method.
+func (m *MockStore) UpdateFileMetadataTx(arg0 context.Context, arg1 db.UpdateFileMetadataTxParams) ([]db.FilesMetadatum, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFileMetadataTx", arg0, arg1)
+	ret0, _ := ret[0].([]db.FilesMetadatum)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFileMetadataTx indicates an expected call of UpdateFileMetadataTx.
+func (mr *MockStoreMockRecorder) UpdateFileMetadataTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFileMetadataTx", reflect.TypeOf((*MockStore)(nil).UpdateFileMetadataTx), arg0, arg1)
+}
+
 // UpdateFilePath mocks base method.
-func (m *MockStore) UpdateFilePath(arg0 context.Context, arg1 db.UpdateFilePathParams) error {
+func (m *MockStore) UpdateFilePath(arg0 context.Context, arg1 db.UpdateFilePathParams) (int64, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateFilePath", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // UpdateFilePath indicates an expected call of UpdateFilePath.
@@ -368,3 +1921,33 @@ func (mr *MockStoreMockRecorder) UpdateSecretMetadata(arg0, arg1 interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSecretMetadata", reflect.TypeOf((*MockStore)(nil).UpdateSecretMetadata), arg0, arg1)
 }
+
+// UseAccountTotpStep mocks base method.
+func (m *MockStore) UseAccountTotpStep(arg0 context.Context, arg1 db.UseAccountTotpStepParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseAccountTotpStep", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseAccountTotpStep indicates an expected call of UseAccountTotpStep.
+func (mr *MockStoreMockRecorder) UseAccountTotpStep(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseAccountTotpStep", reflect.TypeOf((*MockStore)(nil).UseAccountTotpStep), arg0, arg1)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockStore) UseRecoveryCode(arg0 context.Context, arg1 db.UseRecoveryCodeParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockStoreMockRecorder) UseRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockStore)(nil).UseRecoveryCode), arg0, arg1)
+}
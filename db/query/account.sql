@@ -12,10 +12,55 @@ UPDATE account
   set blocked = true
 WHERE username = $1;
 
+-- name: UnblockAccount :exec
+UPDATE account
+  set blocked = false
+WHERE username = $1;
+
+-- name: ListAccounts :many
+SELECT * FROM account
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
 -- name: GetAccount :one
 SELECT * FROM account
 WHERE username = $1 LIMIT 1;
 
+-- name: GetAccountByID :one
+SELECT * FROM account
+WHERE id = $1 LIMIT 1;
+
 -- name: DeleteAccount :exec
 DELETE FROM account
-WHERE username = $1;
\ No newline at end of file
+WHERE username = $1;
+
+-- name: GetAccountForUpdate :one
+SELECT * FROM account
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: SetAccountQuota :exec
+UPDATE account
+  set quota_bytes = $2, quota_files = $3, quota_secrets = $4
+WHERE username = $1;
+
+-- name: BumpAccountSessionEpoch :one
+UPDATE account
+  set session_epoch = session_epoch + 1
+WHERE id = $1
+RETURNING session_epoch;
+
+-- name: GetRefreshTokenAccountForUpdate :one
+SELECT * FROM account
+WHERE id = (
+  SELECT account_id FROM refresh_tokens
+  WHERE token_hash = $1
+) LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ChangeAccountPassword :one
+UPDATE account
+  set passhash = $2, session_epoch = session_epoch + 1
+WHERE id = $1
+RETURNING *;
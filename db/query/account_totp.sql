@@ -0,0 +1,35 @@
+-- name: CreateAccountTotp :one
+INSERT INTO account_totp (
+  account_id,
+  secret,
+  sealed
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (account_id) DO UPDATE
+  set secret = EXCLUDED.secret, sealed = EXCLUDED.sealed, last_step = 0, created_at = now()
+  WHERE account_totp.enabled = false
+RETURNING *;
+
+-- name: GetAccountTotp :one
+SELECT * FROM account_totp
+WHERE account_id = $1 LIMIT 1;
+
+-- name: GetAccountTotpForUpdate :one
+SELECT * FROM account_totp
+WHERE account_id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: EnableAccountTotp :execrows
+UPDATE account_totp
+  set enabled = true, last_step = $2
+WHERE account_id = $1 AND enabled = false;
+
+-- name: UseAccountTotpStep :execrows
+UPDATE account_totp
+  set last_step = $2
+WHERE account_id = $1 AND enabled = true AND last_step < $2;
+
+-- name: DeleteAccountTotp :exec
+DELETE FROM account_totp
+WHERE account_id = $1;
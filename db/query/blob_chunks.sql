@@ -0,0 +1,27 @@
+-- name: CreateBlobChunk :exec
+INSERT INTO blob_chunks (
+  hash,
+  seq,
+  data
+) VALUES (
+  $1, $2, $3
+);
+
+-- name: GetBlobChunk :one
+SELECT data FROM blob_chunks
+WHERE hash = $1 and seq = $2 LIMIT 1;
+
+-- name: BlobChunksExist :one
+SELECT EXISTS (
+  SELECT 1 FROM blob_chunks
+  WHERE hash = $1 and seq = 0
+);
+
+-- name: DeleteBlobChunks :exec
+DELETE FROM blob_chunks
+WHERE hash = $1;
+
+-- name: ListBlobChunkHashes :many
+SELECT hash FROM blob_chunks
+WHERE seq = 0
+ORDER BY hash;
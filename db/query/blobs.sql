@@ -0,0 +1,40 @@
+-- name: LockBlob :exec
+SELECT pg_advisory_xact_lock(hashtext($1));
+
+-- name: CreateBlob :one
+INSERT INTO blobs (
+  hash,
+  size,
+  codec,
+  wrapped_key,
+  refcount
+) VALUES (
+  $1, $2, $3, $4, 1
+)
+RETURNING *;
+
+-- name: ReferenceBlob :one
+UPDATE blobs
+  set refcount = refcount + 1
+WHERE hash = $1
+RETURNING *;
+
+-- name: ReleaseBlob :one
+UPDATE blobs
+  set refcount = refcount - 1
+WHERE hash = $1
+RETURNING *;
+
+-- name: GetBlob :one
+SELECT * FROM blobs
+WHERE hash = $1 LIMIT 1;
+
+-- name: DeleteBlob :exec
+DELETE FROM blobs
+WHERE hash = $1 and refcount <= 0;
+
+-- name: ListBlobs :many
+SELECT * FROM blobs
+WHERE hash > $1
+ORDER BY hash
+LIMIT $2;
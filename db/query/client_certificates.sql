@@ -0,0 +1,23 @@
+-- name: CreateClientCertificate :one
+INSERT INTO client_certificates (
+  identity,
+  account_id
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: GetClientCertificateAccount :one
+SELECT client_certificates.account_id, account.username FROM client_certificates
+JOIN account ON account.id = client_certificates.account_id
+WHERE client_certificates.identity = $1 LIMIT 1;
+
+-- name: ListClientCertificates :many
+SELECT * FROM client_certificates
+WHERE account_id = $1
+ORDER BY identity;
+
+-- name: DeleteClientCertificate :one
+DELETE FROM client_certificates
+WHERE identity = $1
+RETURNING *;
@@ -0,0 +1,33 @@
+-- name: CreateDirectory :exec
+INSERT INTO directories (
+  account_id,
+  path
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (account_id, path) DO NOTHING;
+
+-- name: GetDirectory :one
+SELECT * FROM directories
+WHERE account_id = $1 and path = $2 LIMIT 1;
+
+-- name: ListDirectories :many
+SELECT * FROM directories
+WHERE account_id = $1
+ORDER BY path;
+
+-- name: CountDirectoryEntries :one
+SELECT
+  (SELECT COUNT(*) FROM files f
+   WHERE f.account_id = $1 and (f.filepath = $2 or starts_with(f.filepath, $2 || '/'))) +
+  (SELECT COUNT(*) FROM directories d
+   WHERE d.account_id = $1 and starts_with(d.path, $2 || '/'))
+  AS entries;
+
+-- name: DeleteDirectories :execrows
+DELETE FROM directories
+WHERE account_id = $1 and (path = $2 or starts_with(path, $2 || '/'));
+
+-- name: DeleteAccountDirectories :exec
+DELETE FROM directories
+WHERE account_id = $1;
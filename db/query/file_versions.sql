@@ -0,0 +1,46 @@
+-- name: CreateFileVersion :one
+INSERT INTO file_versions (
+  file_id,
+  version,
+  blob_hash
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetFileVersion :one
+SELECT * FROM file_versions
+WHERE file_id = $1 and version = $2 LIMIT 1;
+
+-- name: ListFileVersions :many
+SELECT * FROM file_versions
+WHERE file_id = $1
+ORDER BY version DESC;
+
+-- name: DeleteFileVersion :one
+DELETE FROM file_versions
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteFileVersions :many
+DELETE FROM file_versions
+WHERE file_id = $1
+RETURNING *;
+
+-- name: ListExpiredFileVersions :many
+SELECT v.id, v.file_id, v.version, v.blob_hash, v.created_at, f.account_id
+FROM (
+  SELECT *, row_number() OVER (PARTITION BY file_id ORDER BY version DESC) AS rank
+  FROM file_versions
+) v
+JOIN files f ON f.id = v.file_id
+WHERE v.rank > sqlc.arg(keep)::bigint and v.created_at < sqlc.arg(before)
+ORDER BY v.id
+LIMIT sqlc.arg(max_rows);
+
+-- name: ListBlobFileVersions :many
+SELECT v.id, v.file_id, v.version, v.blob_hash, v.created_at, f.account_id, f.filename, f.filepath
+FROM file_versions v
+JOIN files f ON f.id = v.file_id
+WHERE v.blob_hash = $1
+ORDER BY v.id;
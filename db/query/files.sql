@@ -9,25 +9,87 @@ INSERT INTO files (
 )
 RETURNING *;
 
--- name: UpdateFilePath :exec
+-- name: UpdateFilePath :execrows
 UPDATE files
-  set filepath = $3
-WHERE filename = $1 and account_id = $2;
+  set filepath = sqlc.arg(new_filepath), filename = sqlc.arg(new_filename)
+WHERE filename = sqlc.arg(filename) and account_id = sqlc.arg(account_id) and filepath = sqlc.arg(filepath);
 
 -- name: MarkFileReady :exec
 UPDATE files
   set ready = true
 WHERE filename = $1 and account_id = $2;
 
+-- name: SetFileBlob :execrows
+UPDATE files
+  set blob_hash = $2, ready = true, version = version + 1
+WHERE id = $1;
+
 -- name: GetFile :one
 SELECT * FROM files
-WHERE filename = $1 and account_id = $2 LIMIT 1;
+WHERE filename = $1 and account_id = $2 and filepath = $3 LIMIT 1;
 
 -- name: ListFiles :many
 SELECT * FROM files
 WHERE account_id = $1 
 ORDER BY filename;
 
--- name: DeleteFile :exec
+-- name: DeleteFile :one
 DELETE FROM files
-WHERE filename = $1 and account_id = $2;
\ No newline at end of file
+WHERE filename = $1 and account_id = $2 and filepath = $3
+RETURNING *;
+
+-- name: GetFileForUpdate :one
+SELECT * FROM files
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetFilesUsage :one
+SELECT
+  (SELECT COUNT(*) FROM files WHERE files.account_id = $1) AS file_count,
+  (
+    (SELECT COALESCE(SUM(b.size), 0) FROM files f
+     JOIN blobs b ON b.hash = f.blob_hash
+     WHERE f.account_id = $1) +
+    (SELECT COALESCE(SUM(b.size), 0) FROM file_versions v
+     JOIN files f ON f.id = v.file_id
+     JOIN blobs b ON b.hash = v.blob_hash
+     WHERE f.account_id = $1)
+  )::bigint AS total_bytes;
+
+-- name: ListDirectoryFiles :many
+SELECT * FROM files
+WHERE account_id = $1 and (filepath = $2 or starts_with(filepath, $2 || '/'))
+ORDER BY id;
+
+-- name: ListStaleFiles :many
+SELECT * FROM files
+WHERE ready = false and touched_at < $1
+ORDER BY id;
+
+-- name: ListLegacyFiles :many
+SELECT * FROM files
+WHERE ready = true and blob_hash IS NULL and id > $1
+ORDER BY id
+LIMIT $2;
+
+-- name: DeleteStaleFile :execrows
+DELETE FROM files
+WHERE id = $1 and ready = false and touched_at < $2;
+
+-- name: DeletePendingFile :exec
+DELETE FROM files
+WHERE id = $1 and ready = false;
+
+-- name: TouchFile :exec
+UPDATE files
+  set touched_at = $2
+WHERE id = $1 and ready = false;
+
+-- name: ListBlobFiles :many
+SELECT * FROM files
+WHERE blob_hash = $1
+ORDER BY id;
+
+-- name: GetFileByID :one
+SELECT * FROM files
+WHERE id = $1 LIMIT 1;
@@ -8,6 +8,18 @@ INSERT INTO files_metadata (
 )
 RETURNING *;
 
+-- name: SetFileMetadata :exec
+INSERT INTO files_metadata (
+  file_id,
+  system,
+  key,
+  value
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (file_id, system, key) DO UPDATE
+  set value = excluded.value;
+
 -- name: UpdateFileMetadata :exec
 UPDATE files_metadata
   set value = $3
@@ -18,6 +30,15 @@ SELECT * FROM files_metadata
 WHERE file_id = $1 
 ORDER BY key;
 
--- name: DeleteFileMetadata :exec
+-- name: ListFilesMetadata :many
+SELECT * FROM files_metadata
+WHERE file_id = ANY(sqlc.arg(file_ids)::bigint[])
+ORDER BY file_id, key;
+
+-- name: CountUserFileMetadata :one
+SELECT COUNT(*) FROM files_metadata
+WHERE file_id = $1 and system = false;
+
+-- name: DeleteFileMetadata :execrows
 DELETE FROM files_metadata
-WHERE key = $1 and file_id = $2;
\ No newline at end of file
+WHERE key = $1 and file_id = $2 and system = false;
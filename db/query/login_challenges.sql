@@ -0,0 +1,24 @@
+-- name: CreateLoginChallenge :exec
+INSERT INTO login_challenges (
+  challenge_hash,
+  account_id,
+  expires_at
+) VALUES (
+  $1, $2, $3
+);
+
+-- name: AttemptLoginChallenge :one
+UPDATE login_challenges
+  set attempts = attempts + 1
+WHERE challenge_hash = sqlc.arg(challenge_hash)
+  AND expires_at > sqlc.arg(now)
+  AND attempts < sqlc.arg(max_attempts)
+RETURNING *;
+
+-- name: DeleteLoginChallenge :exec
+DELETE FROM login_challenges
+WHERE id = $1;
+
+-- name: DeleteExpiredLoginChallenges :execrows
+DELETE FROM login_challenges
+WHERE expires_at < $1;
@@ -0,0 +1,59 @@
+-- name: RecordLoginFailure :one
+INSERT INTO login_failures (
+  scope,
+  key,
+  failures,
+  last_failure_at,
+  locked_until
+) VALUES (
+  sqlc.arg(scope), sqlc.arg(key), 1, sqlc.arg(failed_at), sqlc.arg(failed_at)
+)
+ON CONFLICT (scope, key) DO UPDATE
+  set failures = CASE
+      WHEN login_failures.last_failure_at < sqlc.arg(window_start) THEN 1
+      ELSE login_failures.failures + 1
+    END,
+    last_failure_at = EXCLUDED.last_failure_at
+RETURNING *;
+
+-- name: GetLoginFailureForUpdate :one
+INSERT INTO login_failures (
+  scope,
+  key,
+  failures,
+  last_failure_at,
+  locked_until
+) VALUES (
+  sqlc.arg(scope), sqlc.arg(key), 0, sqlc.arg(now), sqlc.arg(now)
+)
+ON CONFLICT (scope, key) DO UPDATE
+  set scope = EXCLUDED.scope
+RETURNING *;
+
+-- name: LockLogin :one
+UPDATE login_failures
+  set locked_until = GREATEST(locked_until, sqlc.arg(locked_until))
+WHERE scope = sqlc.arg(scope) AND key = sqlc.arg(key)
+RETURNING *;
+
+-- name: UndoLoginFailure :exec
+UPDATE login_failures
+  set failures = GREATEST(failures - 1, 0),
+    locked_until = CASE
+      WHEN locked_until = sqlc.arg(reserved_until) THEN sqlc.arg(locked_until)
+      ELSE locked_until
+    END
+WHERE scope = sqlc.arg(scope) AND key = sqlc.arg(key);
+
+-- name: ListLoginFailures :many
+SELECT * FROM login_failures
+WHERE (scope = sqlc.arg(scope) AND key = sqlc.arg(key))
+   OR (scope = 'ip' AND key = sqlc.arg(ip));
+
+-- name: DeleteLoginFailures :exec
+DELETE FROM login_failures
+WHERE scope = $1 AND key = $2;
+
+-- name: DeleteStaleLoginFailures :execrows
+DELETE FROM login_failures
+WHERE last_failure_at < sqlc.arg(window_start) AND locked_until < sqlc.arg(now);
@@ -0,0 +1,42 @@
+-- name: CreateRefreshToken :one
+INSERT INTO refresh_tokens (
+  account_id,
+  family_id,
+  token_hash,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetRefreshTokenForUpdate :one
+SELECT * FROM refresh_tokens
+WHERE token_hash = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: MarkRefreshTokenUsed :exec
+UPDATE refresh_tokens
+  set used = true
+WHERE id = $1;
+
+-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+  set revoked = true
+WHERE family_id = $1;
+
+-- name: DeleteExpiredRefreshTokens :execrows
+DELETE FROM refresh_tokens
+WHERE expires_at < $1;
+
+-- name: RevokeRefreshTokenFamilyOfToken :execrows
+UPDATE refresh_tokens
+  set revoked = true
+WHERE family_id = (
+  SELECT family_id FROM refresh_tokens AS t
+  WHERE t.token_hash = $1 AND t.account_id = $2
+);
+
+-- name: RevokeAccountRefreshTokens :exec
+UPDATE refresh_tokens
+  set revoked = true
+WHERE account_id = $1 AND revoked = false;
@@ -0,0 +1,18 @@
+-- name: CreateRevokedToken :exec
+INSERT INTO revoked_tokens (
+  jti,
+  account_id,
+  expires_at
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: ListRevokedTokens :many
+SELECT * FROM revoked_tokens
+WHERE expires_at > $1
+ORDER BY jti;
+
+-- name: DeleteExpiredRevokedTokens :execrows
+DELETE FROM revoked_tokens
+WHERE expires_at < $1;
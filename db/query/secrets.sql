@@ -24,4 +24,12 @@ ORDER BY key;
 
 -- name: DeleteSecret :exec
 DELETE FROM secrets
-WHERE key = $1 and account_id = $2;
\ No newline at end of file
+WHERE key = $1 and account_id = $2;
+
+-- name: CountSecrets :one
+SELECT COUNT(*) FROM secrets
+WHERE account_id = $1;
+
+-- name: DeleteAccountSecrets :execrows
+DELETE FROM secrets
+WHERE account_id = $1;
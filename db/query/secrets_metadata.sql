@@ -20,4 +20,8 @@ ORDER BY key;
 
 -- name: DeleteSecretMetadata :exec
 DELETE FROM secrets_metadata
-WHERE key = $1 and secret_id = $2;
\ No newline at end of file
+WHERE key = $1 and secret_id = $2;
+
+-- name: DeleteAccountSecretsMetadata :exec
+DELETE FROM secrets_metadata
+WHERE secret_id IN (SELECT id FROM secrets WHERE account_id = $1);
@@ -0,0 +1,37 @@
+-- name: CreateShareLink :one
+INSERT INTO share_links (
+  account_id,
+  file_id,
+  token_id,
+  password_hash,
+  max_downloads,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetShareLink :one
+SELECT l.id, l.account_id, l.file_id, l.token_id, l.password_hash, l.max_downloads, l.downloads, l.revoked, l.expires_at, l.created_at, a.username
+FROM share_links l
+JOIN account a ON a.id = l.account_id
+WHERE l.token_id = $1 LIMIT 1;
+
+-- name: ListShareLinks :many
+SELECT l.id, l.account_id, l.file_id, l.token_id, l.password_hash, l.max_downloads, l.downloads, l.revoked, l.expires_at, l.created_at, f.filename, f.filepath
+FROM share_links l
+JOIN files f ON f.id = l.file_id
+WHERE l.account_id = sqlc.arg(account_id) and (sqlc.narg(file_id)::bigint IS NULL or l.file_id = sqlc.narg(file_id))
+ORDER BY l.id;
+
+-- name: CountShareLinkDownload :one
+UPDATE share_links
+  set downloads = downloads + 1
+WHERE id = $1 and revoked = false and expires_at > now() and (max_downloads = 0 or downloads < max_downloads)
+RETURNING *;
+
+-- name: RevokeShareLink :one
+UPDATE share_links
+  set revoked = true
+WHERE id = $1 and account_id = $2
+RETURNING *;
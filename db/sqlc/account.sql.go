@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 const blockAccount = `-- name: BlockAccount :exec
@@ -20,6 +21,49 @@ func (q *Queries) BlockAccount(ctx context.Context, username string) error {
 	return err
 }
 
+const bumpAccountSessionEpoch = `-- name: BumpAccountSessionEpoch :one
+UPDATE account
+  set session_epoch = session_epoch + 1
+WHERE id = $1
+RETURNING session_epoch
+`
+
+func (q *Queries) BumpAccountSessionEpoch(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, bumpAccountSessionEpoch, id)
+	var session_epoch int64
+	err := row.Scan(&session_epoch)
+	return session_epoch, err
+}
+
+const changeAccountPassword = `-- name: ChangeAccountPassword :one
+UPDATE account
+  set passhash = $2, session_epoch = session_epoch + 1
+WHERE id = $1
+RETURNING id, username, passhash, blocked, created_at, quota_bytes, quota_files, quota_secrets, session_epoch
+`
+
+type ChangeAccountPasswordParams struct {
+	ID       int64  `json:"id"`
+	Passhash string `json:"passhash"`
+}
+
+func (q *Queries) ChangeAccountPassword(ctx context.Context, arg ChangeAccountPasswordParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, changeAccountPassword, arg.ID, arg.Passhash)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Passhash,
+		&i.Blocked,
+		&i.CreatedAt,
+		&i.QuotaBytes,
+		&i.QuotaFiles,
+		&i.QuotaSecrets,
+		&i.SessionEpoch,
+	)
+	return i, err
+}
+
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO account (
   username,
@@ -27,7 +71,7 @@ INSERT INTO account (
 ) VALUES (
   $1, $2
 )
-RETURNING id, username, passhash, blocked, created_at
+RETURNING id, username, passhash, blocked, created_at, quota_bytes, quota_files, quota_secrets, session_epoch
 `
 
 type CreateAccountParams struct {
@@ -44,6 +88,10 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Passhash,
 		&i.Blocked,
 		&i.CreatedAt,
+		&i.QuotaBytes,
+		&i.QuotaFiles,
+		&i.QuotaSecrets,
+		&i.SessionEpoch,
 	)
 	return i, err
 }
@@ -59,7 +107,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, username string) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, username, passhash, blocked, created_at FROM account
+SELECT id, username, passhash, blocked, created_at, quota_bytes, quota_files, quota_secrets, session_epoch FROM account
 WHERE username = $1 LIMIT 1
 `
 
@@ -72,6 +120,160 @@ func (q *Queries) GetAccount(ctx context.Context, username string) (Account, err
 		&i.Passhash,
 		&i.Blocked,
 		&i.CreatedAt,
+		&i.QuotaBytes,
+		&i.QuotaFiles,
+		&i.QuotaSecrets,
+		&i.SessionEpoch,
+	)
+	return i, err
+}
+
+const getAccountByID = `-- name: GetAccountByID :one
+SELECT id, username, passhash, blocked, created_at, quota_bytes, quota_files, quota_secrets, session_epoch FROM account
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountByID(ctx context.Context, id int64) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByID, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Passhash,
+		&i.Blocked,
+		&i.CreatedAt,
+		&i.QuotaBytes,
+		&i.QuotaFiles,
+		&i.QuotaSecrets,
+		&i.SessionEpoch,
+	)
+	return i, err
+}
+
+const getAccountForUpdate = `-- name: GetAccountForUpdate :one
+SELECT id, username, passhash, blocked, created_at, quota_bytes, quota_files, quota_secrets, session_epoch FROM account
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountForUpdate, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Passhash,
+		&i.Blocked,
+		&i.CreatedAt,
+		&i.QuotaBytes,
+		&i.QuotaFiles,
+		&i.QuotaSecrets,
+		&i.SessionEpoch,
 	)
 	return i, err
 }
+
+const getRefreshTokenAccountForUpdate = `-- name: GetRefreshTokenAccountForUpdate :one
+SELECT id, username, passhash, blocked, created_at, quota_bytes, quota_files, quota_secrets, session_epoch FROM account
+WHERE id = (
+  SELECT account_id FROM refresh_tokens
+  WHERE token_hash = $1
+) LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetRefreshTokenAccountForUpdate(ctx context.Context, tokenHash string) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getRefreshTokenAccountForUpdate, tokenHash)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Passhash,
+		&i.Blocked,
+		&i.CreatedAt,
+		&i.QuotaBytes,
+		&i.QuotaFiles,
+		&i.QuotaSecrets,
+		&i.SessionEpoch,
+	)
+	return i, err
+}
+
+const listAccounts = `-- name: ListAccounts :many
+SELECT id, username, passhash, blocked, created_at, quota_bytes, quota_files, quota_secrets, session_epoch FROM account
+ORDER BY id
+LIMIT $1
+OFFSET $2
+`
+
+type ListAccountsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Account
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Passhash,
+			&i.Blocked,
+			&i.CreatedAt,
+			&i.QuotaBytes,
+			&i.QuotaFiles,
+			&i.QuotaSecrets,
+			&i.SessionEpoch,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setAccountQuota = `-- name: SetAccountQuota :exec
+UPDATE account
+  set quota_bytes = $2, quota_files = $3, quota_secrets = $4
+WHERE username = $1
+`
+
+type SetAccountQuotaParams struct {
+	Username     string        `json:"username"`
+	QuotaBytes   sql.NullInt64 `json:"quota_bytes"`
+	QuotaFiles   sql.NullInt64 `json:"quota_files"`
+	QuotaSecrets sql.NullInt64 `json:"quota_secrets"`
+}
+
+func (q *Queries) SetAccountQuota(ctx context.Context, arg SetAccountQuotaParams) error {
+	_, err := q.db.ExecContext(ctx, setAccountQuota,
+		arg.Username,
+		arg.QuotaBytes,
+		arg.QuotaFiles,
+		arg.QuotaSecrets,
+	)
+	return err
+}
+
+const unblockAccount = `-- name: UnblockAccount :exec
+UPDATE account
+  set blocked = false
+WHERE username = $1
+`
+
+func (q *Queries) UnblockAccount(ctx context.Context, username string) error {
+	_, err := q.db.ExecContext(ctx, unblockAccount, username)
+	return err
+}
@@ -55,3 +55,17 @@ func TestDeleteAccount(t *testing.T) {
 	require.EqualError(t, err, sql.ErrNoRows.Error())
 	require.Empty(t, acc1)
 }
+
+func TestUnblockAccount(t *testing.T) {
+	acc := createRandomAccount(t)
+
+	err := testQueries.BlockAccount(context.TODO(), acc.Username)
+	require.NoError(t, err)
+
+	err = testQueries.UnblockAccount(context.TODO(), acc.Username)
+	require.NoError(t, err)
+
+	acc1, err := testQueries.GetAccount(context.TODO(), acc.Username)
+	require.NoError(t, err)
+	require.False(t, acc1.Blocked)
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCertificates(t *testing.T) {
+	account := createRandomAccount(t)
+	identity := "dns:" + util.RandomString(12) + ".ci.internal"
+
+	cert, err := testQueries.CreateClientCertificate(context.Background(), CreateClientCertificateParams{
+		Identity:  identity,
+		AccountID: account.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, identity, cert.Identity)
+	require.NotZero(t, cert.CreatedAt)
+
+	// An identity maps to one account only.
+	other := createRandomAccount(t)
+	_, err = testQueries.CreateClientCertificate(context.Background(), CreateClientCertificateParams{
+		Identity:  identity,
+		AccountID: other.ID,
+	})
+	require.Error(t, err)
+
+	row, err := testQueries.GetClientCertificateAccount(context.Background(), identity)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, row.AccountID)
+	require.Equal(t, account.Username, row.Username)
+
+	certs, err := testQueries.ListClientCertificates(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, certs, 1)
+
+	deleted, err := testQueries.DeleteClientCertificate(context.Background(), identity)
+	require.NoError(t, err)
+	require.Equal(t, cert.ID, deleted.ID)
+
+	_, err = testQueries.GetClientCertificateAccount(context.Background(), identity)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
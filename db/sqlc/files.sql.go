@@ -7,6 +7,8 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createFile = `-- name: CreateFile :one
@@ -18,7 +20,7 @@ INSERT INTO files (
 ) VALUES (
   $1, $2, $3, $4
 )
-RETURNING id, account_id, filename, filepath, ready, created_at
+RETURNING id, account_id, filename, filepath, ready, created_at, blob_hash, version, touched_at
 `
 
 type CreateFileParams struct {
@@ -43,37 +45,128 @@ func (q *Queries) CreateFile(ctx context.Context, arg CreateFileParams) (File, e
 		&i.Filepath,
 		&i.Ready,
 		&i.CreatedAt,
+		&i.BlobHash,
+		&i.Version,
+		&i.TouchedAt,
 	)
 	return i, err
 }
 
-const deleteFile = `-- name: DeleteFile :exec
+const deleteFile = `-- name: DeleteFile :one
 DELETE FROM files
-WHERE filename = $1 and account_id = $2
+WHERE filename = $1 and account_id = $2 and filepath = $3
+RETURNING id, account_id, filename, filepath, ready, created_at, blob_hash, version, touched_at
 `
 
 type DeleteFileParams struct {
 	Filename  string `json:"filename"`
 	AccountID int64  `json:"account_id"`
+	Filepath  string `json:"filepath"`
+}
+
+func (q *Queries) DeleteFile(ctx context.Context, arg DeleteFileParams) (File, error) {
+	row := q.db.QueryRowContext(ctx, deleteFile, arg.Filename, arg.AccountID, arg.Filepath)
+	var i File
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Filename,
+		&i.Filepath,
+		&i.Ready,
+		&i.CreatedAt,
+		&i.BlobHash,
+		&i.Version,
+		&i.TouchedAt,
+	)
+	return i, err
 }
 
-func (q *Queries) DeleteFile(ctx context.Context, arg DeleteFileParams) error {
-	_, err := q.db.ExecContext(ctx, deleteFile, arg.Filename, arg.AccountID)
+const deletePendingFile = `-- name: DeletePendingFile :exec
+DELETE FROM files
+WHERE id = $1 and ready = false
+`
+
+func (q *Queries) DeletePendingFile(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deletePendingFile, id)
 	return err
 }
 
+const deleteStaleFile = `-- name: DeleteStaleFile :execrows
+DELETE FROM files
+WHERE id = $1 and ready = false and touched_at < $2
+`
+
+type DeleteStaleFileParams struct {
+	ID        int64     `json:"id"`
+	TouchedAt time.Time `json:"touched_at"`
+}
+
+func (q *Queries) DeleteStaleFile(ctx context.Context, arg DeleteStaleFileParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteStaleFile, arg.ID, arg.TouchedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getFile = `-- name: GetFile :one
-SELECT id, account_id, filename, filepath, ready, created_at FROM files
-WHERE filename = $1 and account_id = $2 LIMIT 1
+SELECT id, account_id, filename, filepath, ready, created_at, blob_hash, version, touched_at FROM files
+WHERE filename = $1 and account_id = $2 and filepath = $3 LIMIT 1
 `
 
 type GetFileParams struct {
 	Filename  string `json:"filename"`
 	AccountID int64  `json:"account_id"`
+	Filepath  string `json:"filepath"`
 }
 
 func (q *Queries) GetFile(ctx context.Context, arg GetFileParams) (File, error) {
-	row := q.db.QueryRowContext(ctx, getFile, arg.Filename, arg.AccountID)
+	row := q.db.QueryRowContext(ctx, getFile, arg.Filename, arg.AccountID, arg.Filepath)
+	var i File
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Filename,
+		&i.Filepath,
+		&i.Ready,
+		&i.CreatedAt,
+		&i.BlobHash,
+		&i.Version,
+		&i.TouchedAt,
+	)
+	return i, err
+}
+
+const getFileByID = `-- name: GetFileByID :one
+SELECT id, account_id, filename, filepath, ready, created_at, blob_hash, version, touched_at FROM files
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetFileByID(ctx context.Context, id int64) (File, error) {
+	row := q.db.QueryRowContext(ctx, getFileByID, id)
+	var i File
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Filename,
+		&i.Filepath,
+		&i.Ready,
+		&i.CreatedAt,
+		&i.BlobHash,
+		&i.Version,
+		&i.TouchedAt,
+	)
+	return i, err
+}
+
+const getFileForUpdate = `-- name: GetFileForUpdate :one
+SELECT id, account_id, filename, filepath, ready, created_at, blob_hash, version, touched_at FROM files
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetFileForUpdate(ctx context.Context, id int64) (File, error) {
+	row := q.db.QueryRowContext(ctx, getFileForUpdate, id)
 	var i File
 	err := row.Scan(
 		&i.ID,
@@ -82,12 +175,124 @@ func (q *Queries) GetFile(ctx context.Context, arg GetFileParams) (File, error)
 		&i.Filepath,
 		&i.Ready,
 		&i.CreatedAt,
+		&i.BlobHash,
+		&i.Version,
+		&i.TouchedAt,
 	)
 	return i, err
 }
 
+const getFilesUsage = `-- name: GetFilesUsage :one
+SELECT
+  (SELECT COUNT(*) FROM files WHERE files.account_id = $1) AS file_count,
+  (
+    (SELECT COALESCE(SUM(b.size), 0) FROM files f
+     JOIN blobs b ON b.hash = f.blob_hash
+     WHERE f.account_id = $1) +
+    (SELECT COALESCE(SUM(b.size), 0) FROM file_versions v
+     JOIN files f ON f.id = v.file_id
+     JOIN blobs b ON b.hash = v.blob_hash
+     WHERE f.account_id = $1)
+  )::bigint AS total_bytes
+`
+
+type GetFilesUsageRow struct {
+	FileCount  int64 `json:"file_count"`
+	TotalBytes int64 `json:"total_bytes"`
+}
+
+func (q *Queries) GetFilesUsage(ctx context.Context, accountID int64) (GetFilesUsageRow, error) {
+	row := q.db.QueryRowContext(ctx, getFilesUsage, accountID)
+	var i GetFilesUsageRow
+	err := row.Scan(&i.FileCount, &i.TotalBytes)
+	return i, err
+}
+
+const listBlobFiles = `-- name: ListBlobFiles :many
+SELECT id, account_id, filename, filepath, ready, created_at, blob_hash, version, touched_at FROM files
+WHERE blob_hash = $1
+ORDER BY id
+`
+
+func (q *Queries) ListBlobFiles(ctx context.Context, blobHash sql.NullString) ([]File, error) {
+	rows, err := q.db.QueryContext(ctx, listBlobFiles, blobHash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []File
+	for rows.Next() {
+		var i File
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Filename,
+			&i.Filepath,
+			&i.Ready,
+			&i.CreatedAt,
+			&i.BlobHash,
+			&i.Version,
+			&i.TouchedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDirectoryFiles = `-- name: ListDirectoryFiles :many
+SELECT id, account_id, filename, filepath, ready, created_at, blob_hash, version, touched_at FROM files
+WHERE account_id = $1 and (filepath = $2 or starts_with(filepath, $2 || '/'))
+ORDER BY id
+`
+
+type ListDirectoryFilesParams struct {
+	AccountID int64  `json:"account_id"`
+	Filepath  string `json:"filepath"`
+}
+
+func (q *Queries) ListDirectoryFiles(ctx context.Context, arg ListDirectoryFilesParams) ([]File, error) {
+	rows, err := q.db.QueryContext(ctx, listDirectoryFiles, arg.AccountID, arg.Filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []File
+	for rows.Next() {
+		var i File
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Filename,
+			&i.Filepath,
+			&i.Ready,
+			&i.CreatedAt,
+			&i.BlobHash,
+			&i.Version,
+			&i.TouchedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listFiles = `-- name: ListFiles :many
-SELECT id, account_id, filename, filepath, ready, created_at FROM files
+SELECT id, account_id, filename, filepath, ready, created_at, blob_hash, version, touched_at FROM files
 WHERE account_id = $1 
 ORDER BY filename
 `
@@ -108,6 +313,93 @@ func (q *Queries) ListFiles(ctx context.Context, accountID int64) ([]File, error
 			&i.Filepath,
 			&i.Ready,
 			&i.CreatedAt,
+			&i.BlobHash,
+			&i.Version,
+			&i.TouchedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLegacyFiles = `-- name: ListLegacyFiles :many
+SELECT id, account_id, filename, filepath, ready, created_at, blob_hash, version, touched_at FROM files
+WHERE ready = true and blob_hash IS NULL and id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListLegacyFilesParams struct {
+	ID    int64 `json:"id"`
+	Limit int32 `json:"limit"`
+}
+
+func (q *Queries) ListLegacyFiles(ctx context.Context, arg ListLegacyFilesParams) ([]File, error) {
+	rows, err := q.db.QueryContext(ctx, listLegacyFiles, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []File
+	for rows.Next() {
+		var i File
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Filename,
+			&i.Filepath,
+			&i.Ready,
+			&i.CreatedAt,
+			&i.BlobHash,
+			&i.Version,
+			&i.TouchedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStaleFiles = `-- name: ListStaleFiles :many
+SELECT id, account_id, filename, filepath, ready, created_at, blob_hash, version, touched_at FROM files
+WHERE ready = false and touched_at < $1
+ORDER BY id
+`
+
+func (q *Queries) ListStaleFiles(ctx context.Context, touchedAt time.Time) ([]File, error) {
+	rows, err := q.db.QueryContext(ctx, listStaleFiles, touchedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []File
+	for rows.Next() {
+		var i File
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Filename,
+			&i.Filepath,
+			&i.Ready,
+			&i.CreatedAt,
+			&i.BlobHash,
+			&i.Version,
+			&i.TouchedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -138,19 +430,65 @@ func (q *Queries) MarkFileReady(ctx context.Context, arg MarkFileReadyParams) er
 	return err
 }
 
-const updateFilePath = `-- name: UpdateFilePath :exec
+const setFileBlob = `-- name: SetFileBlob :execrows
 UPDATE files
-  set filepath = $3
-WHERE filename = $1 and account_id = $2
+  set blob_hash = $2, ready = true, version = version + 1
+WHERE id = $1
 `
 
-type UpdateFilePathParams struct {
-	Filename  string `json:"filename"`
-	AccountID int64  `json:"account_id"`
-	Filepath  string `json:"filepath"`
+type SetFileBlobParams struct {
+	ID       int64          `json:"id"`
+	BlobHash sql.NullString `json:"blob_hash"`
+}
+
+func (q *Queries) SetFileBlob(ctx context.Context, arg SetFileBlobParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setFileBlob, arg.ID, arg.BlobHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-func (q *Queries) UpdateFilePath(ctx context.Context, arg UpdateFilePathParams) error {
-	_, err := q.db.ExecContext(ctx, updateFilePath, arg.Filename, arg.AccountID, arg.Filepath)
+const touchFile = `-- name: TouchFile :exec
+UPDATE files
+  set touched_at = $2
+WHERE id = $1 and ready = false
+`
+
+type TouchFileParams struct {
+	ID        int64     `json:"id"`
+	TouchedAt time.Time `json:"touched_at"`
+}
+
+func (q *Queries) TouchFile(ctx context.Context, arg TouchFileParams) error {
+	_, err := q.db.ExecContext(ctx, touchFile, arg.ID, arg.TouchedAt)
 	return err
 }
+
+const updateFilePath = `-- name: UpdateFilePath :execrows
+UPDATE files
+  set filepath = $1, filename = $2
+WHERE filename = $3 and account_id = $4 and filepath = $5
+`
+
+type UpdateFilePathParams struct {
+	NewFilepath string `json:"new_filepath"`
+	NewFilename string `json:"new_filename"`
+	Filename    string `json:"filename"`
+	AccountID   int64  `json:"account_id"`
+	Filepath    string `json:"filepath"`
+}
+
+func (q *Queries) UpdateFilePath(ctx context.Context, arg UpdateFilePathParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateFilePath,
+		arg.NewFilepath,
+		arg.NewFilename,
+		arg.Filename,
+		arg.AccountID,
+		arg.Filepath,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -7,8 +7,22 @@ package db
 
 import (
 	"context"
+
+	"github.com/lib/pq"
 )
 
+const countUserFileMetadata = `-- name: CountUserFileMetadata :one
+SELECT COUNT(*) FROM files_metadata
+WHERE file_id = $1 and system = false
+`
+
+func (q *Queries) CountUserFileMetadata(ctx context.Context, fileID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUserFileMetadata, fileID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createFileMetadata = `-- name: CreateFileMetadata :one
 INSERT INTO files_metadata (
   file_id,
@@ -17,7 +31,7 @@ INSERT INTO files_metadata (
 ) VALUES (
   $1, $2, $3
 )
-RETURNING id, file_id, key, value, created_at
+RETURNING id, file_id, key, value, created_at, system
 `
 
 type CreateFileMetadataParams struct {
@@ -35,13 +49,14 @@ func (q *Queries) CreateFileMetadata(ctx context.Context, arg CreateFileMetadata
 		&i.Key,
 		&i.Value,
 		&i.CreatedAt,
+		&i.System,
 	)
 	return i, err
 }
 
-const deleteFileMetadata = `-- name: DeleteFileMetadata :exec
+const deleteFileMetadata = `-- name: DeleteFileMetadata :execrows
 DELETE FROM files_metadata
-WHERE key = $1 and file_id = $2
+WHERE key = $1 and file_id = $2 and system = false
 `
 
 type DeleteFileMetadataParams struct {
@@ -49,13 +64,16 @@ type DeleteFileMetadataParams struct {
 	FileID int64  `json:"file_id"`
 }
 
-func (q *Queries) DeleteFileMetadata(ctx context.Context, arg DeleteFileMetadataParams) error {
-	_, err := q.db.ExecContext(ctx, deleteFileMetadata, arg.Key, arg.FileID)
-	return err
+func (q *Queries) DeleteFileMetadata(ctx context.Context, arg DeleteFileMetadataParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFileMetadata, arg.Key, arg.FileID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const listFileMetadata = `-- name: ListFileMetadata :many
-SELECT id, file_id, key, value, created_at FROM files_metadata
+SELECT id, file_id, key, value, created_at, system FROM files_metadata
 WHERE file_id = $1 
 ORDER BY key
 `
@@ -75,6 +93,7 @@ func (q *Queries) ListFileMetadata(ctx context.Context, fileID int64) ([]FilesMe
 			&i.Key,
 			&i.Value,
 			&i.CreatedAt,
+			&i.System,
 		); err != nil {
 			return nil, err
 		}
@@ -89,6 +108,72 @@ func (q *Queries) ListFileMetadata(ctx context.Context, fileID int64) ([]FilesMe
 	return items, nil
 }
 
+const listFilesMetadata = `-- name: ListFilesMetadata :many
+SELECT id, file_id, key, value, created_at, system FROM files_metadata
+WHERE file_id = ANY($1::bigint[])
+ORDER BY file_id, key
+`
+
+func (q *Queries) ListFilesMetadata(ctx context.Context, fileIds []int64) ([]FilesMetadatum, error) {
+	rows, err := q.db.QueryContext(ctx, listFilesMetadata, pq.Array(fileIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FilesMetadatum
+	for rows.Next() {
+		var i FilesMetadatum
+		if err := rows.Scan(
+			&i.ID,
+			&i.FileID,
+			&i.Key,
+			&i.Value,
+			&i.CreatedAt,
+			&i.System,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setFileMetadata = `-- name: SetFileMetadata :exec
+INSERT INTO files_metadata (
+  file_id,
+  system,
+  key,
+  value
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (file_id, system, key) DO UPDATE
+  set value = excluded.value
+`
+
+type SetFileMetadataParams struct {
+	FileID int64  `json:"file_id"`
+	System bool   `json:"system"`
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+}
+
+func (q *Queries) SetFileMetadata(ctx context.Context, arg SetFileMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, setFileMetadata,
+		arg.FileID,
+		arg.System,
+		arg.Key,
+		arg.Value,
+	)
+	return err
+}
+
 const updateFileMetadata = `-- name: UpdateFileMetadata :exec
 UPDATE files_metadata
   set value = $3
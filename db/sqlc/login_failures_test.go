@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginFailures(t *testing.T) {
+	username := util.RandomUser()
+	now := time.Now()
+
+	for i := 1; i <= 3; i++ {
+		row, err := testQueries.RecordLoginFailure(context.Background(), RecordLoginFailureParams{
+			Scope:       "username",
+			Key:         username,
+			FailedAt:    now,
+			WindowStart: now.Add(-time.Hour),
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(i), row.Failures)
+	}
+
+	// A lock is never shortened.
+	for _, until := range []time.Time{now.Add(time.Minute), now.Add(time.Second)} {
+		_, err := testQueries.LockLogin(context.Background(), LockLoginParams{
+			LockedUntil: until,
+			Scope:       "username",
+			Key:         username,
+		})
+		require.NoError(t, err)
+	}
+
+	rows, err := testQueries.ListLoginFailures(context.Background(), ListLoginFailuresParams{Scope: "username", Key: username, Ip: "192.0.2.1"})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.WithinDuration(t, now.Add(time.Minute), rows[0].LockedUntil, time.Second)
+
+	// The failures of a quiet key start over.
+	later := now.Add(2 * time.Hour)
+	row, err := testQueries.RecordLoginFailure(context.Background(), RecordLoginFailureParams{
+		Scope:       "username",
+		Key:         username,
+		FailedAt:    later,
+		WindowStart: later.Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), row.Failures)
+
+	deleted, err := testQueries.DeleteStaleLoginFailures(context.Background(), DeleteStaleLoginFailuresParams{
+		WindowStart: later.Add(time.Second),
+		Now:         later.Add(time.Second),
+	})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	rows, err = testQueries.ListLoginFailures(context.Background(), ListLoginFailuresParams{Scope: "username", Key: username})
+	require.NoError(t, err)
+	require.Empty(t, rows)
+}
@@ -5,6 +5,7 @@
 package db
 
 import (
+	"database/sql"
 	"time"
 )
 
@@ -14,6 +15,62 @@ type Account struct {
 	Passhash  string    `json:"passhash"`
 	Blocked   bool      `json:"blocked"`
 	CreatedAt time.Time `json:"created_at"`
+	// overrides the server default when set, zero means unlimited
+	QuotaBytes sql.NullInt64 `json:"quota_bytes"`
+	// overrides the server default when set, zero means unlimited
+	QuotaFiles sql.NullInt64 `json:"quota_files"`
+	// overrides the server default when set, zero means unlimited
+	QuotaSecrets sql.NullInt64 `json:"quota_secrets"`
+	// access tokens issued with a lower epoch are rejected
+	SessionEpoch int64 `json:"session_epoch"`
+}
+
+type AccountTotp struct {
+	AccountID int64 `json:"account_id"`
+	// TOTP secret, sealed with the server key when sealed is set
+	Secret []byte `json:"secret"`
+	Sealed bool   `json:"sealed"`
+	// set once the first code is confirmed
+	Enabled bool `json:"enabled"`
+	// time step of the last accepted code, codes cannot be replayed
+	LastStep  int64     `json:"last_step"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Blob struct {
+	// hex encoded sha256 of the content
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+	// number of files and file_versions rows pointing to the blob
+	Refcount  int64     `json:"refcount"`
+	CreatedAt time.Time `json:"created_at"`
+	// compression codec of the stored content: identity, gzip or zstd
+	Codec string `json:"codec"`
+	// data key of the stored content wrapped with the server key, NULL for plaintext content
+	WrappedKey []byte `json:"wrapped_key"`
+}
+
+// file content for the postgres storage backend
+type BlobChunk struct {
+	Hash string `json:"hash"`
+	Seq  int32  `json:"seq"`
+	Data []byte `json:"data"`
+}
+
+type ClientCertificate struct {
+	ID int64 `json:"id"`
+	// subject:<distinguished name>, dns:<name>, email:<address> or uri:<uri> of the verified client certificate
+	Identity  string    `json:"identity"`
+	AccountID int64     `json:"account_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Directory struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// slash separated path without leading and trailing slashes
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type File struct {
@@ -22,7 +79,21 @@ type File struct {
 	Filename  string `json:"filename"`
 	Filepath  string `json:"filepath"`
 	// file ready or not for listing
-	Ready     bool      `json:"ready"`
+	Ready     bool           `json:"ready"`
+	CreatedAt time.Time      `json:"created_at"`
+	BlobHash  sql.NullString `json:"blob_hash"`
+	// number of the current content version, 0 before the first upload
+	Version int64 `json:"version"`
+	// last sign of life of the upload while the file is not ready
+	TouchedAt time.Time `json:"touched_at"`
+}
+
+type FileVersion struct {
+	ID       int64  `json:"id"`
+	FileID   int64  `json:"file_id"`
+	Version  int64  `json:"version"`
+	BlobHash string `json:"blob_hash"`
+	// time the version was replaced by a newer one
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -32,6 +103,60 @@ type FilesMetadatum struct {
 	Key       string    `json:"key"`
 	Value     string    `json:"value"`
 	CreatedAt time.Time `json:"created_at"`
+	// recorded by the server on upload, the user cannot edit it
+	System bool `json:"system"`
+}
+
+type LoginChallenge struct {
+	ID int64 `json:"id"`
+	// hex encoded sha256 of the challenge handed out by Login
+	ChallengeHash string    `json:"challenge_hash"`
+	AccountID     int64     `json:"account_id"`
+	Attempts      int32     `json:"attempts"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type LoginFailure struct {
+	// what the key is: username or ip
+	Scope string `json:"scope"`
+	Key   string `json:"key"`
+	// failed logins since the last success or the last quiet period
+	Failures      int32     `json:"failures"`
+	LastFailureAt time.Time `json:"last_failure_at"`
+	// logins of the key are refused until this time
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+type RecoveryCode struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// hex encoded sha256 of the code, the code itself is not stored
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type RefreshToken struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// shared by the tokens issued by rotation from one login
+	FamilyID string `json:"family_id"`
+	// hex encoded sha256 of the token, the token itself is not stored
+	TokenHash string `json:"token_hash"`
+	// the token was exchanged for a new one, using it again revokes the family
+	Used      bool      `json:"used"`
+	Revoked   bool      `json:"revoked"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RevokedToken struct {
+	Jti       string `json:"jti"`
+	AccountID int64  `json:"account_id"`
+	// expiry of the revoked token, the row is useless after it
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Secret struct {
@@ -49,3 +174,19 @@ type SecretsMetadatum struct {
 	Value     string    `json:"value"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+type ShareLink struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	FileID    int64 `json:"file_id"`
+	// random part of the signed token, the token itself is not stored
+	TokenID string `json:"token_id"`
+	// bcrypt hash of the link password, NULL for links without one
+	PasswordHash sql.NullString `json:"password_hash"`
+	// zero means unlimited
+	MaxDownloads int64     `json:"max_downloads"`
+	Downloads    int64     `json:"downloads"`
+	Revoked      bool      `json:"revoked"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -6,32 +6,123 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 type Querier interface {
+	AttemptLoginChallenge(ctx context.Context, arg AttemptLoginChallengeParams) (LoginChallenge, error)
+	BlobChunksExist(ctx context.Context, hash string) (bool, error)
 	BlockAccount(ctx context.Context, username string) error
+	BumpAccountSessionEpoch(ctx context.Context, id int64) (int64, error)
+	ChangeAccountPassword(ctx context.Context, arg ChangeAccountPasswordParams) (Account, error)
+	CountDirectoryEntries(ctx context.Context, arg CountDirectoryEntriesParams) (int64, error)
+	CountSecrets(ctx context.Context, accountID int64) (int64, error)
+	CountShareLinkDownload(ctx context.Context, id int64) (ShareLink, error)
+	CountUserFileMetadata(ctx context.Context, fileID int64) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountTotp(ctx context.Context, arg CreateAccountTotpParams) (AccountTotp, error)
+	CreateBlob(ctx context.Context, arg CreateBlobParams) (Blob, error)
+	CreateBlobChunk(ctx context.Context, arg CreateBlobChunkParams) error
+	CreateClientCertificate(ctx context.Context, arg CreateClientCertificateParams) (ClientCertificate, error)
+	CreateDirectory(ctx context.Context, arg CreateDirectoryParams) error
 	CreateFile(ctx context.Context, arg CreateFileParams) (File, error)
 	CreateFileMetadata(ctx context.Context, arg CreateFileMetadataParams) (FilesMetadatum, error)
+	CreateFileVersion(ctx context.Context, arg CreateFileVersionParams) (FileVersion, error)
+	CreateLoginChallenge(ctx context.Context, arg CreateLoginChallengeParams) error
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error
+	CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error)
+	CreateRevokedToken(ctx context.Context, arg CreateRevokedTokenParams) error
 	CreateSecret(ctx context.Context, arg CreateSecretParams) (Secret, error)
 	CreateSecretMetadata(ctx context.Context, arg CreateSecretMetadataParams) (SecretsMetadatum, error)
+	CreateShareLink(ctx context.Context, arg CreateShareLinkParams) (ShareLink, error)
 	DeleteAccount(ctx context.Context, username string) error
-	DeleteFile(ctx context.Context, arg DeleteFileParams) error
-	DeleteFileMetadata(ctx context.Context, arg DeleteFileMetadataParams) error
+	DeleteAccountDirectories(ctx context.Context, accountID int64) error
+	DeleteAccountSecrets(ctx context.Context, accountID int64) (int64, error)
+	DeleteAccountSecretsMetadata(ctx context.Context, accountID int64) error
+	DeleteAccountTotp(ctx context.Context, accountID int64) error
+	DeleteBlob(ctx context.Context, hash string) error
+	DeleteBlobChunks(ctx context.Context, hash string) error
+	DeleteClientCertificate(ctx context.Context, identity string) (ClientCertificate, error)
+	DeleteDirectories(ctx context.Context, arg DeleteDirectoriesParams) (int64, error)
+	DeleteExpiredLoginChallenges(ctx context.Context, expiresAt time.Time) (int64, error)
+	DeleteExpiredRefreshTokens(ctx context.Context, expiresAt time.Time) (int64, error)
+	DeleteExpiredRevokedTokens(ctx context.Context, expiresAt time.Time) (int64, error)
+	DeleteFile(ctx context.Context, arg DeleteFileParams) (File, error)
+	DeleteFileMetadata(ctx context.Context, arg DeleteFileMetadataParams) (int64, error)
+	DeleteFileVersion(ctx context.Context, id int64) (FileVersion, error)
+	DeleteFileVersions(ctx context.Context, fileID int64) ([]FileVersion, error)
+	DeleteLoginChallenge(ctx context.Context, id int64) error
+	DeleteLoginFailures(ctx context.Context, arg DeleteLoginFailuresParams) error
+	DeletePendingFile(ctx context.Context, id int64) error
+	DeleteRecoveryCodes(ctx context.Context, accountID int64) error
 	DeleteSecret(ctx context.Context, arg DeleteSecretParams) error
 	DeleteSecretMetadata(ctx context.Context, arg DeleteSecretMetadataParams) error
+	DeleteStaleFile(ctx context.Context, arg DeleteStaleFileParams) (int64, error)
+	DeleteStaleLoginFailures(ctx context.Context, arg DeleteStaleLoginFailuresParams) (int64, error)
+	EnableAccountTotp(ctx context.Context, arg EnableAccountTotpParams) (int64, error)
 	GetAccount(ctx context.Context, username string) (Account, error)
+	GetAccountByID(ctx context.Context, id int64) (Account, error)
+	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountTotp(ctx context.Context, accountID int64) (AccountTotp, error)
+	GetAccountTotpForUpdate(ctx context.Context, accountID int64) (AccountTotp, error)
+	GetBlob(ctx context.Context, hash string) (Blob, error)
+	GetBlobChunk(ctx context.Context, arg GetBlobChunkParams) ([]byte, error)
+	GetClientCertificateAccount(ctx context.Context, identity string) (GetClientCertificateAccountRow, error)
+	GetDirectory(ctx context.Context, arg GetDirectoryParams) (Directory, error)
 	GetFile(ctx context.Context, arg GetFileParams) (File, error)
+	GetFileByID(ctx context.Context, id int64) (File, error)
+	GetFileForUpdate(ctx context.Context, id int64) (File, error)
+	GetFileVersion(ctx context.Context, arg GetFileVersionParams) (FileVersion, error)
+	GetFilesUsage(ctx context.Context, accountID int64) (GetFilesUsageRow, error)
+	GetLoginFailureForUpdate(ctx context.Context, arg GetLoginFailureForUpdateParams) (LoginFailure, error)
+	GetRefreshTokenAccountForUpdate(ctx context.Context, tokenHash string) (Account, error)
+	GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (RefreshToken, error)
 	GetSecret(ctx context.Context, arg GetSecretParams) (Secret, error)
+	GetShareLink(ctx context.Context, tokenID string) (GetShareLinkRow, error)
+	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListBlobChunkHashes(ctx context.Context) ([]string, error)
+	ListBlobFileVersions(ctx context.Context, blobHash string) ([]ListBlobFileVersionsRow, error)
+	ListBlobFiles(ctx context.Context, blobHash sql.NullString) ([]File, error)
+	ListBlobs(ctx context.Context, arg ListBlobsParams) ([]Blob, error)
+	ListClientCertificates(ctx context.Context, accountID int64) ([]ClientCertificate, error)
+	ListDirectories(ctx context.Context, accountID int64) ([]Directory, error)
+	ListDirectoryFiles(ctx context.Context, arg ListDirectoryFilesParams) ([]File, error)
+	ListExpiredFileVersions(ctx context.Context, arg ListExpiredFileVersionsParams) ([]ListExpiredFileVersionsRow, error)
 	ListFileMetadata(ctx context.Context, fileID int64) ([]FilesMetadatum, error)
+	ListFileVersions(ctx context.Context, fileID int64) ([]FileVersion, error)
 	ListFiles(ctx context.Context, accountID int64) ([]File, error)
+	ListFilesMetadata(ctx context.Context, fileIds []int64) ([]FilesMetadatum, error)
+	ListLegacyFiles(ctx context.Context, arg ListLegacyFilesParams) ([]File, error)
+	ListLoginFailures(ctx context.Context, arg ListLoginFailuresParams) ([]LoginFailure, error)
+	ListRevokedTokens(ctx context.Context, expiresAt time.Time) ([]RevokedToken, error)
 	ListSecretMetadata(ctx context.Context, secretID int64) ([]SecretsMetadatum, error)
 	ListSecrets(ctx context.Context, accountID int64) ([]Secret, error)
+	ListShareLinks(ctx context.Context, arg ListShareLinksParams) ([]ListShareLinksRow, error)
+	ListStaleFiles(ctx context.Context, touchedAt time.Time) ([]File, error)
+	LockBlob(ctx context.Context, hashtext string) error
+	LockLogin(ctx context.Context, arg LockLoginParams) (LoginFailure, error)
 	MarkFileReady(ctx context.Context, arg MarkFileReadyParams) error
+	MarkRefreshTokenUsed(ctx context.Context, id int64) error
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginFailure, error)
+	ReferenceBlob(ctx context.Context, hash string) (Blob, error)
+	ReleaseBlob(ctx context.Context, hash string) (Blob, error)
+	RevokeAccountRefreshTokens(ctx context.Context, accountID int64) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeRefreshTokenFamilyOfToken(ctx context.Context, arg RevokeRefreshTokenFamilyOfTokenParams) (int64, error)
+	RevokeShareLink(ctx context.Context, arg RevokeShareLinkParams) (ShareLink, error)
+	SetAccountQuota(ctx context.Context, arg SetAccountQuotaParams) error
+	SetFileBlob(ctx context.Context, arg SetFileBlobParams) (int64, error)
+	SetFileMetadata(ctx context.Context, arg SetFileMetadataParams) error
+	TouchFile(ctx context.Context, arg TouchFileParams) error
+	UnblockAccount(ctx context.Context, username string) error
+	UndoLoginFailure(ctx context.Context, arg UndoLoginFailureParams) error
 	UpdateFileMetadata(ctx context.Context, arg UpdateFileMetadataParams) error
-	UpdateFilePath(ctx context.Context, arg UpdateFilePathParams) error
+	UpdateFilePath(ctx context.Context, arg UpdateFilePathParams) (int64, error)
 	UpdateSecret(ctx context.Context, arg UpdateSecretParams) error
 	UpdateSecretMetadata(ctx context.Context, arg UpdateSecretMetadataParams) error
+	UseAccountTotpStep(ctx context.Context, arg UseAccountTotpStepParams) (int64, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
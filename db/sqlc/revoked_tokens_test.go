@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokedTokens(t *testing.T) {
+	account := createRandomAccount(t)
+	now := time.Now()
+
+	live := util.RandomString(32)
+	expired := util.RandomString(32)
+	for jti, expiresAt := range map[string]time.Time{live: now.Add(time.Hour), expired: now.Add(-time.Hour)} {
+		err := testQueries.CreateRevokedToken(context.Background(), CreateRevokedTokenParams{
+			Jti:       jti,
+			AccountID: account.ID,
+			ExpiresAt: expiresAt,
+		})
+		require.NoError(t, err)
+	}
+
+	// Revoking twice is fine.
+	err := testQueries.CreateRevokedToken(context.Background(), CreateRevokedTokenParams{
+		Jti:       live,
+		AccountID: account.ID,
+		ExpiresAt: now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	rows, err := testQueries.ListRevokedTokens(context.Background(), now)
+	require.NoError(t, err)
+	jtis := make(map[string]bool)
+	for _, row := range rows {
+		jtis[row.Jti] = true
+	}
+	require.True(t, jtis[live])
+	require.False(t, jtis[expired])
+
+	deleted, err := testQueries.DeleteExpiredRevokedTokens(context.Background(), now)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	rows, err = testQueries.ListRevokedTokens(context.Background(), now.Add(-2*time.Hour))
+	require.NoError(t, err)
+	for _, row := range rows {
+		require.NotEqual(t, expired, row.Jti)
+	}
+}
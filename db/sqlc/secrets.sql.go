@@ -9,6 +9,18 @@ import (
 	"context"
 )
 
+const countSecrets = `-- name: CountSecrets :one
+SELECT COUNT(*) FROM secrets
+WHERE account_id = $1
+`
+
+func (q *Queries) CountSecrets(ctx context.Context, accountID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSecrets, accountID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createSecret = `-- name: CreateSecret :one
 INSERT INTO secrets (
   account_id,
@@ -39,6 +51,19 @@ func (q *Queries) CreateSecret(ctx context.Context, arg CreateSecretParams) (Sec
 	return i, err
 }
 
+const deleteAccountSecrets = `-- name: DeleteAccountSecrets :execrows
+DELETE FROM secrets
+WHERE account_id = $1
+`
+
+func (q *Queries) DeleteAccountSecrets(ctx context.Context, accountID int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAccountSecrets, accountID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteSecret = `-- name: DeleteSecret :exec
 DELETE FROM secrets
 WHERE key = $1 and account_id = $2
@@ -39,6 +39,16 @@ func (q *Queries) CreateSecretMetadata(ctx context.Context, arg CreateSecretMeta
 	return i, err
 }
 
+const deleteAccountSecretsMetadata = `-- name: DeleteAccountSecretsMetadata :exec
+DELETE FROM secrets_metadata
+WHERE secret_id IN (SELECT id FROM secrets WHERE account_id = $1)
+`
+
+func (q *Queries) DeleteAccountSecretsMetadata(ctx context.Context, accountID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteAccountSecretsMetadata, accountID)
+	return err
+}
+
 const deleteSecretMetadata = `-- name: DeleteSecretMetadata :exec
 DELETE FROM secrets_metadata
 WHERE key = $1 and secret_id = $2
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareLinkDownloads(t *testing.T) {
+	account := createRandomAccount(t)
+	file := createRandomFile(t, account)
+
+	link, err := testQueries.CreateShareLink(context.Background(), CreateShareLinkParams{
+		AccountID:    account.ID,
+		FileID:       file.ID,
+		TokenID:      util.RandomString(32),
+		MaxDownloads: 2,
+		ExpiresAt:    time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Zero(t, link.Downloads)
+
+	for i := int64(1); i <= 2; i++ {
+		counted, err := testQueries.CountShareLinkDownload(context.Background(), link.ID)
+		require.NoError(t, err)
+		require.Equal(t, i, counted.Downloads)
+	}
+
+	_, err = testQueries.CountShareLinkDownload(context.Background(), link.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Links of other accounts cannot be revoked.
+	_, err = testQueries.RevokeShareLink(context.Background(), RevokeShareLinkParams{
+		ID:        link.ID,
+		AccountID: account.ID + 1,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	revoked, err := testQueries.RevokeShareLink(context.Background(), RevokeShareLinkParams{
+		ID:        link.ID,
+		AccountID: account.ID,
+	})
+	require.NoError(t, err)
+	require.True(t, revoked.Revoked)
+
+	links, err := testQueries.ListShareLinks(context.Background(), ListShareLinksParams{
+		AccountID: account.ID,
+		FileID:    sql.NullInt64{Int64: file.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	require.Equal(t, file.Filename, links[0].Filename)
+}
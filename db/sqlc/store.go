@@ -1,9 +1,57 @@
 package db
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrQuotaExceeded is returned by transactions refusing to take the account over its quota
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrPathConflict is returned when a file and a directory would share a path
+	ErrPathConflict = errors.New("path is taken")
+	// ErrDirectoryNotEmpty is returned when removing a directory with entries non-recursively
+	ErrDirectoryNotEmpty = errors.New("directory is not empty")
+	// ErrMetadataLimit is returned when a file would get more user metadata entries than allowed
+	ErrMetadataLimit = errors.New("too many metadata entries")
+	// ErrRefreshTokenExpired is returned when rotating a refresh token past its expiry
+	ErrRefreshTokenExpired = errors.New("refresh token is expired")
+	// ErrRefreshTokenRevoked is returned when rotating a refresh token of a revoked family
+	ErrRefreshTokenRevoked = errors.New("refresh token is revoked")
+	// ErrRefreshTokenReused is returned when a refresh token is rotated twice, its family is revoked
+	ErrRefreshTokenReused = errors.New("refresh token is reused")
+	// ErrTotpEnabled is returned when enrolling an account with two-factor authentication enabled
+	ErrTotpEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTotpNotEnabled is returned when changing the second factor of an account without one
+	ErrTotpNotEnabled = errors.New("two-factor authentication is not enabled")
+	// ErrAccountBlocked is returned when rotating a refresh token of a blocked account
+	ErrAccountBlocked = errors.New("account is blocked")
+	// ErrLoginLocked is returned when reserving a login attempt of a locked out key
+	ErrLoginLocked = errors.New("login is locked")
+)
 
 type Store interface {
 	Querier
+	CreateFileTx(ctx context.Context, arg CreateFileTxParams) (File, error)
+	AttachFileBlobTx(ctx context.Context, arg AttachFileBlobTxParams) (Blob, error)
+	DeleteFileTx(ctx context.Context, arg DeleteFileTxParams) (File, error)
+	DeleteFileVersionTx(ctx context.Context, arg DeleteFileVersionTxParams) (FileVersion, error)
+	DeleteOrphanContentTx(ctx context.Context, arg DeleteOrphanContentTxParams) (bool, error)
+	MakeDirectoryTx(ctx context.Context, arg MakeDirectoryTxParams) error
+	MoveFileTx(ctx context.Context, arg MoveFileTxParams) error
+	RemoveDirectoryTx(ctx context.Context, arg RemoveDirectoryTxParams) (int64, error)
+	UpdateFileMetadataTx(ctx context.Context, arg UpdateFileMetadataTxParams) ([]FilesMetadatum, error)
+	CreateSecretTx(ctx context.Context, arg CreateSecretTxParams) (Secret, error)
+	RotateRefreshTokenTx(ctx context.Context, arg RotateRefreshTokenTxParams) (RotateRefreshTokenTxResult, error)
+	RevokeAllSessionsTx(ctx context.Context, arg RevokeAllSessionsTxParams) (int64, error)
+	ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (Account, error)
+	EnrollTotpTx(ctx context.Context, arg EnrollTotpTxParams) (AccountTotp, error)
+	ReplaceRecoveryCodesTx(ctx context.Context, arg ReplaceRecoveryCodesTxParams) error
+	DisableTotpTx(ctx context.Context, accountID int64) error
+	DeleteAccountTx(ctx context.Context, arg DeleteAccountTxParams) (DeleteAccountTxResult, error)
+	ReserveLoginAttemptTx(ctx context.Context, arg ReserveLoginAttemptTxParams) (ReserveLoginAttemptTxResult, error)
 }
 
 type SQLStore struct {
@@ -18,3 +66,22 @@ func NewStore(db *sql.DB) Store {
 		Queries: New(db),
 	}
 }
+
+// execTx executes a function within a database transaction
+func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	q := New(tx)
+	err = fn(q)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
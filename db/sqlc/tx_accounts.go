@@ -0,0 +1,80 @@
+package db
+
+import "context"
+
+// DeleteAccountTxParams contains the input parameters of the DeleteAccountTx
+type DeleteAccountTxParams struct {
+	AccountID int64
+	// AfterRelease is called in the transaction while the blob lock is held
+	// once the last reference to the blob is gone. Only content kept in the
+	// database may be removed there, the transaction can still roll back.
+	AfterRelease func(q Querier, blob Blob) error
+}
+
+// DeleteAccountTxResult is the result of the DeleteAccountTx
+type DeleteAccountTxResult struct {
+	Account        Account
+	FilesDeleted   int64
+	SecretsDeleted int64
+}
+
+// DeleteAccountTx deletes the account with all its files, secrets and
+// directories. The files go the way DeleteFileTx takes them, so content no
+// other account refers to is removed too.
+func (store *SQLStore) DeleteAccountTx(ctx context.Context, arg DeleteAccountTxParams) (DeleteAccountTxResult, error) {
+	var result DeleteAccountTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Account, err = q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		files, err := q.ListFiles(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		// Metadata and share links of the files go with them. The blobs
+		// of all the files are released together, in one lock order.
+		var hashes []string
+		for _, file := range files {
+			_, fileHashes, err := removeFile(ctx, q, DeleteFileParams{
+				Filename:  file.Filename,
+				AccountID: file.AccountID,
+				Filepath:  file.Filepath,
+			})
+			if err != nil {
+				return err
+			}
+			hashes = append(hashes, fileHashes...)
+			result.FilesDeleted++
+		}
+
+		err = dropBlobReferences(ctx, q, hashes, arg.AfterRelease)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteAccountSecretsMetadata(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		result.SecretsDeleted, err = q.DeleteAccountSecrets(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteAccountDirectories(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		// Refresh tokens go with the account.
+		return q.DeleteAccount(ctx, result.Account.Username)
+	})
+
+	return result, err
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	other := createRandomAccount(t)
+	shared := util.RandomString(64)
+	own := util.RandomString(64)
+
+	attach := func(file File, hash string) {
+		_, err := store.AttachFileBlobTx(context.Background(), AttachFileBlobTxParams{
+			AccountID: file.AccountID,
+			FileID:    file.ID,
+			Hash:      hash,
+			Size:      10,
+			NewBlob:   newTestBlob,
+		})
+		require.NoError(t, err)
+	}
+	attach(createRandomFile(t, account), shared)
+	attach(createRandomFile(t, other), shared)
+	file := createRandomFile(t, account)
+	attach(file, own)
+	createRandomFile(t, account)
+
+	_, err := testQueries.CreateShareLink(context.Background(), CreateShareLinkParams{
+		AccountID: account.ID,
+		FileID:    file.ID,
+		TokenID:   util.RandomString(32),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	secret, err := testQueries.CreateSecret(context.Background(), CreateSecretParams{
+		AccountID: account.ID,
+		Key:       util.RandomString(6),
+		Value:     util.RandomString(6),
+	})
+	require.NoError(t, err)
+	_, err = testQueries.CreateSecretMetadata(context.Background(), CreateSecretMetadataParams{
+		SecretID: secret.ID,
+		Key:      util.RandomString(6),
+		Value:    util.RandomString(6),
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.CreateRefreshToken(context.Background(), CreateRefreshTokenParams{
+		AccountID: account.ID,
+		FamilyID:  util.RandomString(16),
+		TokenHash: util.RandomString(64),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	var released []string
+	result, err := store.DeleteAccountTx(context.Background(), DeleteAccountTxParams{
+		AccountID: account.ID,
+		AfterRelease: func(q Querier, blob Blob) error {
+			released = append(released, blob.Hash)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), result.FilesDeleted)
+	require.Equal(t, int64(1), result.SecretsDeleted)
+
+	// Content still used by the other account stays.
+	require.Equal(t, []string{own}, released)
+	blob, err := testQueries.GetBlob(context.Background(), shared)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), blob.Refcount)
+
+	_, err = testQueries.GetAccount(context.Background(), account.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.DeleteAccountTx(context.Background(), DeleteAccountTxParams{AccountID: account.ID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeleteAccountTxSharedBlobs(t *testing.T) {
+	store := NewStore(testDB)
+	hashes := []string{util.RandomString(64), util.RandomString(64)}
+
+	// The files of the two accounts refer to the blobs in opposite orders,
+	// the deletes must still lock them in one order.
+	n := 2
+	accounts := make([]Account, n)
+	for i := range accounts {
+		accounts[i] = createRandomAccount(t)
+		for j := range hashes {
+			file := createRandomFile(t, accounts[i])
+			_, err := store.AttachFileBlobTx(context.Background(), AttachFileBlobTxParams{
+				AccountID: file.AccountID,
+				FileID:    file.ID,
+				Hash:      hashes[(i+j)%len(hashes)],
+				Size:      10,
+				NewBlob:   newTestBlob,
+			})
+			require.NoError(t, err)
+		}
+	}
+
+	errs := make(chan error, n)
+	for _, account := range accounts {
+		go func(account Account) {
+			_, err := store.DeleteAccountTx(context.Background(), DeleteAccountTxParams{
+				AccountID:    account.ID,
+				AfterRelease: func(q Querier, blob Blob) error { return nil },
+			})
+			errs <- err
+		}(account)
+	}
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	for _, hash := range hashes {
+		_, err := testQueries.GetBlob(context.Background(), hash)
+		require.EqualError(t, err, sql.ErrNoRows.Error())
+	}
+}
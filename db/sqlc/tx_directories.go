@@ -0,0 +1,199 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"path"
+	"strings"
+)
+
+// MakeDirectoryTxParams contains the input parameters of the MakeDirectoryTx
+type MakeDirectoryTxParams struct {
+	AccountID int64
+	// Path is a clean path without leading and trailing slashes.
+	Path string
+}
+
+// MakeDirectoryTx creates the directory with all its parents. Existing
+// directories are left as they are.
+func (store *SQLStore) MakeDirectoryTx(ctx context.Context, arg MakeDirectoryTxParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		_, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		return makeDirectories(ctx, q, arg.AccountID, arg.Path)
+	})
+}
+
+// MoveFileTxParams contains the input parameters of the MoveFileTx
+type MoveFileTxParams struct {
+	UpdateFilePathParams
+}
+
+// MoveFileTx moves the file to the new path and name, creating the directories
+// on the new path.
+func (store *SQLStore) MoveFileTx(ctx context.Context, arg MoveFileTxParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		_, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		err = makeFilePath(ctx, q, arg.AccountID, arg.NewFilepath, arg.NewFilename)
+		if err != nil {
+			return err
+		}
+
+		rows, err := q.UpdateFilePath(ctx, arg.UpdateFilePathParams)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return sql.ErrNoRows
+		}
+
+		return nil
+	})
+}
+
+// RemoveDirectoryTxParams contains the input parameters of the RemoveDirectoryTx
+type RemoveDirectoryTxParams struct {
+	AccountID int64
+	Path      string
+	// Recursive removes the files and directories under the path, otherwise
+	// only an empty directory is removed.
+	Recursive bool
+	// AfterRelease is called in the transaction while the blob lock is held
+	// once the last reference to the blob is gone. Only content kept in the
+	// database may be removed there, the transaction can still roll back.
+	AfterRelease func(q Querier, blob Blob) error
+}
+
+// RemoveDirectoryTx removes the directory and returns the number of files
+// removed with it.
+func (store *SQLStore) RemoveDirectoryTx(ctx context.Context, arg RemoveDirectoryTxParams) (int64, error) {
+	var removed int64
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		_, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if !arg.Recursive {
+			entries, err := q.CountDirectoryEntries(ctx, CountDirectoryEntriesParams{
+				AccountID: arg.AccountID,
+				Filepath:  arg.Path,
+			})
+			if err != nil {
+				return err
+			}
+			if entries > 0 {
+				return ErrDirectoryNotEmpty
+			}
+		}
+
+		files, err := q.ListDirectoryFiles(ctx, ListDirectoryFilesParams{
+			AccountID: arg.AccountID,
+			Filepath:  arg.Path,
+		})
+		if err != nil {
+			return err
+		}
+
+		// The blobs of all the files are released together, in one lock
+		// order.
+		var hashes []string
+		for _, file := range files {
+			_, fileHashes, err := removeFile(ctx, q, DeleteFileParams{
+				Filename:  file.Filename,
+				AccountID: file.AccountID,
+				Filepath:  file.Filepath,
+			})
+			if err != nil {
+				return err
+			}
+			hashes = append(hashes, fileHashes...)
+			removed++
+		}
+
+		err = dropBlobReferences(ctx, q, hashes, arg.AfterRelease)
+		if err != nil {
+			return err
+		}
+
+		rows, err := q.DeleteDirectories(ctx, DeleteDirectoriesParams{
+			AccountID: arg.AccountID,
+			Path:      arg.Path,
+		})
+		if err != nil {
+			return err
+		}
+		if rows == 0 && len(files) == 0 {
+			return sql.ErrNoRows
+		}
+
+		return nil
+	})
+
+	return removed, err
+}
+
+// makeFilePath creates the directories of the file path and makes sure no
+// directory takes the file name.
+func makeFilePath(ctx context.Context, q *Queries, accountID int64, dir string, name string) error {
+	err := makeDirectories(ctx, q, accountID, dir)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.GetDirectory(ctx, GetDirectoryParams{
+		AccountID: accountID,
+		Path:      path.Join(dir, name),
+	})
+	if err == nil {
+		return ErrPathConflict
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	return nil
+}
+
+// makeDirectories creates the directory and its parents, failing with
+// ErrPathConflict if a file takes the name of any of them.
+func makeDirectories(ctx context.Context, q *Queries, accountID int64, dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	names := strings.Split(dir, "/")
+	for i := range names {
+		parent := strings.Join(names[:i], "/")
+
+		_, err := q.GetFile(ctx, GetFileParams{
+			Filename:  names[i],
+			AccountID: accountID,
+			Filepath:  parent,
+		})
+		if err == nil {
+			return ErrPathConflict
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		err = q.CreateDirectory(ctx, CreateDirectoryParams{
+			AccountID: accountID,
+			Path:      path.Join(parent, names[i]),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
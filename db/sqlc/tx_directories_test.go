@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectoriesTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	ctx := context.Background()
+
+	err := store.MakeDirectoryTx(ctx, MakeDirectoryTxParams{AccountID: account.ID, Path: "a/b/c"})
+	require.NoError(t, err)
+
+	dirs, err := testQueries.ListDirectories(ctx, account.ID)
+	require.NoError(t, err)
+	require.Len(t, dirs, 3)
+	require.Equal(t, "a", dirs[0].Path)
+	require.Equal(t, "a/b", dirs[1].Path)
+	require.Equal(t, "a/b/c", dirs[2].Path)
+
+	file, err := store.CreateFileTx(ctx, CreateFileTxParams{
+		CreateFileParams: CreateFileParams{
+			AccountID: account.ID,
+			Filename:  util.RandomString(8),
+			Filepath:  "a/d",
+		},
+	})
+	require.NoError(t, err)
+
+	// A file and a directory never share a path.
+	_, err = store.CreateFileTx(ctx, CreateFileTxParams{
+		CreateFileParams: CreateFileParams{
+			AccountID: account.ID,
+			Filename:  "b",
+			Filepath:  "a",
+		},
+	})
+	require.ErrorIs(t, err, ErrPathConflict)
+
+	err = store.MakeDirectoryTx(ctx, MakeDirectoryTxParams{AccountID: account.ID, Path: "a/d/" + file.Filename})
+	require.ErrorIs(t, err, ErrPathConflict)
+
+	err = store.MoveFileTx(ctx, MoveFileTxParams{UpdateFilePathParams{
+		NewFilepath: "e/f",
+		NewFilename: "moved",
+		Filename:    file.Filename,
+		AccountID:   account.ID,
+		Filepath:    file.Filepath,
+	}})
+	require.NoError(t, err)
+
+	moved, err := testQueries.GetFile(ctx, GetFileParams{Filename: "moved", AccountID: account.ID, Filepath: "e/f"})
+	require.NoError(t, err)
+	require.Equal(t, file.ID, moved.ID)
+
+	_, err = testQueries.GetDirectory(ctx, GetDirectoryParams{AccountID: account.ID, Path: "e/f"})
+	require.NoError(t, err)
+
+	_, err = store.RemoveDirectoryTx(ctx, RemoveDirectoryTxParams{AccountID: account.ID, Path: "e"})
+	require.ErrorIs(t, err, ErrDirectoryNotEmpty)
+
+	removed, err := store.RemoveDirectoryTx(ctx, RemoveDirectoryTxParams{
+		AccountID:    account.ID,
+		Path:         "e",
+		Recursive:    true,
+		AfterRelease: func(q Querier, blob Blob) error { return nil },
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), removed)
+
+	_, err = testQueries.GetFile(ctx, GetFileParams{Filename: "moved", AccountID: account.ID, Filepath: "e/f"})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.RemoveDirectoryTx(ctx, RemoveDirectoryTxParams{AccountID: account.ID, Path: "e"})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Another account has a namespace of its own.
+	other := createRandomAccount(t)
+	err = store.MakeDirectoryTx(ctx, MakeDirectoryTxParams{AccountID: other.ID, Path: "a/b/c"})
+	require.NoError(t, err)
+
+	_, err = store.RemoveDirectoryTx(ctx, RemoveDirectoryTxParams{AccountID: other.ID, Path: "a", Recursive: true})
+	require.NoError(t, err)
+
+	dirs, err = testQueries.ListDirectories(ctx, account.ID)
+	require.NoError(t, err)
+	require.Len(t, dirs, 4)
+}
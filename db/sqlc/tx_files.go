@@ -0,0 +1,377 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+)
+
+// CreateFileTxParams contains the input parameters of the CreateFileTx
+type CreateFileTxParams struct {
+	CreateFileParams
+	// MaxFiles limits the number of files of the account, zero means unlimited.
+	MaxFiles int64
+	// Metadata are the user metadata entries of the file.
+	Metadata map[string]string
+	// MaxMetadata limits the number of user metadata entries, zero means unlimited.
+	MaxMetadata int64
+}
+
+// CreateFileTx creates a file row unless the account already has MaxFiles files.
+// The directories on the file path and the user metadata are created as well.
+func (store *SQLStore) CreateFileTx(ctx context.Context, arg CreateFileTxParams) (File, error) {
+	var file File
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// The account row serializes quota checks of parallel requests.
+		_, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if arg.MaxFiles > 0 {
+			usage, err := q.GetFilesUsage(ctx, arg.AccountID)
+			if err != nil {
+				return err
+			}
+			if usage.FileCount >= arg.MaxFiles {
+				return ErrQuotaExceeded
+			}
+		}
+
+		err = makeFilePath(ctx, q, arg.AccountID, arg.Filepath, arg.Filename)
+		if err != nil {
+			return err
+		}
+
+		file, err = q.CreateFile(ctx, arg.CreateFileParams)
+		if err != nil {
+			return err
+		}
+
+		return writeFileMetadata(ctx, q, file.ID, false, arg.Metadata, arg.MaxMetadata)
+	})
+
+	return file, err
+}
+
+// AttachFileBlobTxParams contains the input parameters of the AttachFileBlobTx
+type AttachFileBlobTxParams struct {
+	AccountID int64
+	FileID    int64
+	Hash      string
+	Size      int64
+	// MaxBytes limits the total size of the account files, zero means unlimited.
+	MaxBytes int64
+	// SystemMetadata describes the new content, it replaces the entries
+	// recorded for the old one.
+	SystemMetadata map[string]string
+	// Metadata are user metadata entries added to the file or replaced.
+	Metadata map[string]string
+	// MaxMetadata limits the number of user metadata entries, zero means unlimited.
+	MaxMetadata int64
+	// NewBlob is called while the blob lock is held when no blob has the
+	// hash yet. It returns the compression codec and the wrapped encryption
+	// key of the content, a nil key stores the content in plaintext.
+	NewBlob func() (codec string, wrappedKey []byte, err error)
+	// AfterCreate is called while the blob lock is held once the blob is
+	// created, so the content can be put to the storage before any other
+	// request sees the blob. The content of a blob referenced before is
+	// stored already, nil leaves it at that. Storage kept in the database
+	// writes through q to join the transaction.
+	AfterCreate func(q Querier, blob Blob) error
+}
+
+// AttachFileBlobTx points the file to the blob with the given hash, creating the
+// blob or taking one more reference to it, and marks the file as ready. The blob
+// the file pointed to before is kept as a version of the file.
+func (store *SQLStore) AttachFileBlobTx(ctx context.Context, arg AttachFileBlobTxParams) (Blob, error) {
+	var blob Blob
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// Locks are always taken in the account, file, blob order, the same
+		// order DeleteFileTx uses, so the transactions never deadlock.
+		_, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		file, err := q.GetFileForUpdate(ctx, arg.FileID)
+		if err != nil {
+			return err
+		}
+
+		// The old blob keeps its reference as a file version, so only the
+		// new one is locked.
+		err = q.LockBlob(ctx, arg.Hash)
+		if err != nil {
+			return err
+		}
+
+		if arg.MaxBytes > 0 {
+			err = checkBytesQuota(ctx, q, arg.AccountID, arg.Size, arg.MaxBytes)
+			if err != nil {
+				return err
+			}
+		}
+
+		blob, err = acquireBlob(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+
+		rows, err := q.SetFileBlob(ctx, SetFileBlobParams{
+			ID:       arg.FileID,
+			BlobHash: sql.NullString{String: arg.Hash, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return sql.ErrNoRows
+		}
+
+		err = writeFileMetadata(ctx, q, arg.FileID, true, arg.SystemMetadata, 0)
+		if err != nil {
+			return err
+		}
+
+		err = writeFileMetadata(ctx, q, arg.FileID, false, arg.Metadata, arg.MaxMetadata)
+		if err != nil {
+			return err
+		}
+
+		if !file.BlobHash.Valid {
+			return nil
+		}
+
+		// The version keeps the reference the file had to the old blob.
+		_, err = q.CreateFileVersion(ctx, CreateFileVersionParams{
+			FileID:   file.ID,
+			Version:  file.Version,
+			BlobHash: file.BlobHash.String,
+		})
+		return err
+	})
+
+	return blob, err
+}
+
+// acquireBlob takes one more reference to the blob with the hash, or creates
+// it with the codec and the key NewBlob returns. The caller holds the blob
+// lock, so the blob cannot appear or go in between.
+func acquireBlob(ctx context.Context, q *Queries, arg AttachFileBlobTxParams) (Blob, error) {
+	blob, err := q.ReferenceBlob(ctx, arg.Hash)
+	if err != sql.ErrNoRows {
+		return blob, err
+	}
+
+	codec, wrappedKey, err := arg.NewBlob()
+	if err != nil {
+		return blob, err
+	}
+
+	blob, err = q.CreateBlob(ctx, CreateBlobParams{
+		Hash:       arg.Hash,
+		Size:       arg.Size,
+		Codec:      codec,
+		WrappedKey: wrappedKey,
+	})
+	if err != nil {
+		return blob, err
+	}
+
+	if arg.AfterCreate == nil {
+		return blob, nil
+	}
+	return blob, arg.AfterCreate(q, blob)
+}
+
+// checkBytesQuota fails with ErrQuotaExceeded if adding size bytes takes the
+// account over maxBytes. Replaced content stays as a file version until the
+// retention drops it, so it still counts.
+func checkBytesQuota(ctx context.Context, q *Queries, accountID int64, size int64, maxBytes int64) error {
+	usage, err := q.GetFilesUsage(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	if usage.TotalBytes+size > maxBytes {
+		return ErrQuotaExceeded
+	}
+
+	return nil
+}
+
+// DeleteFileTxParams contains the input parameters of the DeleteFileTx
+type DeleteFileTxParams struct {
+	DeleteFileParams
+	// AfterRelease is called in the transaction while the blob lock is held
+	// once the last reference to the blob is gone. Only content kept in the
+	// database may be removed there, the transaction can still roll back.
+	AfterRelease func(q Querier, blob Blob) error
+}
+
+// DeleteFileTx deletes the file with all its versions and drops their
+// references to the blobs.
+func (store *SQLStore) DeleteFileTx(ctx context.Context, arg DeleteFileTxParams) (File, error) {
+	var file File
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		_, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		var hashes []string
+		file, hashes, err = removeFile(ctx, q, arg.DeleteFileParams)
+		if err != nil {
+			return err
+		}
+
+		return dropBlobReferences(ctx, q, hashes, arg.AfterRelease)
+	})
+
+	return file, err
+}
+
+// removeFile deletes the file with its versions in the transaction q, the
+// caller holds the account lock. It returns the hashes of the blobs the file
+// referred to, the caller drops the references with dropBlobReferences.
+func removeFile(ctx context.Context, q *Queries, arg DeleteFileParams) (File, []string, error) {
+	file, err := q.GetFile(ctx, GetFileParams(arg))
+	if err != nil {
+		return file, nil, err
+	}
+
+	versions, err := q.DeleteFileVersions(ctx, file.ID)
+	if err != nil {
+		return file, nil, err
+	}
+
+	file, err = q.DeleteFile(ctx, arg)
+	if err != nil {
+		return file, nil, err
+	}
+
+	var hashes []string
+	for _, version := range versions {
+		hashes = append(hashes, version.BlobHash)
+	}
+	if file.BlobHash.Valid {
+		hashes = append(hashes, file.BlobHash.String)
+	}
+
+	return file, hashes, nil
+}
+
+// dropBlobReferences drops a reference to each of the blobs. Blobs are locked
+// in the hash order and no blob lock is released before the commit, so
+// transactions dropping references to the same blobs never deadlock, as long
+// as each of them passes all its hashes in one call.
+func dropBlobReferences(ctx context.Context, q *Queries, hashes []string, afterRelease func(q Querier, blob Blob) error) error {
+	sort.Strings(hashes)
+	for _, hash := range hashes {
+		err := dropBlobReference(ctx, q, hash, afterRelease)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteFileVersionTxParams contains the input parameters of the DeleteFileVersionTx
+type DeleteFileVersionTxParams struct {
+	AccountID int64
+	ID        int64
+	// AfterRelease is called in the transaction while the blob lock is held
+	// once the last reference to the blob is gone. Only content kept in the
+	// database may be removed there, the transaction can still roll back.
+	AfterRelease func(q Querier, blob Blob) error
+}
+
+// DeleteFileVersionTx deletes the file version and drops its reference to the blob.
+func (store *SQLStore) DeleteFileVersionTx(ctx context.Context, arg DeleteFileVersionTxParams) (FileVersion, error) {
+	var version FileVersion
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		_, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		version, err = q.DeleteFileVersion(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		return dropBlobReference(ctx, q, version.BlobHash, arg.AfterRelease)
+	})
+
+	return version, err
+}
+
+func dropBlobReference(ctx context.Context, q *Queries, hash string, afterRelease func(q Querier, blob Blob) error) error {
+	err := q.LockBlob(ctx, hash)
+	if err != nil {
+		return err
+	}
+
+	blob, err := q.ReleaseBlob(ctx, hash)
+	if err != nil {
+		return err
+	}
+
+	if blob.Refcount > 0 {
+		return nil
+	}
+
+	err = q.DeleteBlob(ctx, hash)
+	if err != nil {
+		return err
+	}
+
+	return afterRelease(q, blob)
+}
+
+// DeleteOrphanContentTxParams contains the input parameters of the DeleteOrphanContentTx
+type DeleteOrphanContentTxParams struct {
+	Hash string
+	// DeleteContent is called while the blob lock is held once it is known
+	// no blob row refers to the content.
+	DeleteContent func(q Querier) error
+}
+
+// DeleteOrphanContentTx removes stored content which has no blob row. The blob
+// lock waits for an upload of the same content to commit or roll back, so
+// content just being attached is never taken for an orphan. It reports
+// whether the content was removed.
+func (store *SQLStore) DeleteOrphanContentTx(ctx context.Context, arg DeleteOrphanContentTxParams) (bool, error) {
+	deleted := false
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		err := q.LockBlob(ctx, arg.Hash)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.GetBlob(ctx, arg.Hash)
+		if err == nil {
+			return nil
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		err = arg.DeleteContent(q)
+		if err != nil {
+			return err
+		}
+
+		deleted = true
+		return nil
+	})
+
+	return deleted, err
+}
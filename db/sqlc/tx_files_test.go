@@ -0,0 +1,232 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomFile(t *testing.T, account Account) File {
+	arg := CreateFileParams{
+		AccountID: account.ID,
+		Filename:  util.RandomString(8),
+		Filepath:  util.RandomString(4),
+	}
+
+	file, err := testQueries.CreateFile(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Filename, file.Filename)
+	require.False(t, file.Ready)
+
+	return file
+}
+
+// newTestBlob stores the content of new test blobs in plaintext.
+func newTestBlob() (string, []byte, error) {
+	return "identity", nil, nil
+}
+
+func TestBlobRefcountTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	hash := util.RandomString(64)
+
+	n := 5
+	files := make([]File, n)
+	for i := range files {
+		files[i] = createRandomFile(t, account)
+	}
+
+	// Only the first attach creates the blob.
+	var created int32
+	errs := make(chan error)
+	for _, file := range files {
+		go func(file File) {
+			_, err := store.AttachFileBlobTx(context.Background(), AttachFileBlobTxParams{
+				AccountID: file.AccountID,
+				FileID:    file.ID,
+				Hash:      hash,
+				Size:      10,
+				NewBlob:   newTestBlob,
+				AfterCreate: func(q Querier, blob Blob) error {
+					atomic.AddInt32(&created, 1)
+					return nil
+				},
+			})
+			errs <- err
+		}(file)
+	}
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	blob, err := testQueries.GetBlob(context.Background(), hash)
+	require.NoError(t, err)
+	require.Equal(t, int64(n), blob.Refcount)
+	require.Equal(t, int32(1), atomic.LoadInt32(&created))
+
+	released := make(chan string, n)
+	for _, file := range files {
+		go func(file File) {
+			_, err := store.DeleteFileTx(context.Background(), DeleteFileTxParams{
+				DeleteFileParams: DeleteFileParams{
+					Filename:  file.Filename,
+					AccountID: file.AccountID,
+					Filepath:  file.Filepath,
+				},
+				AfterRelease: func(q Querier, blob Blob) error {
+					released <- blob.Hash
+					return nil
+				},
+			})
+			errs <- err
+		}(file)
+	}
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	require.Len(t, released, 1)
+	require.Equal(t, hash, <-released)
+
+	_, err = testQueries.GetBlob(context.Background(), hash)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestFileVersionsTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	file := createRandomFile(t, account)
+
+	hashes := []string{util.RandomString(64), util.RandomString(64), util.RandomString(64)}
+	for _, hash := range hashes {
+		_, err := store.AttachFileBlobTx(context.Background(), AttachFileBlobTxParams{
+			AccountID: account.ID,
+			FileID:    file.ID,
+			Hash:      hash,
+			Size:      10,
+			NewBlob:   newTestBlob,
+		})
+		require.NoError(t, err)
+	}
+
+	current, err := testQueries.GetFileForUpdate(context.Background(), file.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(hashes)), current.Version)
+	require.Equal(t, hashes[2], current.BlobHash.String)
+
+	versions, err := testQueries.ListFileVersions(context.Background(), file.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.Equal(t, int64(2), versions[0].Version)
+	require.Equal(t, hashes[1], versions[0].BlobHash)
+	require.Equal(t, int64(1), versions[1].Version)
+	require.Equal(t, hashes[0], versions[1].BlobHash)
+
+	usage, err := testQueries.GetFilesUsage(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(30), usage.TotalBytes)
+
+	released := make(map[string]bool)
+	afterRelease := func(q Querier, blob Blob) error {
+		released[blob.Hash] = true
+		return nil
+	}
+
+	_, err = store.DeleteFileVersionTx(context.Background(), DeleteFileVersionTxParams{
+		AccountID:    account.ID,
+		ID:           versions[1].ID,
+		AfterRelease: afterRelease,
+	})
+	require.NoError(t, err)
+	require.True(t, released[hashes[0]])
+
+	_, err = store.DeleteFileTx(context.Background(), DeleteFileTxParams{
+		DeleteFileParams: DeleteFileParams{
+			Filename:  file.Filename,
+			AccountID: account.ID,
+			Filepath:  file.Filepath,
+		},
+		AfterRelease: afterRelease,
+	})
+	require.NoError(t, err)
+	require.Len(t, released, 3)
+
+	versions, err = testQueries.ListFileVersions(context.Background(), file.ID)
+	require.NoError(t, err)
+	require.Empty(t, versions)
+}
+
+func TestFilesQuotaTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	n := 5
+	maxFiles := int64(3)
+
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.CreateFileTx(context.Background(), CreateFileTxParams{
+				CreateFileParams: CreateFileParams{
+					AccountID: account.ID,
+					Filename:  util.RandomString(8),
+					Filepath:  util.RandomString(4),
+				},
+				MaxFiles: maxFiles,
+			})
+			errs <- err
+		}()
+	}
+
+	exceeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == ErrQuotaExceeded {
+			exceeded++
+			continue
+		}
+		require.NoError(t, err)
+	}
+	require.Equal(t, n-int(maxFiles), exceeded)
+
+	usage, err := testQueries.GetFilesUsage(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, maxFiles, usage.FileCount)
+}
+
+func TestDeleteOrphanContentTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	file := createRandomFile(t, account)
+	hash := util.RandomString(64)
+
+	_, err := store.AttachFileBlobTx(context.Background(), AttachFileBlobTxParams{
+		AccountID: account.ID,
+		FileID:    file.ID,
+		Hash:      hash,
+		Size:      10,
+		NewBlob:   newTestBlob,
+	})
+	require.NoError(t, err)
+
+	deleteContent := func(q Querier) error { return nil }
+
+	deleted, err := store.DeleteOrphanContentTx(context.Background(), DeleteOrphanContentTxParams{
+		Hash:          hash,
+		DeleteContent: deleteContent,
+	})
+	require.NoError(t, err)
+	require.False(t, deleted)
+
+	deleted, err = store.DeleteOrphanContentTx(context.Background(), DeleteOrphanContentTxParams{
+		Hash:          util.RandomString(64),
+		DeleteContent: deleteContent,
+	})
+	require.NoError(t, err)
+	require.True(t, deleted)
+}
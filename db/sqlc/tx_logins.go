@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// LoginKey is a key the login attempts are counted by.
+type LoginKey struct {
+	Scope string
+	Key   string
+}
+
+// ReserveLoginAttemptTxParams contains the input parameters of the ReserveLoginAttemptTx
+type ReserveLoginAttemptTxParams struct {
+	Keys        []LoginKey
+	Now         time.Time
+	WindowStart time.Time
+	// LockedUntil returns how long the i-th key is locked after its
+	// failures-th failure.
+	LockedUntil func(i int, failures int32) time.Time
+}
+
+// ReserveLoginAttemptTxResult is the result of the ReserveLoginAttemptTx
+type ReserveLoginAttemptTxResult struct {
+	// Previous are the rows of the keys before the attempt, Reserved the rows
+	// counting it. Both are in the order of the keys.
+	Previous []LoginFailure
+	Reserved []LoginFailure
+	// LockedUntil is the end of the latest lock of the keys when the attempt
+	// is refused with ErrLoginLocked.
+	LockedUntil time.Time
+}
+
+// ReserveLoginAttemptTx counts the attempt as failed and locks its keys for
+// the backoff before the password is checked, the caller takes the attempt
+// back when it succeeds. The rows of the keys stay locked until the commit, so
+// parallel attempts take turns and only see each other counted. While a key
+// is locked nothing is counted and ErrLoginLocked is returned.
+func (store *SQLStore) ReserveLoginAttemptTx(ctx context.Context, arg ReserveLoginAttemptTxParams) (ReserveLoginAttemptTxResult, error) {
+	var result ReserveLoginAttemptTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// The keys are locked in the given order, the scope of the password
+		// first and the peer address last, so attempts never deadlock.
+		for _, key := range arg.Keys {
+			row, err := q.GetLoginFailureForUpdate(ctx, GetLoginFailureForUpdateParams{
+				Scope: key.Scope,
+				Key:   key.Key,
+				Now:   arg.Now,
+			})
+			if err != nil {
+				return err
+			}
+
+			result.Previous = append(result.Previous, row)
+			if row.LockedUntil.After(arg.Now) && row.LockedUntil.After(result.LockedUntil) {
+				result.LockedUntil = row.LockedUntil
+			}
+		}
+		if !result.LockedUntil.IsZero() {
+			return ErrLoginLocked
+		}
+
+		for i, key := range arg.Keys {
+			row, err := q.RecordLoginFailure(ctx, RecordLoginFailureParams{
+				Scope:       key.Scope,
+				Key:         key.Key,
+				FailedAt:    arg.Now,
+				WindowStart: arg.WindowStart,
+			})
+			if err != nil {
+				return err
+			}
+
+			row, err = q.LockLogin(ctx, LockLoginParams{
+				LockedUntil: arg.LockedUntil(i, row.Failures),
+				Scope:       key.Scope,
+				Key:         key.Key,
+			})
+			if err != nil {
+				return err
+			}
+			result.Reserved = append(result.Reserved, row)
+		}
+
+		return nil
+	})
+	if err == ErrLoginLocked {
+		return ReserveLoginAttemptTxResult{LockedUntil: result.LockedUntil}, err
+	}
+	if err != nil {
+		return ReserveLoginAttemptTxResult{}, err
+	}
+
+	return result, nil
+}
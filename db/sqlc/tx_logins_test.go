@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserveLoginAttemptTx(t *testing.T) {
+	store := NewStore(testDB)
+	keys := []LoginKey{{Scope: "username", Key: util.RandomUser()}}
+	now := time.Now()
+
+	reserve := func() (ReserveLoginAttemptTxResult, error) {
+		return store.ReserveLoginAttemptTx(context.Background(), ReserveLoginAttemptTxParams{
+			Keys:        keys,
+			Now:         now,
+			WindowStart: now.Add(-time.Hour),
+			LockedUntil: func(i int, failures int32) time.Time {
+				return now.Add(time.Minute)
+			},
+		})
+	}
+
+	// Of parallel attempts only the first one gets through the lock it sets.
+	n := 5
+	errs := make(chan error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := reserve()
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	reserved := 0
+	for err := range errs {
+		if err == nil {
+			reserved++
+			continue
+		}
+		require.ErrorIs(t, err, ErrLoginLocked)
+	}
+	require.Equal(t, 1, reserved)
+
+	result, err := reserve()
+	require.ErrorIs(t, err, ErrLoginLocked)
+	require.WithinDuration(t, now.Add(time.Minute), result.LockedUntil, time.Second)
+
+	// Taking the attempt back restores the lock it replaced.
+	rows, err := store.ListLoginFailures(context.Background(), ListLoginFailuresParams{Scope: keys[0].Scope, Key: keys[0].Key})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, int32(1), rows[0].Failures)
+
+	err = store.UndoLoginFailure(context.Background(), UndoLoginFailureParams{
+		ReservedUntil: rows[0].LockedUntil,
+		LockedUntil:   now,
+		Scope:         keys[0].Scope,
+		Key:           keys[0].Key,
+	})
+	require.NoError(t, err)
+
+	result, err = reserve()
+	require.NoError(t, err)
+	require.Equal(t, int32(0), result.Previous[0].Failures)
+	require.Equal(t, int32(1), result.Reserved[0].Failures)
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"sort"
+)
+
+// UpdateFileMetadataTxParams contains the input parameters of the UpdateFileMetadataTx
+type UpdateFileMetadataTxParams struct {
+	FileID int64
+	// Set adds the user entries or replaces their values.
+	Set map[string]string
+	// Remove deletes the user entries, missing ones are ignored.
+	Remove []string
+	// MaxEntries limits the number of user entries of the file, zero means unlimited.
+	MaxEntries int64
+}
+
+// UpdateFileMetadataTx edits the user metadata of the file and returns all its
+// metadata. Entries recorded by the server are never touched.
+func (store *SQLStore) UpdateFileMetadataTx(ctx context.Context, arg UpdateFileMetadataTxParams) ([]FilesMetadatum, error) {
+	var metadata []FilesMetadatum
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// The file row serializes the entry limit checks of parallel requests.
+		_, err := q.GetFileForUpdate(ctx, arg.FileID)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range arg.Remove {
+			_, err = q.DeleteFileMetadata(ctx, DeleteFileMetadataParams{
+				Key:    key,
+				FileID: arg.FileID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = writeFileMetadata(ctx, q, arg.FileID, false, arg.Set, arg.MaxEntries)
+		if err != nil {
+			return err
+		}
+
+		metadata, err = q.ListFileMetadata(ctx, arg.FileID)
+		return err
+	})
+
+	return metadata, err
+}
+
+// writeFileMetadata writes the entries in the key order and fails with
+// ErrMetadataLimit once the file has more than maxEntries user entries.
+func writeFileMetadata(ctx context.Context, q *Queries, fileID int64, system bool, entries map[string]string, maxEntries int64) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		err := q.SetFileMetadata(ctx, SetFileMetadataParams{
+			FileID: fileID,
+			System: system,
+			Key:    key,
+			Value:  entries[key],
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if system || maxEntries <= 0 {
+		return nil
+	}
+
+	count, err := q.CountUserFileMetadata(ctx, fileID)
+	if err != nil {
+		return err
+	}
+	if count > maxEntries {
+		return ErrMetadataLimit
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateFileMetadataTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	file := createRandomFile(t, account)
+
+	err := testQueries.SetFileMetadata(context.Background(), SetFileMetadataParams{
+		FileID: file.ID,
+		System: true,
+		Key:    "content_type",
+		Value:  "text/plain",
+	})
+	require.NoError(t, err)
+
+	metadata, err := store.UpdateFileMetadataTx(context.Background(), UpdateFileMetadataTxParams{
+		FileID:     file.ID,
+		Set:        map[string]string{"author": util.RandomString(6), "content_type": "mine"},
+		MaxEntries: 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, metadata, 3)
+
+	// The recorded entry is not removed by a user entry of the same key.
+	metadata, err = store.UpdateFileMetadataTx(context.Background(), UpdateFileMetadataTxParams{
+		FileID: file.ID,
+		Remove: []string{"content_type", "missing"},
+	})
+	require.NoError(t, err)
+	require.Len(t, metadata, 2)
+	for _, entry := range metadata {
+		if entry.Key == "content_type" {
+			require.True(t, entry.System)
+			require.Equal(t, "text/plain", entry.Value)
+		}
+	}
+
+	_, err = store.UpdateFileMetadataTx(context.Background(), UpdateFileMetadataTxParams{
+		FileID:     file.ID,
+		Set:        map[string]string{"a": "1", "b": "2"},
+		MaxEntries: 2,
+	})
+	require.ErrorIs(t, err, ErrMetadataLimit)
+
+	count, err := testQueries.CountUserFileMetadata(context.Background(), file.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
@@ -0,0 +1,38 @@
+package db
+
+import "context"
+
+// CreateSecretTxParams contains the input parameters of the CreateSecretTx
+type CreateSecretTxParams struct {
+	CreateSecretParams
+	// MaxSecrets limits the number of secrets of the account, zero means unlimited.
+	MaxSecrets int64
+}
+
+// CreateSecretTx creates a secret unless the account already has MaxSecrets secrets.
+func (store *SQLStore) CreateSecretTx(ctx context.Context, arg CreateSecretTxParams) (Secret, error) {
+	var secret Secret
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// The account row serializes quota checks of parallel requests.
+		_, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if arg.MaxSecrets > 0 {
+			count, err := q.CountSecrets(ctx, arg.AccountID)
+			if err != nil {
+				return err
+			}
+			if count >= arg.MaxSecrets {
+				return ErrQuotaExceeded
+			}
+		}
+
+		secret, err = q.CreateSecret(ctx, arg.CreateSecretParams)
+		return err
+	})
+
+	return secret, err
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// RotateRefreshTokenTxParams contains the input parameters of the RotateRefreshTokenTx
+type RotateRefreshTokenTxParams struct {
+	TokenHash    string
+	NewTokenHash string
+	// ExpiresAt is the expiry of the new token.
+	ExpiresAt time.Time
+	Now       time.Time
+}
+
+// RotateRefreshTokenTxResult is the result of the RotateRefreshTokenTx
+type RotateRefreshTokenTxResult struct {
+	Account Account
+	Token   RefreshToken
+}
+
+// RotateRefreshTokenTx exchanges the refresh token for a new one of the same
+// family. A token presented a second time means it was stolen, so the whole
+// family is revoked and ErrRefreshTokenReused is returned. Nothing changes for
+// a blocked account, ErrAccountBlocked is returned.
+func (store *SQLStore) RotateRefreshTokenTx(ctx context.Context, arg RotateRefreshTokenTxParams) (RotateRefreshTokenTxResult, error) {
+	var result RotateRefreshTokenTxResult
+	// rejected is returned after the commit of the family revocation.
+	var rejected error
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// The account is locked first like in RevokeAllSessionsTx, so a
+		// rotation cannot slip a live token past the revocation of all
+		// sessions.
+		account, err := q.GetRefreshTokenAccountForUpdate(ctx, arg.TokenHash)
+		if err != nil {
+			return err
+		}
+
+		// The row lock makes parallel uses of one token take turns, only the
+		// first of them gets a new token.
+		token, err := q.GetRefreshTokenForUpdate(ctx, arg.TokenHash)
+		if err != nil {
+			return err
+		}
+
+		if token.Revoked {
+			rejected = ErrRefreshTokenRevoked
+			return nil
+		}
+		if token.Used {
+			rejected = ErrRefreshTokenReused
+			return q.RevokeRefreshTokenFamily(ctx, token.FamilyID)
+		}
+		if !arg.Now.Before(token.ExpiresAt) {
+			return ErrRefreshTokenExpired
+		}
+		// The account row is locked, a block cannot land between this
+		// check and the commit.
+		if account.Blocked {
+			return ErrAccountBlocked
+		}
+
+		result.Account = account
+
+		err = q.MarkRefreshTokenUsed(ctx, token.ID)
+		if err != nil {
+			return err
+		}
+
+		result.Token, err = q.CreateRefreshToken(ctx, CreateRefreshTokenParams{
+			AccountID: token.AccountID,
+			FamilyID:  token.FamilyID,
+			TokenHash: arg.NewTokenHash,
+			ExpiresAt: arg.ExpiresAt,
+		})
+		return err
+	})
+	if err != nil {
+		return RotateRefreshTokenTxResult{}, err
+	}
+	if rejected != nil {
+		return RotateRefreshTokenTxResult{}, rejected
+	}
+
+	return result, nil
+}
+
+// RevokeAllSessionsTxParams contains the input parameters of the RevokeAllSessionsTx
+type RevokeAllSessionsTxParams struct {
+	AccountID int64
+}
+
+// RevokeAllSessionsTx revokes every refresh token of the account and bumps
+// its session epoch, so the access tokens issued so far stop working too. The
+// new epoch is returned.
+func (store *SQLStore) RevokeAllSessionsTx(ctx context.Context, arg RevokeAllSessionsTxParams) (int64, error) {
+	var epoch int64
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		// Bumping first locks the account row, a parallel refresh of the
+		// account waits for the revocation.
+		epoch, err = q.BumpAccountSessionEpoch(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		return q.RevokeAccountRefreshTokens(ctx, arg.AccountID)
+	})
+
+	return epoch, err
+}
+
+// ChangePasswordTxParams contains the input parameters of the ChangePasswordTx
+type ChangePasswordTxParams struct {
+	AccountID int64
+	Passhash  string
+}
+
+// ChangePasswordTx stores the new password hash of the account and revokes
+// all its sessions like RevokeAllSessionsTx. The updated account is returned.
+func (store *SQLStore) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.ChangeAccountPassword(ctx, ChangeAccountPasswordParams{
+			ID:       arg.AccountID,
+			Passhash: arg.Passhash,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.RevokeAccountRefreshTokens(ctx, arg.AccountID)
+	})
+
+	return account, err
+}
@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateRefreshTokenTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	now := time.Now()
+
+	first, err := testQueries.CreateRefreshToken(context.Background(), CreateRefreshTokenParams{
+		AccountID: account.ID,
+		FamilyID:  util.RandomString(16),
+		TokenHash: util.RandomString(64),
+		ExpiresAt: now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	result, err := store.RotateRefreshTokenTx(context.Background(), RotateRefreshTokenTxParams{
+		TokenHash:    first.TokenHash,
+		NewTokenHash: util.RandomString(64),
+		ExpiresAt:    now.Add(time.Hour),
+		Now:          now,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Username, result.Account.Username)
+	require.Equal(t, first.FamilyID, result.Token.FamilyID)
+	second := result.Token
+
+	// The old token shows up again, the whole family goes.
+	_, err = store.RotateRefreshTokenTx(context.Background(), RotateRefreshTokenTxParams{
+		TokenHash:    first.TokenHash,
+		NewTokenHash: util.RandomString(64),
+		ExpiresAt:    now.Add(time.Hour),
+		Now:          now,
+	})
+	require.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	_, err = store.RotateRefreshTokenTx(context.Background(), RotateRefreshTokenTxParams{
+		TokenHash:    second.TokenHash,
+		NewTokenHash: util.RandomString(64),
+		ExpiresAt:    now.Add(time.Hour),
+		Now:          now,
+	})
+	require.ErrorIs(t, err, ErrRefreshTokenRevoked)
+
+	expired, err := testQueries.CreateRefreshToken(context.Background(), CreateRefreshTokenParams{
+		AccountID: account.ID,
+		FamilyID:  util.RandomString(16),
+		TokenHash: util.RandomString(64),
+		ExpiresAt: now.Add(-time.Second),
+	})
+	require.NoError(t, err)
+
+	_, err = store.RotateRefreshTokenTx(context.Background(), RotateRefreshTokenTxParams{
+		TokenHash:    expired.TokenHash,
+		NewTokenHash: util.RandomString(64),
+		ExpiresAt:    now.Add(time.Hour),
+		Now:          now,
+	})
+	require.ErrorIs(t, err, ErrRefreshTokenExpired)
+}
+
+func TestRevokeAllSessionsTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	require.Zero(t, account.SessionEpoch)
+
+	token, err := testQueries.CreateRefreshToken(context.Background(), CreateRefreshTokenParams{
+		AccountID: account.ID,
+		FamilyID:  util.RandomString(16),
+		TokenHash: util.RandomString(64),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	epoch, err := store.RevokeAllSessionsTx(context.Background(), RevokeAllSessionsTxParams{AccountID: account.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), epoch)
+
+	updated, err := testQueries.GetAccount(context.Background(), account.Username)
+	require.NoError(t, err)
+	require.Equal(t, epoch, updated.SessionEpoch)
+
+	_, err = store.RotateRefreshTokenTx(context.Background(), RotateRefreshTokenTxParams{
+		TokenHash:    token.TokenHash,
+		NewTokenHash: util.RandomString(64),
+		ExpiresAt:    time.Now().Add(time.Hour),
+		Now:          time.Now(),
+	})
+	require.ErrorIs(t, err, ErrRefreshTokenRevoked)
+}
+
+func TestChangePasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	token, err := testQueries.CreateRefreshToken(context.Background(), CreateRefreshTokenParams{
+		AccountID: account.ID,
+		FamilyID:  util.RandomString(16),
+		TokenHash: util.RandomString(64),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	hash, err := util.HashPassword(util.RandomPass())
+	require.NoError(t, err)
+	updated, err := store.ChangePasswordTx(context.Background(), ChangePasswordTxParams{
+		AccountID: account.ID,
+		Passhash:  hash,
+	})
+	require.NoError(t, err)
+	require.Equal(t, hash, updated.Passhash)
+	require.Equal(t, account.SessionEpoch+1, updated.SessionEpoch)
+
+	_, err = store.RotateRefreshTokenTx(context.Background(), RotateRefreshTokenTxParams{
+		TokenHash:    token.TokenHash,
+		NewTokenHash: util.RandomString(64),
+		ExpiresAt:    time.Now().Add(time.Hour),
+		Now:          time.Now(),
+	})
+	require.ErrorIs(t, err, ErrRefreshTokenRevoked)
+}
+
+func TestRotateRefreshTokenTxBlocked(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	token, err := testQueries.CreateRefreshToken(context.Background(), CreateRefreshTokenParams{
+		AccountID: account.ID,
+		FamilyID:  util.RandomString(16),
+		TokenHash: util.RandomString(64),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	rotate := func() error {
+		_, err := store.RotateRefreshTokenTx(context.Background(), RotateRefreshTokenTxParams{
+			TokenHash:    token.TokenHash,
+			NewTokenHash: util.RandomString(64),
+			ExpiresAt:    time.Now().Add(time.Hour),
+			Now:          time.Now(),
+		})
+		return err
+	}
+
+	require.NoError(t, testQueries.BlockAccount(context.Background(), account.Username))
+	require.ErrorIs(t, rotate(), ErrAccountBlocked)
+
+	// The refused rotation rolled back, the token still works once unblocked.
+	require.NoError(t, testQueries.UnblockAccount(context.Background(), account.Username))
+	require.NoError(t, rotate())
+}
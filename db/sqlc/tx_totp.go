@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// EnrollTotpTxParams contains the input parameters of the EnrollTotpTx
+type EnrollTotpTxParams struct {
+	AccountID int64
+	Secret    []byte
+	Sealed    bool
+	// RecoveryCodeHashes replace the recovery codes of the account.
+	RecoveryCodeHashes []string
+}
+
+// EnrollTotpTx stores a new, not yet enabled, TOTP secret of the account with
+// its recovery codes. An unconfirmed enrollment is replaced, an enabled one
+// gives ErrTotpEnabled.
+func (store *SQLStore) EnrollTotpTx(ctx context.Context, arg EnrollTotpTxParams) (AccountTotp, error) {
+	var result AccountTotp
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = q.CreateAccountTotp(ctx, CreateAccountTotpParams{
+			AccountID: arg.AccountID,
+			Secret:    arg.Secret,
+			Sealed:    arg.Sealed,
+		})
+		if err == sql.ErrNoRows {
+			return ErrTotpEnabled
+		}
+		if err != nil {
+			return err
+		}
+
+		return replaceRecoveryCodes(ctx, q, arg.AccountID, arg.RecoveryCodeHashes)
+	})
+
+	return result, err
+}
+
+// lockEnabledTotp locks the TOTP row of the account, it gives ErrTotpNotEnabled
+// unless two-factor authentication is enabled.
+func lockEnabledTotp(ctx context.Context, q *Queries, accountID int64) error {
+	totp, err := q.GetAccountTotpForUpdate(ctx, accountID)
+	if err == sql.ErrNoRows || (err == nil && !totp.Enabled) {
+		return ErrTotpNotEnabled
+	}
+	return err
+}
+
+func replaceRecoveryCodes(ctx context.Context, q *Queries, accountID int64, hashes []string) error {
+	err := q.DeleteRecoveryCodes(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		err = q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+			AccountID: accountID,
+			CodeHash:  hash,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodesTxParams contains the input parameters of the ReplaceRecoveryCodesTx
+type ReplaceRecoveryCodesTxParams struct {
+	AccountID          int64
+	RecoveryCodeHashes []string
+}
+
+// ReplaceRecoveryCodesTx replaces the recovery codes of an account with
+// two-factor authentication enabled, otherwise it gives ErrTotpNotEnabled.
+func (store *SQLStore) ReplaceRecoveryCodesTx(ctx context.Context, arg ReplaceRecoveryCodesTxParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		err := lockEnabledTotp(ctx, q, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		return replaceRecoveryCodes(ctx, q, arg.AccountID, arg.RecoveryCodeHashes)
+	})
+}
+
+// DisableTotpTx removes the TOTP secret and the recovery codes of an account
+// with two-factor authentication enabled, otherwise it gives ErrTotpNotEnabled.
+func (store *SQLStore) DisableTotpTx(ctx context.Context, accountID int64) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		err := lockEnabledTotp(ctx, q, accountID)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteAccountTotp(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteRecoveryCodes(ctx, accountID)
+	})
+}
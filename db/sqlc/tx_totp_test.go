@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnrollTotpTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	enroll := func(secret string, codes ...string) (AccountTotp, error) {
+		return store.EnrollTotpTx(context.Background(), EnrollTotpTxParams{
+			AccountID:          account.ID,
+			Secret:             []byte(secret),
+			RecoveryCodeHashes: codes,
+		})
+	}
+
+	_, err := enroll(util.RandomString(20), "first")
+	require.NoError(t, err)
+
+	// An unconfirmed enrollment is replaced together with its recovery codes.
+	secret := util.RandomString(20)
+	totp, err := enroll(secret, "second", "third")
+	require.NoError(t, err)
+	require.Equal(t, []byte(secret), totp.Secret)
+	require.False(t, totp.Enabled)
+
+	useCode := func(hash string) int64 {
+		used, err := store.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{
+			AccountID: account.ID,
+			CodeHash:  hash,
+			UsedAt:    sql.NullTime{Time: time.Now(), Valid: true},
+		})
+		require.NoError(t, err)
+		return used
+	}
+	require.Zero(t, useCode("first"))
+
+	enabled, err := store.EnableAccountTotp(context.Background(), EnableAccountTotpParams{AccountID: account.ID, LastStep: 10})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), enabled)
+
+	_, err = enroll(util.RandomString(20), "fourth")
+	require.ErrorIs(t, err, ErrTotpEnabled)
+
+	totp, err = store.GetAccountTotp(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, []byte(secret), totp.Secret)
+	require.True(t, totp.Enabled)
+
+	// Each recovery code and each time step works once.
+	require.Equal(t, int64(1), useCode("second"))
+	require.Zero(t, useCode("second"))
+
+	accepted, err := store.UseAccountTotpStep(context.Background(), UseAccountTotpStepParams{AccountID: account.ID, LastStep: 10})
+	require.NoError(t, err)
+	require.Zero(t, accepted)
+	accepted, err = store.UseAccountTotpStep(context.Background(), UseAccountTotpStepParams{AccountID: account.ID, LastStep: 11})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), accepted)
+}
+
+func TestDisableTotpTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	replace := func(codes ...string) error {
+		return store.ReplaceRecoveryCodesTx(context.Background(), ReplaceRecoveryCodesTxParams{
+			AccountID:          account.ID,
+			RecoveryCodeHashes: codes,
+		})
+	}
+	useCode := func(hash string) int64 {
+		used, err := store.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{
+			AccountID: account.ID,
+			CodeHash:  hash,
+			UsedAt:    sql.NullTime{Time: time.Now(), Valid: true},
+		})
+		require.NoError(t, err)
+		return used
+	}
+
+	// Nothing is changed before the enrollment is confirmed.
+	_, err := store.EnrollTotpTx(context.Background(), EnrollTotpTxParams{
+		AccountID:          account.ID,
+		Secret:             []byte(util.RandomString(20)),
+		RecoveryCodeHashes: []string{"first"},
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, replace("second"), ErrTotpNotEnabled)
+	require.ErrorIs(t, store.DisableTotpTx(context.Background(), account.ID), ErrTotpNotEnabled)
+
+	_, err = store.EnableAccountTotp(context.Background(), EnableAccountTotpParams{AccountID: account.ID, LastStep: 1})
+	require.NoError(t, err)
+
+	// The new recovery codes replace all the former ones.
+	require.NoError(t, replace("second", "third"))
+	require.Zero(t, useCode("first"))
+	require.Equal(t, int64(1), useCode("second"))
+
+	require.NoError(t, store.DisableTotpTx(context.Background(), account.ID))
+	_, err = store.GetAccountTotp(context.Background(), account.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.Zero(t, useCode("third"))
+
+	require.ErrorIs(t, store.DisableTotpTx(context.Background(), account.ID), ErrTotpNotEnabled)
+}
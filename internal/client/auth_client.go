@@ -2,30 +2,43 @@ package client
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
+// ErrSecondFactorRequired is returned by Login for accounts with two-factor
+// authentication, the login is finished by CompleteLogin.
+var ErrSecondFactorRequired = errors.New("second factor required")
+
+// AuthClient keeps the refresh token of the session. The password is only
+// needed for the first Login.
 type AuthClient struct {
-	service  pb.AuthenticationClient
-	username string
-	password string
+	service pb.AuthenticationClient
+
+	mu           sync.Mutex
+	refreshToken string
+	challenge    string
 }
 
-func NewAuthClient(cc *grpc.ClientConn, username string, password string) *AuthClient {
+func NewAuthClient(cc *grpc.ClientConn) *AuthClient {
 	service := pb.NewAuthenticationClient(cc)
-	return &AuthClient{service, username, password}
+	return &AuthClient{service: service}
 }
 
-func (client *AuthClient) Login() (string, error) {
+// Login starts a session and returns its first access token.
+func (client *AuthClient) Login(username string, password string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	req := &pb.LoginRequest{
-		Login:    client.username,
-		Password: client.password,
+		Login:    username,
+		Password: password,
 	}
 
 	res, err := client.service.Login(ctx, req)
@@ -33,5 +46,107 @@ func (client *AuthClient) Login() (string, error) {
 		return "", err
 	}
 
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if res.GetChallenge() != "" {
+		client.challenge = res.GetChallenge()
+		return "", ErrSecondFactorRequired
+	}
+
+	client.refreshToken = res.GetRefreshToken()
+	return res.GetToken(), nil
+}
+
+// CompleteLogin finishes a Login that needs the second factor, the code of
+// the authenticator app or one of the recovery codes.
+func (client *AuthClient) CompleteLogin(code string) (string, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if client.challenge == "" {
+		return "", fmt.Errorf("no login to complete")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := client.service.CompleteLogin(ctx, &pb.CompleteLoginRequest{
+		Challenge: client.challenge,
+		Code:      code,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	client.challenge = ""
+	client.refreshToken = res.GetRefreshToken()
+	return res.GetToken(), nil
+}
+
+// Refresh returns a new access token. The refresh token is rotated by the
+// server on every call, so calls are not made in parallel.
+func (client *AuthClient) Refresh() (string, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if client.refreshToken == "" {
+		return "", fmt.Errorf("not logged in")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := client.service.RefreshToken(ctx, &pb.RefreshTokenRequest{
+		RefreshToken: client.refreshToken,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	client.refreshToken = res.GetRefreshToken()
+	return res.GetToken(), nil
+}
+
+// Logout ends the session. The access token is the one the calls are made
+// with, it stops working together with the refresh token.
+func (client *AuthClient) Logout(accessToken string) error {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", accessToken)
+
+	_, err := client.service.Logout(ctx, &pb.LogoutRequest{
+		RefreshToken: client.refreshToken,
+	})
+	if err != nil {
+		return err
+	}
+
+	client.refreshToken = ""
+	return nil
+}
+
+// ChangePassword replaces the password of the account. The other sessions
+// end, this one goes on with the returned access token.
+func (client *AuthClient) ChangePassword(accessToken string, oldPassword string, newPassword string) (string, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", accessToken)
+
+	res, err := client.service.ChangePassword(ctx, &pb.ChangePasswordRequest{
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	client.refreshToken = res.GetRefreshToken()
 	return res.GetToken(), nil
 }
@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -13,9 +14,12 @@ import (
 type AuthInteceptor struct {
 	authClient  *AuthClient
 	authMethods map[string]bool
-	token       string
+
+	mu    sync.RWMutex
+	token string
 }
 
+// NewAuthInterceptor takes the client of a session started with Login.
 func NewAuthInterceptor(
 	authClient *AuthClient,
 	authMethods map[string]bool,
@@ -54,6 +58,9 @@ func (interceptor *AuthInteceptor) Unary() grpc.UnaryClientInterceptor {
 }
 
 func (interceptor *AuthInteceptor) attachToken(ctx context.Context) context.Context {
+	interceptor.mu.RLock()
+	defer interceptor.mu.RUnlock()
+
 	return metadata.AppendToOutgoingContext(ctx, "authorization", interceptor.token)
 }
 
@@ -81,13 +88,15 @@ func (interceptor *AuthInteceptor) scheduleRefreshToken(refreshDuration time.Dur
 }
 
 func (interceptor *AuthInteceptor) refreshToken() error {
-	token, err := interceptor.authClient.Login()
+	token, err := interceptor.authClient.Refresh()
 	if err != nil {
-		return nil
+		return err
 	}
 
+	interceptor.mu.Lock()
 	interceptor.token = token
-	log.Info().Msg(fmt.Sprintf("token refreshed %v", token))
+	interceptor.mu.Unlock()
+	log.Info().Msg("token refreshed")
 
 	return nil
 }
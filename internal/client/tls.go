@@ -0,0 +1,47 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// TransportCredentials returns the credentials to dial the server with. The
+// connection is plaintext without a CA file. With a certificate and its key
+// the client presents them, the server maps them to the account and the
+// protected calls need no token.
+func TransportCredentials(caFile string, certFile string, keyFile string) (credentials.TransportCredentials, error) {
+	if caFile == "" {
+		if certFile != "" {
+			return nil, fmt.Errorf("client certificate needs the CA file of the server")
+		}
+		return insecure.NewCredentials(), nil
+	}
+
+	bundle, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read CA file: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("CA file %s has no certificates", caFile)
+	}
+	tlsConfig := &tls.Config{
+		RootCAs:    roots,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
@@ -27,20 +27,97 @@ var file_service_proto_rawDesc = []byte{
 	0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x1a, 0x0a, 0x61, 0x75, 0x74,
 	0x68, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0d, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x73,
 	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0b, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x32, 0xd9, 0x01, 0x0a, 0x0e, 0x41, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69,
-	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x5e, 0x0a, 0x05, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x12,
-	0x28, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61,
-	0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x4c, 0x6f, 0x67,
-	0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x29, 0x2e, 0x67, 0x6f, 0x5f, 0x64,
+	0x6f, 0x74, 0x6f, 0x1a, 0x0b, 0x75, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x1a, 0x0b, 0x73, 0x68, 0x61, 0x72, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0b, 0x61,
+	0x64, 0x6d, 0x69, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0x82, 0x0b, 0x0a, 0x0e, 0x41,
+	0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x5e, 0x0a,
+	0x05, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x12, 0x28, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f,
+	0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c,
+	0x6f, 0x6d, 0x61, 0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x29, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76,
+	0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x4c, 0x6f,
+	0x67, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x67, 0x0a,
+	0x08, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x12, 0x2b, 0x2e, 0x67, 0x6f, 0x5f, 0x64,
 	0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64,
-	0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x67, 0x0a, 0x08, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74,
-	0x65, 0x72, 0x12, 0x2b, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61,
+	0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2c, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f,
+	0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c,
+	0x6f, 0x6d, 0x61, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x73, 0x0a, 0x0c, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73,
+	0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x2f, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f,
+	0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c,
+	0x6f, 0x6d, 0x61, 0x2e, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x30, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76,
+	0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70,
+	0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x76, 0x0a, 0x0d, 0x47,
+	0x65, 0x74, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x73, 0x12, 0x30, 0x2e, 0x67,
+	0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65,
+	0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x75, 0x62,
+	0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x31,
+	0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e,
+	0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x47, 0x65, 0x74, 0x50,
+	0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x00, 0x12, 0x61, 0x0a, 0x06, 0x4c, 0x6f, 0x67, 0x6f, 0x75, 0x74, 0x12, 0x29, 0x2e,
+	0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63,
+	0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x4c, 0x6f, 0x67, 0x6f, 0x75,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2a, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65,
+	0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69,
+	0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x4c, 0x6f, 0x67, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x82, 0x01, 0x0a, 0x11, 0x52, 0x65, 0x76, 0x6f, 0x6b,
+	0x65, 0x41, 0x6c, 0x6c, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x34, 0x2e, 0x67,
+	0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65,
+	0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65,
+	0x41, 0x6c, 0x6c, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x35, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61,
 	0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e,
-	0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x2c, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61,
-	0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x52, 0x65, 0x67,
-	0x69, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x32,
+	0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x41, 0x6c, 0x6c, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x76, 0x0a, 0x0d, 0x43,
+	0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x12, 0x30, 0x2e, 0x67,
+	0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65,
+	0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65,
+	0x74, 0x65, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x31,
+	0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e,
+	0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x70,
+	0x6c, 0x65, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x00, 0x12, 0x6a, 0x0a, 0x09, 0x45, 0x6e, 0x72, 0x6f, 0x6c, 0x6c, 0x32, 0x46, 0x41,
+	0x12, 0x2c, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76,
+	0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x45, 0x6e,
+	0x72, 0x6f, 0x6c, 0x6c, 0x32, 0x46, 0x41, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2d,
+	0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e,
+	0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x45, 0x6e, 0x72, 0x6f,
+	0x6c, 0x6c, 0x32, 0x46, 0x41, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12,
+	0x6d, 0x0a, 0x0a, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x32, 0x46, 0x41, 0x12, 0x2d, 0x2e,
+	0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63,
+	0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x72, 0x6d, 0x32, 0x46, 0x41, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2e, 0x2e, 0x67,
+	0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65,
+	0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72,
+	0x6d, 0x32, 0x46, 0x41, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x6d,
+	0x0a, 0x0a, 0x44, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x32, 0x46, 0x41, 0x12, 0x2d, 0x2e, 0x67,
+	0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65,
+	0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x44, 0x69, 0x73, 0x61, 0x62, 0x6c,
+	0x65, 0x32, 0x46, 0x41, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2e, 0x2e, 0x67, 0x6f,
+	0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64,
+	0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x44, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65,
+	0x32, 0x46, 0x41, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x94, 0x01,
+	0x0a, 0x17, 0x52, 0x65, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x52, 0x65, 0x63, 0x6f,
+	0x76, 0x65, 0x72, 0x79, 0x43, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x3a, 0x2e, 0x67, 0x6f, 0x5f, 0x64,
+	0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64,
+	0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x52, 0x65, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x79, 0x43, 0x6f, 0x64, 0x65, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x3b, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70,
+	0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f,
+	0x6d, 0x61, 0x2e, 0x52, 0x65, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x52, 0x65, 0x63,
+	0x6f, 0x76, 0x65, 0x72, 0x79, 0x43, 0x6f, 0x64, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x00, 0x12, 0x79, 0x0a, 0x0e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x50, 0x61,
+	0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x31, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f,
+	0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c,
+	0x6f, 0x6d, 0x61, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f,
+	0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x32, 0x2e, 0x67, 0x6f, 0x5f, 0x64,
+	0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64,
+	0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x50, 0x61, 0x73,
+	0x73, 0x77, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x32,
 	0xc2, 0x04, 0x0a, 0x06, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x12, 0x73, 0x0a, 0x0c, 0x43, 0x72,
 	0x65, 0x61, 0x74, 0x65, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x12, 0x2f, 0x2e, 0x67, 0x6f, 0x5f,
 	0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f,
@@ -77,7 +154,7 @@ var file_service_proto_rawDesc = []byte{
 	0x73, 0x74, 0x1a, 0x2e, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61,
 	0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e,
 	0x4c, 0x69, 0x73, 0x74, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x22, 0x00, 0x32, 0xa8, 0x04, 0x0a, 0x04, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x6f, 0x0a,
+	0x73, 0x65, 0x22, 0x00, 0x32, 0x9a, 0x0c, 0x0a, 0x04, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x6f, 0x0a,
 	0x0a, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x2d, 0x2e, 0x67, 0x6f,
 	0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64,
 	0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x46,
@@ -111,67 +188,335 @@ var file_service_proto_rawDesc = []byte{
 	0x4c, 0x69, 0x73, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
 	0x2c, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61,
 	0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x4c, 0x69, 0x73,
-	0x74, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42,
-	0x39, 0x5a, 0x37, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x4a, 0x61,
-	0x79, 0x2d, 0x54, 0x2f, 0x67, 0x6f, 0x2d, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x2d, 0x61, 0x64,
-	0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x2d, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2f, 0x69,
-	0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x33,
+	0x74, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12,
+	0x7f, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x33, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f,
+	0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61,
+	0x2e, 0x4c, 0x69, 0x73, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x34, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65,
+	0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69,
+	0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
+	0x12, 0x85, 0x01, 0x0a, 0x12, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x46, 0x69, 0x6c, 0x65,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x35, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76,
+	0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70,
+	0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x46, 0x69, 0x6c, 0x65,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x36,
+	0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e,
+	0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x52, 0x65, 0x73, 0x74,
+	0x6f, 0x72, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x76, 0x0a, 0x0d, 0x4d, 0x61, 0x6b, 0x65,
+	0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x30, 0x2e, 0x67, 0x6f, 0x5f, 0x64,
+	0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64,
+	0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x4d, 0x61, 0x6b, 0x65, 0x44, 0x69, 0x72, 0x65, 0x63,
+	0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x31, 0x2e, 0x67, 0x6f,
+	0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64,
+	0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x4d, 0x61, 0x6b, 0x65, 0x44, 0x69, 0x72,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
+	0x12, 0x7c, 0x0a, 0x0f, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x79, 0x12, 0x32, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f,
+	0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61,
+	0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x33, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76,
+	0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70,
+	0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x44, 0x69, 0x72, 0x65, 0x63,
+	0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x67,
+	0x0a, 0x08, 0x4d, 0x6f, 0x76, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x2b, 0x2e, 0x67, 0x6f, 0x5f,
+	0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f,
+	0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x4d, 0x6f, 0x76, 0x65, 0x46, 0x69, 0x6c, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2c, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76,
+	0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70,
+	0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x4d, 0x6f, 0x76, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x7c, 0x0a, 0x0f, 0x53, 0x65, 0x74, 0x46, 0x69,
+	0x6c, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x32, 0x2e, 0x67, 0x6f, 0x5f,
+	0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f,
+	0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x53, 0x65, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x4d,
+	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x33,
+	0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e,
+	0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x53, 0x65, 0x74, 0x46,
+	0x69, 0x6c, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x85, 0x01, 0x0a, 0x12, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x46, 0x69, 0x6c, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x35, 0x2e, 0x67,
+	0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65,
+	0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x46, 0x69, 0x6c, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x36, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f,
+	0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61,
+	0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x81, 0x01,
+	0x0a, 0x10, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f,
+	0x72, 0x79, 0x12, 0x33, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61,
+	0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e,
+	0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x34, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76,
+	0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70,
+	0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x44, 0x69, 0x72, 0x65,
+	0x63, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30,
+	0x01, 0x32, 0xfe, 0x02, 0x0a, 0x05, 0x53, 0x68, 0x61, 0x72, 0x65, 0x12, 0x7c, 0x0a, 0x0f, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x68, 0x61, 0x72, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x32,
+	0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e,
+	0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x53, 0x68, 0x61, 0x72, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x33, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61,
+	0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x68, 0x61, 0x72, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x79, 0x0a, 0x0e, 0x4c, 0x69, 0x73,
+	0x74, 0x53, 0x68, 0x61, 0x72, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x12, 0x31, 0x2e, 0x67, 0x6f,
+	0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64,
+	0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x68, 0x61,
+	0x72, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x32,
+	0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e,
+	0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x53, 0x68, 0x61, 0x72, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x00, 0x12, 0x7c, 0x0a, 0x0f, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x53, 0x68,
+	0x61, 0x72, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x32, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76,
+	0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70,
+	0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x53, 0x68, 0x61, 0x72, 0x65,
+	0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x33, 0x2e, 0x67, 0x6f,
+	0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64,
+	0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x53,
+	0x68, 0x61, 0x72, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x00, 0x32, 0x72, 0x0a, 0x07, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x67, 0x0a,
+	0x08, 0x47, 0x65, 0x74, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2b, 0x2e, 0x67, 0x6f, 0x5f, 0x64,
+	0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64,
+	0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2c, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f,
+	0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c,
+	0x6f, 0x6d, 0x61, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x32, 0x9d, 0x07, 0x0a, 0x05, 0x41, 0x64, 0x6d, 0x69, 0x6e,
+	0x12, 0x73, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73,
+	0x12, 0x2f, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76,
+	0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x4c, 0x69,
+	0x73, 0x74, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x30, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64,
+	0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x73, 0x0a, 0x0c, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x41, 0x63,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x2f, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70,
+	0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f,
+	0x6d, 0x61, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x30, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f,
+	0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c,
+	0x6f, 0x6d, 0x61, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x79, 0x0a, 0x0e, 0x55, 0x6e,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x31, 0x2e, 0x67,
+	0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65,
+	0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x55, 0x6e, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x32, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61,
+	0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x55, 0x6e, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x76, 0x0a, 0x0d, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41,
+	0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x30, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f,
+	0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c,
+	0x6f, 0x6d, 0x61, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x31, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65,
+	0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69,
+	0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41, 0x63, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x8b, 0x01,
+	0x0a, 0x14, 0x41, 0x64, 0x64, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x65, 0x72, 0x74, 0x69,
+	0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x37, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f,
+	0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c,
+	0x6f, 0x6d, 0x61, 0x2e, 0x41, 0x64, 0x64, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x65, 0x72,
+	0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x38, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61,
+	0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x41, 0x64, 0x64,
+	0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x91, 0x01, 0x0a, 0x16,
+	0x4c, 0x69, 0x73, 0x74, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66,
+	0x69, 0x63, 0x61, 0x74, 0x65, 0x73, 0x12, 0x39, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f,
+	0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c,
+	0x6f, 0x6d, 0x61, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x65,
+	0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x3a, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64,
+	0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69,
+	0x63, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12,
+	0x94, 0x01, 0x0a, 0x17, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x3a, 0x2e, 0x67, 0x6f,
+	0x5f, 0x64, 0x65, 0x76, 0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64,
+	0x5f, 0x64, 0x69, 0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x43,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x3b, 0x2e, 0x67, 0x6f, 0x5f, 0x64, 0x65, 0x76,
+	0x6f, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x5f, 0x64, 0x69, 0x70,
+	0x6c, 0x6f, 0x6d, 0x61, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x43, 0x6c, 0x69, 0x65, 0x6e,
+	0x74, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x39, 0x5a, 0x37, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x4a, 0x61, 0x79, 0x2d, 0x54, 0x2f, 0x67, 0x6f, 0x2d, 0x64, 0x65,
+	0x76, 0x6f, 0x70, 0x73, 0x2d, 0x61, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x2d, 0x64, 0x69,
+	0x70, 0x6c, 0x6f, 0x6d, 0x61, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70,
+	0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var file_service_proto_goTypes = []interface{}{
-	(*LoginRequest)(nil),         // 0: go_devops_advanced_diploma.LoginRequest
-	(*RegisterRequest)(nil),      // 1: go_devops_advanced_diploma.RegisterRequest
-	(*CreateSecretRequest)(nil),  // 2: go_devops_advanced_diploma.CreateSecretRequest
-	(*UpdateSecretRequest)(nil),  // 3: go_devops_advanced_diploma.UpdateSecretRequest
-	(*DeleteSecretRequest)(nil),  // 4: go_devops_advanced_diploma.DeleteSecretRequest
-	(*GetSecretRequest)(nil),     // 5: go_devops_advanced_diploma.GetSecretRequest
-	(*ListSecretRequest)(nil),    // 6: go_devops_advanced_diploma.ListSecretRequest
-	(*CreateFileRequest)(nil),    // 7: go_devops_advanced_diploma.CreateFileRequest
-	(*UpdateFileRequest)(nil),    // 8: go_devops_advanced_diploma.UpdateFileRequest
-	(*DeleteFileRequest)(nil),    // 9: go_devops_advanced_diploma.DeleteFileRequest
-	(*GetFileRequest)(nil),       // 10: go_devops_advanced_diploma.GetFileRequest
-	(*ListFileRequest)(nil),      // 11: go_devops_advanced_diploma.ListFileRequest
-	(*LoginResponse)(nil),        // 12: go_devops_advanced_diploma.LoginResponse
-	(*RegisterResponse)(nil),     // 13: go_devops_advanced_diploma.RegisterResponse
-	(*CreateSecretResponse)(nil), // 14: go_devops_advanced_diploma.CreateSecretResponse
-	(*UpdateSecretResponse)(nil), // 15: go_devops_advanced_diploma.UpdateSecretResponse
-	(*DeleteSecretResponse)(nil), // 16: go_devops_advanced_diploma.DeleteSecretResponse
-	(*GetSecretResponse)(nil),    // 17: go_devops_advanced_diploma.GetSecretResponse
-	(*ListSecretResponse)(nil),   // 18: go_devops_advanced_diploma.ListSecretResponse
-	(*CreateFileResponse)(nil),   // 19: go_devops_advanced_diploma.CreateFileResponse
-	(*UpdateFileResponse)(nil),   // 20: go_devops_advanced_diploma.UpdateFileResponse
-	(*DeleteFileResponse)(nil),   // 21: go_devops_advanced_diploma.DeleteFileResponse
-	(*GetFileResponse)(nil),      // 22: go_devops_advanced_diploma.GetFileResponse
-	(*ListFileResponse)(nil),     // 23: go_devops_advanced_diploma.ListFileResponse
+	(*LoginRequest)(nil),                    // 0: go_devops_advanced_diploma.LoginRequest
+	(*RegisterRequest)(nil),                 // 1: go_devops_advanced_diploma.RegisterRequest
+	(*RefreshTokenRequest)(nil),             // 2: go_devops_advanced_diploma.RefreshTokenRequest
+	(*GetPublicKeysRequest)(nil),            // 3: go_devops_advanced_diploma.GetPublicKeysRequest
+	(*LogoutRequest)(nil),                   // 4: go_devops_advanced_diploma.LogoutRequest
+	(*RevokeAllSessionsRequest)(nil),        // 5: go_devops_advanced_diploma.RevokeAllSessionsRequest
+	(*CompleteLoginRequest)(nil),            // 6: go_devops_advanced_diploma.CompleteLoginRequest
+	(*Enroll2FARequest)(nil),                // 7: go_devops_advanced_diploma.Enroll2FARequest
+	(*Confirm2FARequest)(nil),               // 8: go_devops_advanced_diploma.Confirm2FARequest
+	(*Disable2FARequest)(nil),               // 9: go_devops_advanced_diploma.Disable2FARequest
+	(*RegenerateRecoveryCodesRequest)(nil),  // 10: go_devops_advanced_diploma.RegenerateRecoveryCodesRequest
+	(*ChangePasswordRequest)(nil),           // 11: go_devops_advanced_diploma.ChangePasswordRequest
+	(*CreateSecretRequest)(nil),             // 12: go_devops_advanced_diploma.CreateSecretRequest
+	(*UpdateSecretRequest)(nil),             // 13: go_devops_advanced_diploma.UpdateSecretRequest
+	(*DeleteSecretRequest)(nil),             // 14: go_devops_advanced_diploma.DeleteSecretRequest
+	(*GetSecretRequest)(nil),                // 15: go_devops_advanced_diploma.GetSecretRequest
+	(*ListSecretRequest)(nil),               // 16: go_devops_advanced_diploma.ListSecretRequest
+	(*CreateFileRequest)(nil),               // 17: go_devops_advanced_diploma.CreateFileRequest
+	(*UpdateFileRequest)(nil),               // 18: go_devops_advanced_diploma.UpdateFileRequest
+	(*DeleteFileRequest)(nil),               // 19: go_devops_advanced_diploma.DeleteFileRequest
+	(*GetFileRequest)(nil),                  // 20: go_devops_advanced_diploma.GetFileRequest
+	(*ListFileRequest)(nil),                 // 21: go_devops_advanced_diploma.ListFileRequest
+	(*ListFileVersionsRequest)(nil),         // 22: go_devops_advanced_diploma.ListFileVersionsRequest
+	(*RestoreFileVersionRequest)(nil),       // 23: go_devops_advanced_diploma.RestoreFileVersionRequest
+	(*MakeDirectoryRequest)(nil),            // 24: go_devops_advanced_diploma.MakeDirectoryRequest
+	(*RemoveDirectoryRequest)(nil),          // 25: go_devops_advanced_diploma.RemoveDirectoryRequest
+	(*MoveFileRequest)(nil),                 // 26: go_devops_advanced_diploma.MoveFileRequest
+	(*SetFileMetadataRequest)(nil),          // 27: go_devops_advanced_diploma.SetFileMetadataRequest
+	(*DeleteFileMetadataRequest)(nil),       // 28: go_devops_advanced_diploma.DeleteFileMetadataRequest
+	(*ArchiveDirectoryRequest)(nil),         // 29: go_devops_advanced_diploma.ArchiveDirectoryRequest
+	(*CreateShareLinkRequest)(nil),          // 30: go_devops_advanced_diploma.CreateShareLinkRequest
+	(*ListShareLinksRequest)(nil),           // 31: go_devops_advanced_diploma.ListShareLinksRequest
+	(*RevokeShareLinkRequest)(nil),          // 32: go_devops_advanced_diploma.RevokeShareLinkRequest
+	(*GetUsageRequest)(nil),                 // 33: go_devops_advanced_diploma.GetUsageRequest
+	(*ListAccountsRequest)(nil),             // 34: go_devops_advanced_diploma.ListAccountsRequest
+	(*BlockAccountRequest)(nil),             // 35: go_devops_advanced_diploma.BlockAccountRequest
+	(*UnblockAccountRequest)(nil),           // 36: go_devops_advanced_diploma.UnblockAccountRequest
+	(*DeleteAccountRequest)(nil),            // 37: go_devops_advanced_diploma.DeleteAccountRequest
+	(*AddClientCertificateRequest)(nil),     // 38: go_devops_advanced_diploma.AddClientCertificateRequest
+	(*ListClientCertificatesRequest)(nil),   // 39: go_devops_advanced_diploma.ListClientCertificatesRequest
+	(*RemoveClientCertificateRequest)(nil),  // 40: go_devops_advanced_diploma.RemoveClientCertificateRequest
+	(*LoginResponse)(nil),                   // 41: go_devops_advanced_diploma.LoginResponse
+	(*RegisterResponse)(nil),                // 42: go_devops_advanced_diploma.RegisterResponse
+	(*RefreshTokenResponse)(nil),            // 43: go_devops_advanced_diploma.RefreshTokenResponse
+	(*GetPublicKeysResponse)(nil),           // 44: go_devops_advanced_diploma.GetPublicKeysResponse
+	(*LogoutResponse)(nil),                  // 45: go_devops_advanced_diploma.LogoutResponse
+	(*RevokeAllSessionsResponse)(nil),       // 46: go_devops_advanced_diploma.RevokeAllSessionsResponse
+	(*CompleteLoginResponse)(nil),           // 47: go_devops_advanced_diploma.CompleteLoginResponse
+	(*Enroll2FAResponse)(nil),               // 48: go_devops_advanced_diploma.Enroll2FAResponse
+	(*Confirm2FAResponse)(nil),              // 49: go_devops_advanced_diploma.Confirm2FAResponse
+	(*Disable2FAResponse)(nil),              // 50: go_devops_advanced_diploma.Disable2FAResponse
+	(*RegenerateRecoveryCodesResponse)(nil), // 51: go_devops_advanced_diploma.RegenerateRecoveryCodesResponse
+	(*ChangePasswordResponse)(nil),          // 52: go_devops_advanced_diploma.ChangePasswordResponse
+	(*CreateSecretResponse)(nil),            // 53: go_devops_advanced_diploma.CreateSecretResponse
+	(*UpdateSecretResponse)(nil),            // 54: go_devops_advanced_diploma.UpdateSecretResponse
+	(*DeleteSecretResponse)(nil),            // 55: go_devops_advanced_diploma.DeleteSecretResponse
+	(*GetSecretResponse)(nil),               // 56: go_devops_advanced_diploma.GetSecretResponse
+	(*ListSecretResponse)(nil),              // 57: go_devops_advanced_diploma.ListSecretResponse
+	(*CreateFileResponse)(nil),              // 58: go_devops_advanced_diploma.CreateFileResponse
+	(*UpdateFileResponse)(nil),              // 59: go_devops_advanced_diploma.UpdateFileResponse
+	(*DeleteFileResponse)(nil),              // 60: go_devops_advanced_diploma.DeleteFileResponse
+	(*GetFileResponse)(nil),                 // 61: go_devops_advanced_diploma.GetFileResponse
+	(*ListFileResponse)(nil),                // 62: go_devops_advanced_diploma.ListFileResponse
+	(*ListFileVersionsResponse)(nil),        // 63: go_devops_advanced_diploma.ListFileVersionsResponse
+	(*RestoreFileVersionResponse)(nil),      // 64: go_devops_advanced_diploma.RestoreFileVersionResponse
+	(*MakeDirectoryResponse)(nil),           // 65: go_devops_advanced_diploma.MakeDirectoryResponse
+	(*RemoveDirectoryResponse)(nil),         // 66: go_devops_advanced_diploma.RemoveDirectoryResponse
+	(*MoveFileResponse)(nil),                // 67: go_devops_advanced_diploma.MoveFileResponse
+	(*SetFileMetadataResponse)(nil),         // 68: go_devops_advanced_diploma.SetFileMetadataResponse
+	(*DeleteFileMetadataResponse)(nil),      // 69: go_devops_advanced_diploma.DeleteFileMetadataResponse
+	(*ArchiveDirectoryResponse)(nil),        // 70: go_devops_advanced_diploma.ArchiveDirectoryResponse
+	(*CreateShareLinkResponse)(nil),         // 71: go_devops_advanced_diploma.CreateShareLinkResponse
+	(*ListShareLinksResponse)(nil),          // 72: go_devops_advanced_diploma.ListShareLinksResponse
+	(*RevokeShareLinkResponse)(nil),         // 73: go_devops_advanced_diploma.RevokeShareLinkResponse
+	(*GetUsageResponse)(nil),                // 74: go_devops_advanced_diploma.GetUsageResponse
+	(*ListAccountsResponse)(nil),            // 75: go_devops_advanced_diploma.ListAccountsResponse
+	(*BlockAccountResponse)(nil),            // 76: go_devops_advanced_diploma.BlockAccountResponse
+	(*UnblockAccountResponse)(nil),          // 77: go_devops_advanced_diploma.UnblockAccountResponse
+	(*DeleteAccountResponse)(nil),           // 78: go_devops_advanced_diploma.DeleteAccountResponse
+	(*AddClientCertificateResponse)(nil),    // 79: go_devops_advanced_diploma.AddClientCertificateResponse
+	(*ListClientCertificatesResponse)(nil),  // 80: go_devops_advanced_diploma.ListClientCertificatesResponse
+	(*RemoveClientCertificateResponse)(nil), // 81: go_devops_advanced_diploma.RemoveClientCertificateResponse
 }
 var file_service_proto_depIdxs = []int32{
 	0,  // 0: go_devops_advanced_diploma.Authentication.Login:input_type -> go_devops_advanced_diploma.LoginRequest
 	1,  // 1: go_devops_advanced_diploma.Authentication.Register:input_type -> go_devops_advanced_diploma.RegisterRequest
-	2,  // 2: go_devops_advanced_diploma.Secret.CreateSecret:input_type -> go_devops_advanced_diploma.CreateSecretRequest
-	3,  // 3: go_devops_advanced_diploma.Secret.UpdateSecret:input_type -> go_devops_advanced_diploma.UpdateSecretRequest
-	4,  // 4: go_devops_advanced_diploma.Secret.DeleteSecret:input_type -> go_devops_advanced_diploma.DeleteSecretRequest
-	5,  // 5: go_devops_advanced_diploma.Secret.GetSecret:input_type -> go_devops_advanced_diploma.GetSecretRequest
-	6,  // 6: go_devops_advanced_diploma.Secret.ListSecret:input_type -> go_devops_advanced_diploma.ListSecretRequest
-	7,  // 7: go_devops_advanced_diploma.File.CreateFile:input_type -> go_devops_advanced_diploma.CreateFileRequest
-	8,  // 8: go_devops_advanced_diploma.File.UpdateFile:input_type -> go_devops_advanced_diploma.UpdateFileRequest
-	9,  // 9: go_devops_advanced_diploma.File.DeleteFile:input_type -> go_devops_advanced_diploma.DeleteFileRequest
-	10, // 10: go_devops_advanced_diploma.File.GetFile:input_type -> go_devops_advanced_diploma.GetFileRequest
-	11, // 11: go_devops_advanced_diploma.File.ListFile:input_type -> go_devops_advanced_diploma.ListFileRequest
-	12, // 12: go_devops_advanced_diploma.Authentication.Login:output_type -> go_devops_advanced_diploma.LoginResponse
-	13, // 13: go_devops_advanced_diploma.Authentication.Register:output_type -> go_devops_advanced_diploma.RegisterResponse
-	14, // 14: go_devops_advanced_diploma.Secret.CreateSecret:output_type -> go_devops_advanced_diploma.CreateSecretResponse
-	15, // 15: go_devops_advanced_diploma.Secret.UpdateSecret:output_type -> go_devops_advanced_diploma.UpdateSecretResponse
-	16, // 16: go_devops_advanced_diploma.Secret.DeleteSecret:output_type -> go_devops_advanced_diploma.DeleteSecretResponse
-	17, // 17: go_devops_advanced_diploma.Secret.GetSecret:output_type -> go_devops_advanced_diploma.GetSecretResponse
-	18, // 18: go_devops_advanced_diploma.Secret.ListSecret:output_type -> go_devops_advanced_diploma.ListSecretResponse
-	19, // 19: go_devops_advanced_diploma.File.CreateFile:output_type -> go_devops_advanced_diploma.CreateFileResponse
-	20, // 20: go_devops_advanced_diploma.File.UpdateFile:output_type -> go_devops_advanced_diploma.UpdateFileResponse
-	21, // 21: go_devops_advanced_diploma.File.DeleteFile:output_type -> go_devops_advanced_diploma.DeleteFileResponse
-	22, // 22: go_devops_advanced_diploma.File.GetFile:output_type -> go_devops_advanced_diploma.GetFileResponse
-	23, // 23: go_devops_advanced_diploma.File.ListFile:output_type -> go_devops_advanced_diploma.ListFileResponse
-	12, // [12:24] is the sub-list for method output_type
-	0,  // [0:12] is the sub-list for method input_type
+	2,  // 2: go_devops_advanced_diploma.Authentication.RefreshToken:input_type -> go_devops_advanced_diploma.RefreshTokenRequest
+	3,  // 3: go_devops_advanced_diploma.Authentication.GetPublicKeys:input_type -> go_devops_advanced_diploma.GetPublicKeysRequest
+	4,  // 4: go_devops_advanced_diploma.Authentication.Logout:input_type -> go_devops_advanced_diploma.LogoutRequest
+	5,  // 5: go_devops_advanced_diploma.Authentication.RevokeAllSessions:input_type -> go_devops_advanced_diploma.RevokeAllSessionsRequest
+	6,  // 6: go_devops_advanced_diploma.Authentication.CompleteLogin:input_type -> go_devops_advanced_diploma.CompleteLoginRequest
+	7,  // 7: go_devops_advanced_diploma.Authentication.Enroll2FA:input_type -> go_devops_advanced_diploma.Enroll2FARequest
+	8,  // 8: go_devops_advanced_diploma.Authentication.Confirm2FA:input_type -> go_devops_advanced_diploma.Confirm2FARequest
+	9,  // 9: go_devops_advanced_diploma.Authentication.Disable2FA:input_type -> go_devops_advanced_diploma.Disable2FARequest
+	10, // 10: go_devops_advanced_diploma.Authentication.RegenerateRecoveryCodes:input_type -> go_devops_advanced_diploma.RegenerateRecoveryCodesRequest
+	11, // 11: go_devops_advanced_diploma.Authentication.ChangePassword:input_type -> go_devops_advanced_diploma.ChangePasswordRequest
+	12, // 12: go_devops_advanced_diploma.Secret.CreateSecret:input_type -> go_devops_advanced_diploma.CreateSecretRequest
+	13, // 13: go_devops_advanced_diploma.Secret.UpdateSecret:input_type -> go_devops_advanced_diploma.UpdateSecretRequest
+	14, // 14: go_devops_advanced_diploma.Secret.DeleteSecret:input_type -> go_devops_advanced_diploma.DeleteSecretRequest
+	15, // 15: go_devops_advanced_diploma.Secret.GetSecret:input_type -> go_devops_advanced_diploma.GetSecretRequest
+	16, // 16: go_devops_advanced_diploma.Secret.ListSecret:input_type -> go_devops_advanced_diploma.ListSecretRequest
+	17, // 17: go_devops_advanced_diploma.File.CreateFile:input_type -> go_devops_advanced_diploma.CreateFileRequest
+	18, // 18: go_devops_advanced_diploma.File.UpdateFile:input_type -> go_devops_advanced_diploma.UpdateFileRequest
+	19, // 19: go_devops_advanced_diploma.File.DeleteFile:input_type -> go_devops_advanced_diploma.DeleteFileRequest
+	20, // 20: go_devops_advanced_diploma.File.GetFile:input_type -> go_devops_advanced_diploma.GetFileRequest
+	21, // 21: go_devops_advanced_diploma.File.ListFile:input_type -> go_devops_advanced_diploma.ListFileRequest
+	22, // 22: go_devops_advanced_diploma.File.ListFileVersions:input_type -> go_devops_advanced_diploma.ListFileVersionsRequest
+	23, // 23: go_devops_advanced_diploma.File.RestoreFileVersion:input_type -> go_devops_advanced_diploma.RestoreFileVersionRequest
+	24, // 24: go_devops_advanced_diploma.File.MakeDirectory:input_type -> go_devops_advanced_diploma.MakeDirectoryRequest
+	25, // 25: go_devops_advanced_diploma.File.RemoveDirectory:input_type -> go_devops_advanced_diploma.RemoveDirectoryRequest
+	26, // 26: go_devops_advanced_diploma.File.MoveFile:input_type -> go_devops_advanced_diploma.MoveFileRequest
+	27, // 27: go_devops_advanced_diploma.File.SetFileMetadata:input_type -> go_devops_advanced_diploma.SetFileMetadataRequest
+	28, // 28: go_devops_advanced_diploma.File.DeleteFileMetadata:input_type -> go_devops_advanced_diploma.DeleteFileMetadataRequest
+	29, // 29: go_devops_advanced_diploma.File.ArchiveDirectory:input_type -> go_devops_advanced_diploma.ArchiveDirectoryRequest
+	30, // 30: go_devops_advanced_diploma.Share.CreateShareLink:input_type -> go_devops_advanced_diploma.CreateShareLinkRequest
+	31, // 31: go_devops_advanced_diploma.Share.ListShareLinks:input_type -> go_devops_advanced_diploma.ListShareLinksRequest
+	32, // 32: go_devops_advanced_diploma.Share.RevokeShareLink:input_type -> go_devops_advanced_diploma.RevokeShareLinkRequest
+	33, // 33: go_devops_advanced_diploma.Account.GetUsage:input_type -> go_devops_advanced_diploma.GetUsageRequest
+	34, // 34: go_devops_advanced_diploma.Admin.ListAccounts:input_type -> go_devops_advanced_diploma.ListAccountsRequest
+	35, // 35: go_devops_advanced_diploma.Admin.BlockAccount:input_type -> go_devops_advanced_diploma.BlockAccountRequest
+	36, // 36: go_devops_advanced_diploma.Admin.UnblockAccount:input_type -> go_devops_advanced_diploma.UnblockAccountRequest
+	37, // 37: go_devops_advanced_diploma.Admin.DeleteAccount:input_type -> go_devops_advanced_diploma.DeleteAccountRequest
+	38, // 38: go_devops_advanced_diploma.Admin.AddClientCertificate:input_type -> go_devops_advanced_diploma.AddClientCertificateRequest
+	39, // 39: go_devops_advanced_diploma.Admin.ListClientCertificates:input_type -> go_devops_advanced_diploma.ListClientCertificatesRequest
+	40, // 40: go_devops_advanced_diploma.Admin.RemoveClientCertificate:input_type -> go_devops_advanced_diploma.RemoveClientCertificateRequest
+	41, // 41: go_devops_advanced_diploma.Authentication.Login:output_type -> go_devops_advanced_diploma.LoginResponse
+	42, // 42: go_devops_advanced_diploma.Authentication.Register:output_type -> go_devops_advanced_diploma.RegisterResponse
+	43, // 43: go_devops_advanced_diploma.Authentication.RefreshToken:output_type -> go_devops_advanced_diploma.RefreshTokenResponse
+	44, // 44: go_devops_advanced_diploma.Authentication.GetPublicKeys:output_type -> go_devops_advanced_diploma.GetPublicKeysResponse
+	45, // 45: go_devops_advanced_diploma.Authentication.Logout:output_type -> go_devops_advanced_diploma.LogoutResponse
+	46, // 46: go_devops_advanced_diploma.Authentication.RevokeAllSessions:output_type -> go_devops_advanced_diploma.RevokeAllSessionsResponse
+	47, // 47: go_devops_advanced_diploma.Authentication.CompleteLogin:output_type -> go_devops_advanced_diploma.CompleteLoginResponse
+	48, // 48: go_devops_advanced_diploma.Authentication.Enroll2FA:output_type -> go_devops_advanced_diploma.Enroll2FAResponse
+	49, // 49: go_devops_advanced_diploma.Authentication.Confirm2FA:output_type -> go_devops_advanced_diploma.Confirm2FAResponse
+	50, // 50: go_devops_advanced_diploma.Authentication.Disable2FA:output_type -> go_devops_advanced_diploma.Disable2FAResponse
+	51, // 51: go_devops_advanced_diploma.Authentication.RegenerateRecoveryCodes:output_type -> go_devops_advanced_diploma.RegenerateRecoveryCodesResponse
+	52, // 52: go_devops_advanced_diploma.Authentication.ChangePassword:output_type -> go_devops_advanced_diploma.ChangePasswordResponse
+	53, // 53: go_devops_advanced_diploma.Secret.CreateSecret:output_type -> go_devops_advanced_diploma.CreateSecretResponse
+	54, // 54: go_devops_advanced_diploma.Secret.UpdateSecret:output_type -> go_devops_advanced_diploma.UpdateSecretResponse
+	55, // 55: go_devops_advanced_diploma.Secret.DeleteSecret:output_type -> go_devops_advanced_diploma.DeleteSecretResponse
+	56, // 56: go_devops_advanced_diploma.Secret.GetSecret:output_type -> go_devops_advanced_diploma.GetSecretResponse
+	57, // 57: go_devops_advanced_diploma.Secret.ListSecret:output_type -> go_devops_advanced_diploma.ListSecretResponse
+	58, // 58: go_devops_advanced_diploma.File.CreateFile:output_type -> go_devops_advanced_diploma.CreateFileResponse
+	59, // 59: go_devops_advanced_diploma.File.UpdateFile:output_type -> go_devops_advanced_diploma.UpdateFileResponse
+	60, // 60: go_devops_advanced_diploma.File.DeleteFile:output_type -> go_devops_advanced_diploma.DeleteFileResponse
+	61, // 61: go_devops_advanced_diploma.File.GetFile:output_type -> go_devops_advanced_diploma.GetFileResponse
+	62, // 62: go_devops_advanced_diploma.File.ListFile:output_type -> go_devops_advanced_diploma.ListFileResponse
+	63, // 63: go_devops_advanced_diploma.File.ListFileVersions:output_type -> go_devops_advanced_diploma.ListFileVersionsResponse
+	64, // 64: go_devops_advanced_diploma.File.RestoreFileVersion:output_type -> go_devops_advanced_diploma.RestoreFileVersionResponse
+	65, // 65: go_devops_advanced_diploma.File.MakeDirectory:output_type -> go_devops_advanced_diploma.MakeDirectoryResponse
+	66, // 66: go_devops_advanced_diploma.File.RemoveDirectory:output_type -> go_devops_advanced_diploma.RemoveDirectoryResponse
+	67, // 67: go_devops_advanced_diploma.File.MoveFile:output_type -> go_devops_advanced_diploma.MoveFileResponse
+	68, // 68: go_devops_advanced_diploma.File.SetFileMetadata:output_type -> go_devops_advanced_diploma.SetFileMetadataResponse
+	69, // 69: go_devops_advanced_diploma.File.DeleteFileMetadata:output_type -> go_devops_advanced_diploma.DeleteFileMetadataResponse
+	70, // 70: go_devops_advanced_diploma.File.ArchiveDirectory:output_type -> go_devops_advanced_diploma.ArchiveDirectoryResponse
+	71, // 71: go_devops_advanced_diploma.Share.CreateShareLink:output_type -> go_devops_advanced_diploma.CreateShareLinkResponse
+	72, // 72: go_devops_advanced_diploma.Share.ListShareLinks:output_type -> go_devops_advanced_diploma.ListShareLinksResponse
+	73, // 73: go_devops_advanced_diploma.Share.RevokeShareLink:output_type -> go_devops_advanced_diploma.RevokeShareLinkResponse
+	74, // 74: go_devops_advanced_diploma.Account.GetUsage:output_type -> go_devops_advanced_diploma.GetUsageResponse
+	75, // 75: go_devops_advanced_diploma.Admin.ListAccounts:output_type -> go_devops_advanced_diploma.ListAccountsResponse
+	76, // 76: go_devops_advanced_diploma.Admin.BlockAccount:output_type -> go_devops_advanced_diploma.BlockAccountResponse
+	77, // 77: go_devops_advanced_diploma.Admin.UnblockAccount:output_type -> go_devops_advanced_diploma.UnblockAccountResponse
+	78, // 78: go_devops_advanced_diploma.Admin.DeleteAccount:output_type -> go_devops_advanced_diploma.DeleteAccountResponse
+	79, // 79: go_devops_advanced_diploma.Admin.AddClientCertificate:output_type -> go_devops_advanced_diploma.AddClientCertificateResponse
+	80, // 80: go_devops_advanced_diploma.Admin.ListClientCertificates:output_type -> go_devops_advanced_diploma.ListClientCertificatesResponse
+	81, // 81: go_devops_advanced_diploma.Admin.RemoveClientCertificate:output_type -> go_devops_advanced_diploma.RemoveClientCertificateResponse
+	41, // [41:82] is the sub-list for method output_type
+	0,  // [0:41] is the sub-list for method input_type
 	0,  // [0:0] is the sub-list for extension type_name
 	0,  // [0:0] is the sub-list for extension extendee
 	0,  // [0:0] is the sub-list for field type_name
@@ -185,6 +530,9 @@ func file_service_proto_init() {
 	file_auth_proto_init()
 	file_secrets_proto_init()
 	file_files_proto_init()
+	file_usage_proto_init()
+	file_share_proto_init()
+	file_admin_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
@@ -193,7 +541,7 @@ func file_service_proto_init() {
 			NumEnums:      0,
 			NumMessages:   0,
 			NumExtensions: 0,
-			NumServices:   3,
+			NumServices:   6,
 		},
 		GoTypes:           file_service_proto_goTypes,
 		DependencyIndexes: file_service_proto_depIdxs,
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type AccountServer struct {
+	accountStore db.Store
+	quota        Quota
+	pb.UnimplementedAccountServer
+}
+
+func NewAccountServer(accountStore db.Store, quota Quota) *AccountServer {
+	return &AccountServer{accountStore, quota, pb.UnimplementedAccountServer{}}
+}
+
+func (s *AccountServer) GetUsage(ctx context.Context, in *pb.GetUsageRequest) (*pb.GetUsageResponse, error) {
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Msgf("Got GetUsage request for login '%s'", caller.Username)
+
+	account, err := s.accountStore.GetAccountByID(ctx, caller.AccountID)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot get account from db. Err :%s", err))
+	}
+
+	filesUsage, err := s.accountStore.GetFilesUsage(ctx, account.ID)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot get files usage. Err: %s", err))
+	}
+
+	secretCount, err := s.accountStore.CountSecrets(ctx, account.ID)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot get secrets usage. Err: %s", err))
+	}
+
+	quota := s.quota.forAccount(account)
+
+	return &pb.GetUsageResponse{
+		Bytes: &pb.Usage{
+			Used:  filesUsage.TotalBytes,
+			Limit: quota.Bytes,
+		},
+		Files: &pb.Usage{
+			Used:  filesUsage.FileCount,
+			Limit: quota.Files,
+		},
+		Secrets: &pb.Usage{
+			Used:  secretCount,
+			Limit: quota.Secrets,
+		},
+	}, nil
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+)
+
+// accountStatusTTL bounds how long a block or a revocation of all sessions
+// takes to reach the live tokens of the account on other server instances.
+const accountStatusTTL = 10 * time.Second
+
+// AccountStatus is the part of the account the access tokens are checked
+// against.
+type AccountStatus struct {
+	ID      int64
+	Blocked bool
+	// SessionEpoch is the lowest epoch of the valid access tokens.
+	SessionEpoch int64
+}
+
+type accountStatusEntry struct {
+	status    AccountStatus
+	checkedAt time.Time
+}
+
+// AccountStatusCache tells whether accounts may use their tokens, so the
+// authorized calls do not all hit the database.
+type AccountStatusCache struct {
+	store db.Store
+	ttl   time.Duration
+
+	mu       sync.Mutex
+	accounts map[string]accountStatusEntry
+}
+
+func NewAccountStatusCache(store db.Store, ttl time.Duration) *AccountStatusCache {
+	return &AccountStatusCache{
+		store:    store,
+		ttl:      ttl,
+		accounts: make(map[string]accountStatusEntry),
+	}
+}
+
+// Get returns the status of the account. A deleted account counts as blocked.
+func (c *AccountStatusCache) Get(ctx context.Context, username string) (AccountStatus, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.accounts[username]
+	c.mu.Unlock()
+	if ok && now.Sub(entry.checkedAt) < c.ttl {
+		return entry.status, nil
+	}
+
+	account, err := c.store.GetAccount(ctx, username)
+	if err != nil && err != sql.ErrNoRows {
+		return AccountStatus{}, err
+	}
+	entry = accountStatusEntry{
+		status: AccountStatus{
+			ID:           account.ID,
+			Blocked:      err == sql.ErrNoRows || account.Blocked,
+			SessionEpoch: account.SessionEpoch,
+		},
+		checkedAt: now,
+	}
+
+	c.mu.Lock()
+	// Expired entries of other accounts go on the way, the map stays at the
+	// size of the recently active accounts.
+	for name, cached := range c.accounts {
+		if now.Sub(cached.checkedAt) >= c.ttl {
+			delete(c.accounts, name)
+		}
+	}
+	c.accounts[username] = entry
+	c.mu.Unlock()
+
+	return entry.status, nil
+}
+
+// Invalidate drops the cached status, the next call reads the database.
+func (c *AccountStatusCache) Invalidate(username string) {
+	c.mu.Lock()
+	delete(c.accounts, username)
+	c.mu.Unlock()
+}
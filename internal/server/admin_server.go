@@ -0,0 +1,286 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultListAccountsLimit = 100
+	maxListAccountsLimit     = 1000
+)
+
+// AdminServer manages the accounts. Only callers with RoleAdmin, the users
+// listed as admins in the config, may call it.
+type AdminServer struct {
+	store         db.Store
+	fileServer    *FileServer
+	accountStatus *AccountStatusCache
+	certificates  *ClientCertificateCache
+	pb.UnimplementedAdminServer
+}
+
+func NewAdminServer(store db.Store, fileServer *FileServer, accountStatus *AccountStatusCache, certificates *ClientCertificateCache) *AdminServer {
+	return &AdminServer{store, fileServer, accountStatus, certificates, pb.UnimplementedAdminServer{}}
+}
+
+// requireAdmin returns the username of the caller if it is an admin.
+func (s *AdminServer) requireAdmin(ctx context.Context, method string) (string, error) {
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if !caller.HasRole(RoleAdmin) {
+		return "", logError(status.Errorf(codes.PermissionDenied, "user %s is not an admin", caller.Username))
+	}
+
+	log.Info().Msgf("receive an %s request from admin %s", method, caller.Username)
+	return caller.Username, nil
+}
+
+// getTargetAccount returns the account the admin request is about. Admins
+// cannot lock themselves out.
+func (s *AdminServer) getTargetAccount(ctx context.Context, admin string, username string) (db.Account, error) {
+	if username == "" {
+		return db.Account{}, logError(status.Error(codes.InvalidArgument, "username is empty"))
+	}
+	if username == admin {
+		return db.Account{}, logError(status.Error(codes.FailedPrecondition, "admins cannot change their own account"))
+	}
+
+	account, err := s.store.GetAccount(ctx, username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.Account{}, logError(status.Errorf(codes.NotFound, "cannot find account %s", username))
+		}
+		return db.Account{}, logError(status.Errorf(codes.Internal, "cannot get account from db. Err :%s", err))
+	}
+
+	return account, nil
+}
+
+func (s *AdminServer) ListAccounts(ctx context.Context, in *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	_, err := s.requireAdmin(ctx, "ListAccounts")
+	if err != nil {
+		return nil, err
+	}
+
+	limit := in.GetLimit()
+	if limit == 0 {
+		limit = defaultListAccountsLimit
+	}
+	if limit < 0 || limit > maxListAccountsLimit || in.GetOffset() < 0 {
+		return nil, logError(status.Errorf(codes.InvalidArgument, "limit must be at most %d and offset cannot be negative", maxListAccountsLimit))
+	}
+
+	accounts, err := s.store.ListAccounts(ctx, db.ListAccountsParams{
+		Limit:  limit,
+		Offset: in.GetOffset(),
+	})
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot list accounts: %v", err))
+	}
+
+	res := &pb.ListAccountsResponse{}
+	for _, account := range accounts {
+		res.Accounts = append(res.Accounts, accountToProto(account))
+	}
+
+	return res, nil
+}
+
+func (s *AdminServer) BlockAccount(ctx context.Context, in *pb.BlockAccountRequest) (*pb.BlockAccountResponse, error) {
+	admin, err := s.requireAdmin(ctx, "BlockAccount")
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.getTargetAccount(ctx, admin, in.GetUsername())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.BlockAccount(ctx, account.Username)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot block account: %v", err))
+	}
+	s.accountStatus.Invalidate(account.Username)
+
+	account.Blocked = true
+	log.Info().Msgf("Account %s blocked by %s", account.Username, admin)
+	return &pb.BlockAccountResponse{Account: accountToProto(account)}, nil
+}
+
+func (s *AdminServer) UnblockAccount(ctx context.Context, in *pb.UnblockAccountRequest) (*pb.UnblockAccountResponse, error) {
+	admin, err := s.requireAdmin(ctx, "UnblockAccount")
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.getTargetAccount(ctx, admin, in.GetUsername())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.UnblockAccount(ctx, account.Username)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot unblock account: %v", err))
+	}
+	s.accountStatus.Invalidate(account.Username)
+
+	account.Blocked = false
+	log.Info().Msgf("Account %s unblocked by %s", account.Username, admin)
+	return &pb.UnblockAccountResponse{Account: accountToProto(account)}, nil
+}
+
+// DeleteAccount deletes the account with all its data. The stored content no
+// other account refers to is removed as well.
+func (s *AdminServer) DeleteAccount(ctx context.Context, in *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	admin, err := s.requireAdmin(ctx, "DeleteAccount")
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.getTargetAccount(ctx, admin, in.GetUsername())
+	if err != nil {
+		return nil, err
+	}
+
+	releaser := newBlobReleaser(ctx, s.store, s.fileServer.fileContentSaver)
+	result, err := s.store.DeleteAccountTx(ctx, db.DeleteAccountTxParams{
+		AccountID:    account.ID,
+		AfterRelease: releaser.AfterRelease,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, logError(status.Errorf(codes.NotFound, "cannot find account %s", account.Username))
+		}
+		return nil, logError(status.Errorf(codes.Internal, "cannot delete account: %v", err))
+	}
+	releaser.Committed()
+	s.accountStatus.Invalidate(account.Username)
+
+	log.Info().Msgf("Account %s deleted by %s with %d files and %d secrets", account.Username, admin, result.FilesDeleted, result.SecretsDeleted)
+	return &pb.DeleteAccountResponse{
+		Account:        accountToProto(result.Account),
+		FilesDeleted:   result.FilesDeleted,
+		SecretsDeleted: result.SecretsDeleted,
+	}, nil
+}
+
+// AddClientCertificate maps a client certificate identity to an account, the
+// certificates with that identity log in as the account from then on.
+func (s *AdminServer) AddClientCertificate(ctx context.Context, in *pb.AddClientCertificateRequest) (*pb.AddClientCertificateResponse, error) {
+	admin, err := s.requireAdmin(ctx, "AddClientCertificate")
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateCertificateIdentity(in.GetIdentity())
+	if err != nil {
+		return nil, logError(status.Errorf(codes.InvalidArgument, "invalid identity: %v", err))
+	}
+
+	account, err := s.store.GetAccount(ctx, in.GetUsername())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, logError(status.Errorf(codes.NotFound, "cannot find account %s", in.GetUsername()))
+		}
+		return nil, logError(status.Errorf(codes.Internal, "cannot get account from db. Err :%s", err))
+	}
+
+	cert, err := s.store.CreateClientCertificate(ctx, db.CreateClientCertificateParams{
+		Identity:  in.GetIdentity(),
+		AccountID: account.ID,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return nil, logError(status.Errorf(codes.AlreadyExists, "identity %s is already mapped", in.GetIdentity()))
+		}
+		return nil, logError(status.Errorf(codes.Internal, "cannot add client certificate: %v", err))
+	}
+	// The identity may be cached as unknown.
+	s.certificates.Invalidate(cert.Identity)
+
+	log.Info().Msgf("Client certificate %s mapped to account %s by %s", cert.Identity, account.Username, admin)
+	return &pb.AddClientCertificateResponse{Certificate: clientCertificateToProto(cert, account.Username)}, nil
+}
+
+func (s *AdminServer) ListClientCertificates(ctx context.Context, in *pb.ListClientCertificatesRequest) (*pb.ListClientCertificatesResponse, error) {
+	_, err := s.requireAdmin(ctx, "ListClientCertificates")
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.store.GetAccount(ctx, in.GetUsername())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, logError(status.Errorf(codes.NotFound, "cannot find account %s", in.GetUsername()))
+		}
+		return nil, logError(status.Errorf(codes.Internal, "cannot get account from db. Err :%s", err))
+	}
+
+	certs, err := s.store.ListClientCertificates(ctx, account.ID)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot list client certificates: %v", err))
+	}
+
+	res := &pb.ListClientCertificatesResponse{}
+	for _, cert := range certs {
+		res.Certificates = append(res.Certificates, clientCertificateToProto(cert, account.Username))
+	}
+
+	return res, nil
+}
+
+// RemoveClientCertificate unmaps the identity. The other server instances
+// stop accepting its certificates within accountStatusTTL.
+func (s *AdminServer) RemoveClientCertificate(ctx context.Context, in *pb.RemoveClientCertificateRequest) (*pb.RemoveClientCertificateResponse, error) {
+	admin, err := s.requireAdmin(ctx, "RemoveClientCertificate")
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := s.store.DeleteClientCertificate(ctx, in.GetIdentity())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, logError(status.Errorf(codes.NotFound, "identity %s is not mapped", in.GetIdentity()))
+		}
+		return nil, logError(status.Errorf(codes.Internal, "cannot remove client certificate: %v", err))
+	}
+	s.certificates.Invalidate(cert.Identity)
+
+	account, err := s.store.GetAccountByID(ctx, cert.AccountID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, logError(status.Errorf(codes.Internal, "cannot get account from db. Err :%s", err))
+	}
+
+	log.Info().Msgf("Client certificate %s of account %s removed by %s", cert.Identity, account.Username, admin)
+	return &pb.RemoveClientCertificateResponse{Certificate: clientCertificateToProto(cert, account.Username)}, nil
+}
+
+func clientCertificateToProto(cert db.ClientCertificate, username string) *pb.ClientCertificateInfo {
+	return &pb.ClientCertificateInfo{
+		Identity:  cert.Identity,
+		Username:  username,
+		CreatedAt: timestamppb.New(cert.CreatedAt),
+	}
+}
+
+func accountToProto(account db.Account) *pb.AccountInfo {
+	return &pb.AccountInfo{
+		Id:        account.ID,
+		Username:  account.Username,
+		Blocked:   account.Blocked,
+		CreatedAt: timestamppb.New(account.CreatedAt),
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/Jay-T/go-devops-advanced-diploma/db/mock"
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminServerPermissions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := NewAdminServer(store, nil, NewAccountStatusCache(store, time.Minute), nil)
+
+	_, err := server.ListAccounts(principalContext(2, "user"), &pb.ListAccountsRequest{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = server.BlockAccount(principalContext(1, "admin", RoleAdmin), &pb.BlockAccountRequest{Username: "admin"})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	store.EXPECT().GetAccount(gomock.Any(), "missing").Return(db.Account{}, sql.ErrNoRows)
+	_, err = server.DeleteAccount(principalContext(1, "admin", RoleAdmin), &pb.DeleteAccountRequest{Username: "missing"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	store.EXPECT().
+		ListAccounts(gomock.Any(), db.ListAccountsParams{Limit: defaultListAccountsLimit}).
+		Return([]db.Account{{ID: 1, Username: "admin"}, {ID: 2, Username: "user", Blocked: true}}, nil)
+	res, err := server.ListAccounts(principalContext(1, "admin", RoleAdmin), &pb.ListAccountsRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.GetAccounts(), 2)
+	require.True(t, res.GetAccounts()[1].GetBlocked())
+}
+
+func TestBlockAccountTakesLiveTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	jwtManager := newTestJWTManager(t, time.Minute)
+	accountStatus := NewAccountStatusCache(store, time.Hour)
+	interceptor := NewAuthInterceptor(jwtManager, accountStatus, NewTokenRevocationList(store), nil, map[string]bool{"/test/Method": true}, nil)
+	server := NewAdminServer(store, nil, accountStatus, nil)
+
+	account := db.Account{ID: 2, Username: "user"}
+	token, err := jwtManager.GeneratetToken(&account)
+	require.NoError(t, err)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", token))
+
+	store.EXPECT().GetAccount(gomock.Any(), "user").Return(account, nil).Times(2)
+	_, err = interceptor.authorize(ctx, "/test/Method")
+	require.NoError(t, err)
+
+	// The status is cached, the database is not asked again.
+	_, err = interceptor.authorize(ctx, "/test/Method")
+	require.NoError(t, err)
+
+	store.EXPECT().BlockAccount(gomock.Any(), "user").Return(nil)
+	_, err = server.BlockAccount(principalContext(1, "admin", RoleAdmin), &pb.BlockAccountRequest{Username: "user"})
+	require.NoError(t, err)
+
+	blocked := account
+	blocked.Blocked = true
+	store.EXPECT().GetAccount(gomock.Any(), "user").Return(blocked, nil)
+	_, err = interceptor.authorize(ctx, "/test/Method")
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestLoginBlockedAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := NewAuthServer(store, newTestJWTManager(t, time.Minute), NewAccountStatusCache(store, time.Minute), NewTokenRevocationList(store), NewLoginLimiter(store, LoginLimits{}), NewTwoFactor(store, nil, "test"), PasswordPolicy{}, nil, time.Hour)
+
+	hash, err := util.HashPassword("secret")
+	require.NoError(t, err)
+	store.EXPECT().GetAccount(gomock.Any(), "user").
+		Return(db.Account{Username: "user", Passhash: hash, Blocked: true}, nil)
+
+	_, err = server.Login(context.Background(), &pb.LoginRequest{Login: "user", Password: "secret"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
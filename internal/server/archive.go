@@ -0,0 +1,350 @@
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	ArchiveFormatZip   = "zip"
+	ArchiveFormatTarGz = "tar.gz"
+)
+
+// archiveWriter adds files to an archive of some format.
+type archiveWriter interface {
+	// Create starts the next file of the archive, its content is written
+	// to the returned writer before the next call.
+	Create(name string, file db.File, size int64) (io.Writer, error)
+	Close() error
+}
+
+type zipArchiveWriter struct {
+	zw *zip.Writer
+}
+
+func (w *zipArchiveWriter) Create(name string, file db.File, size int64) (io.Writer, error) {
+	return w.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: file.CreatedAt,
+	})
+}
+
+func (w *zipArchiveWriter) Close() error {
+	return w.zw.Close()
+}
+
+type tarGzArchiveWriter struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func (w *tarGzArchiveWriter) Create(name string, file db.File, size int64) (io.Writer, error) {
+	// PAX keeps long and non-ASCII names intact.
+	err := w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0o644,
+		ModTime:  file.CreatedAt,
+		Format:   tar.FormatPAX,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return w.tw, nil
+}
+
+func (w *tarGzArchiveWriter) Close() error {
+	err := w.tw.Close()
+	if err != nil {
+		return err
+	}
+	return w.gz.Close()
+}
+
+func newArchiveWriter(format string, w io.Writer) (archiveWriter, error) {
+	switch format {
+	case "", ArchiveFormatZip:
+		return &zipArchiveWriter{zw: zip.NewWriter(w)}, nil
+	case ArchiveFormatTarGz:
+		gz := gzip.NewWriter(w)
+		return &tarGzArchiveWriter{gz: gz, tw: tar.NewWriter(gz)}, nil
+	default:
+		return nil, fmt.Errorf("unknown archive format '%s'", format)
+	}
+}
+
+// streamWriter sends what is written to it as chunks of at most chunkSize.
+// Writes are buffered, so the archive does not turn into tiny messages.
+type streamWriter struct {
+	send   func(chunk []byte) error
+	buffer []byte
+}
+
+func newStreamWriter(send func(chunk []byte) error) *streamWriter {
+	return &streamWriter{send: send, buffer: make([]byte, 0, chunkSize)}
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := copy(w.buffer[len(w.buffer):cap(w.buffer)], p)
+		w.buffer = w.buffer[:len(w.buffer)+n]
+		p = p[n:]
+		written += n
+
+		if len(w.buffer) == cap(w.buffer) {
+			err := w.Flush()
+			if err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Flush sends the buffered bytes.
+func (w *streamWriter) Flush() error {
+	if len(w.buffer) == 0 {
+		return nil
+	}
+
+	err := w.send(w.buffer)
+	w.buffer = w.buffer[:0]
+	return err
+}
+
+// validateGlob checks the pattern once up front, so a bad pattern fails the
+// request instead of silently matching nothing.
+func validateGlob(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("pattern is empty")
+	}
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == "**" {
+			continue
+		}
+		_, err := path.Match(segment, "")
+		if err != nil {
+			return fmt.Errorf("pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchGlob reports whether the slash separated relative name matches the
+// pattern. A pattern without a slash is matched against the base name.
+func matchGlob(pattern string, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(name))
+		return matched
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// "**" takes any number of directories, including none.
+			for skip := 0; skip <= len(name); skip++ {
+				if matchSegments(pattern[1:], name[skip:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		matched, _ := path.Match(pattern[0], name[0])
+		if !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// archiveFilter selects the files of the archive by their relative names.
+type archiveFilter struct {
+	include []string
+	exclude []string
+}
+
+func newArchiveFilter(include []string, exclude []string) (archiveFilter, error) {
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		err := validateGlob(pattern)
+		if err != nil {
+			return archiveFilter{}, err
+		}
+	}
+
+	return archiveFilter{include: include, exclude: exclude}, nil
+}
+
+func (f archiveFilter) Match(name string) bool {
+	for _, pattern := range f.exclude {
+		if matchGlob(pattern, name) {
+			return false
+		}
+	}
+
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, pattern := range f.include {
+		if matchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// relativeName returns the name of the file in an archive of the directory.
+func relativeName(dir string, file db.File) string {
+	if dir == "" {
+		return path.Join(file.Filepath, file.Filename)
+	}
+	return path.Join(strings.TrimPrefix(strings.TrimPrefix(file.Filepath, dir), "/"), file.Filename)
+}
+
+// ArchiveDirectory streams the files under the directory as an archive built
+// on the fly. The content goes from the storage straight into the stream.
+func (s *FileServer) ArchiveDirectory(in *pb.ArchiveDirectoryRequest, stream pb.File_ArchiveDirectoryServer) error {
+	ctx := stream.Context()
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msgf("receive an ArchiveDirectory request from user %s", caller.Username)
+
+	dir, err := dirPath(in.GetPath())
+	if err != nil {
+		return err
+	}
+
+	filter, err := newArchiveFilter(in.GetInclude(), in.GetExclude())
+	if err != nil {
+		return logError(status.Errorf(codes.InvalidArgument, "invalid filter: %v", err))
+	}
+
+	w := newStreamWriter(func(chunk []byte) error {
+		return stream.Send(&pb.ArchiveDirectoryResponse{ChunkData: chunk})
+	})
+
+	archive, err := newArchiveWriter(in.GetFormat(), w)
+	if err != nil {
+		return logError(status.Errorf(codes.InvalidArgument, "%v", err))
+	}
+
+	var files []db.File
+	if dir == "" {
+		files, err = s.fileStore.ListFiles(ctx, caller.AccountID)
+	} else {
+		files, err = s.fileStore.ListDirectoryFiles(ctx, db.ListDirectoryFilesParams{
+			AccountID: caller.AccountID,
+			Filepath:  dir,
+		})
+	}
+	if err != nil {
+		return logError(status.Errorf(codes.Internal, "cannot list files: %v", err))
+	}
+
+	// Files of a directory end up next to each other in the archive.
+	sort.Slice(files, func(i, j int) bool {
+		return relativeName(dir, files[i]) < relativeName(dir, files[j])
+	})
+
+	archived := 0
+	for _, file := range files {
+		if !file.Ready || !file.BlobHash.Valid {
+			continue
+		}
+
+		name := relativeName(dir, file)
+		if !filter.Match(name) {
+			continue
+		}
+
+		err = s.archiveFile(ctx, archive, name, file)
+		if err != nil {
+			return err
+		}
+		archived++
+	}
+
+	err = archive.Close()
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot send archive: %v", err))
+	}
+
+	log.Info().Msgf("Archived %d files of directory '/%s'", archived, dir)
+	return nil
+}
+
+// archiveFile copies the content of the file into the archive, checking the
+// digest on the way like GetFile does.
+func (s *FileServer) archiveFile(ctx context.Context, archive archiveWriter, name string, file db.File) error {
+	err := contextError(ctx)
+	if err != nil {
+		return err
+	}
+
+	blob, err := s.fileStore.GetBlob(ctx, file.BlobHash.String)
+	if err != nil {
+		return logError(status.Errorf(codes.Internal, "cannot get file blob: %v", err))
+	}
+
+	content, err := s.openBlobContent(ctx, blob, false, 0)
+	if err != nil {
+		if errors.Is(err, errCorrupted) {
+			return logError(status.Errorf(codes.DataLoss, "stored content of '%s' is corrupted: %v", name, err))
+		}
+		return logError(status.Errorf(codes.Internal, "cannot open file content: %v", err))
+	}
+	defer content.Close()
+
+	entry, err := archive.Create(name, file, blob.Size)
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot add '%s' to archive: %v", name, err))
+	}
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(entry, hash), content)
+	if errors.Is(err, errCorrupted) {
+		return logError(status.Errorf(codes.DataLoss, "stored content of '%s' is corrupted: %v", name, err))
+	}
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot add '%s' to archive: %v", name, err))
+	}
+
+	if digest := hex.EncodeToString(hash.Sum(nil)); digest != blob.Hash {
+		return logError(status.Errorf(codes.DataLoss, "stored content of '%s' is corrupted: expected %s, read %s", name, blob.Hash, digest))
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"io"
+	"testing"
+
+	mockdb "github.com/Jay-T/go-devops-advanced-diploma/db/mock"
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMatchGlob(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/server/main.go", true},
+		{"*.go", "main.go.txt", false},
+		{"cmd/*.go", "cmd/main.go", true},
+		{"cmd/*.go", "cmd/server/main.go", false},
+		{"cmd/**/*.go", "cmd/main.go", true},
+		{"cmd/**/*.go", "cmd/server/main.go", true},
+		{"docs/**", "docs/a/b/c.md", true},
+		{"docs/**", "src/docs/c.md", false},
+		{"**/testdata/*", "a/b/testdata/x", true},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, matchGlob(tc.pattern, tc.name), "%s %s", tc.pattern, tc.name)
+	}
+
+	require.Error(t, validateGlob(""))
+	require.Error(t, validateGlob("docs/[a"))
+	require.NoError(t, validateGlob("docs/**/*.md"))
+}
+
+func TestArchiveFilter(t *testing.T) {
+	filter, err := newArchiveFilter([]string{"*.go", "docs/**"}, []string{"*_test.go"})
+	require.NoError(t, err)
+
+	require.True(t, filter.Match("main.go"))
+	require.True(t, filter.Match("docs/readme.md"))
+	require.False(t, filter.Match("main_test.go"))
+	require.False(t, filter.Match("readme.md"))
+
+	filter, err = newArchiveFilter(nil, nil)
+	require.NoError(t, err)
+	require.True(t, filter.Match("anything"))
+}
+
+func TestStreamWriter(t *testing.T) {
+	var chunks [][]byte
+	w := newStreamWriter(func(chunk []byte) error {
+		require.LessOrEqual(t, len(chunk), chunkSize)
+		chunks = append(chunks, append([]byte{}, chunk...))
+		return nil
+	})
+
+	content, _ := randomContent(2*chunkSize + 10)
+	n, err := w.Write(content)
+	require.NoError(t, err)
+	require.Equal(t, len(content), n)
+	require.Len(t, chunks, 2)
+
+	require.NoError(t, w.Flush())
+	require.Len(t, chunks, 3)
+	require.Equal(t, content, bytes.Join(chunks, nil))
+}
+
+type archiveStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	data bytes.Buffer
+}
+
+func (s *archiveStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *archiveStream) Send(res *pb.ArchiveDirectoryResponse) error {
+	s.data.Write(res.GetChunkData())
+	return nil
+}
+
+func TestArchiveDirectory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := principalContext(1, "user")
+	store := mockdb.NewMockStore(ctrl)
+	saver := NewDiskFileContentSaver(t.TempDir())
+	server := NewFileServer(store, saver, Quota{}, CodecIdentity, nil)
+
+	contents := make(map[string][]byte)
+	var files []db.File
+	for _, file := range []db.File{
+		{Filepath: "project", Filename: "main.go"},
+		{Filepath: "project/docs", Filename: "readme.md"},
+		{Filepath: "project", Filename: "main_test.go"},
+	} {
+		content, hash := randomContent(100)
+		require.NoError(t, saver.Save(ctx, hash, bytes.NewReader(content), int64(len(content))))
+
+		file.Ready = true
+		file.BlobHash = sql.NullString{String: hash, Valid: true}
+		files = append(files, file)
+		contents[hash] = content
+
+		store.EXPECT().GetBlob(gomock.Any(), hash).
+			Return(db.Blob{Hash: hash, Size: int64(len(content)), Codec: CodecIdentity}, nil).
+			AnyTimes()
+	}
+	// Files still uploading are left out.
+	files = append(files, db.File{Filepath: "project", Filename: "partial.go"})
+
+	store.EXPECT().
+		ListDirectoryFiles(gomock.Any(), db.ListDirectoryFilesParams{AccountID: 1, Filepath: "project"}).
+		Return(files, nil).
+		AnyTimes()
+
+	expected := map[string][]byte{
+		"docs/readme.md": contents[files[1].BlobHash.String],
+		"main.go":        contents[files[0].BlobHash.String],
+	}
+
+	t.Run("Zip", func(t *testing.T) {
+		stream := &archiveStream{ctx: ctx}
+		err := server.ArchiveDirectory(&pb.ArchiveDirectoryRequest{
+			Path:    "/project/",
+			Exclude: []string{"*_test.go"},
+		}, stream)
+		require.NoError(t, err)
+
+		zr, err := zip.NewReader(bytes.NewReader(stream.data.Bytes()), int64(stream.data.Len()))
+		require.NoError(t, err)
+
+		got := make(map[string][]byte)
+		for _, f := range zr.File {
+			r, err := f.Open()
+			require.NoError(t, err)
+			got[f.Name], err = io.ReadAll(r)
+			require.NoError(t, err)
+			r.Close()
+		}
+		require.Equal(t, expected, got)
+	})
+
+	t.Run("TarGz", func(t *testing.T) {
+		stream := &archiveStream{ctx: ctx}
+		err := server.ArchiveDirectory(&pb.ArchiveDirectoryRequest{
+			Path:    "project",
+			Format:  ArchiveFormatTarGz,
+			Include: []string{"*.go", "docs/**"},
+			Exclude: []string{"*_test.go"},
+		}, stream)
+		require.NoError(t, err)
+
+		gz, err := gzip.NewReader(&stream.data)
+		require.NoError(t, err)
+		tr := tar.NewReader(gz)
+
+		got := make(map[string][]byte)
+		var names []string
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			got[header.Name], err = io.ReadAll(tr)
+			require.NoError(t, err)
+			names = append(names, header.Name)
+		}
+		require.Equal(t, expected, got)
+		require.Equal(t, []string{"docs/readme.md", "main.go"}, names)
+	})
+
+	t.Run("InvalidRequest", func(t *testing.T) {
+		for _, in := range []*pb.ArchiveDirectoryRequest{
+			{Path: "project", Format: "rar"},
+			{Path: "project", Include: []string{"[a"}},
+			{Path: "../project"},
+		} {
+			stream := &archiveStream{ctx: ctx}
+			err := server.ArchiveDirectory(in, stream)
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+			require.Zero(t, stream.data.Len())
+		}
+	})
+}
@@ -2,7 +2,9 @@ package server
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc"
@@ -22,11 +24,29 @@ func (s *serverStream) Context() context.Context {
 
 type AuthInteceptor struct {
 	jwtManager       *JWTManager
+	accountStatus    *AccountStatusCache
+	revocations      *TokenRevocationList
+	certificates     *ClientCertificateCache
 	protectedMethods map[string]bool
+	admins           map[string]bool
 }
 
-func NewAuthInterceptor(jwtManager *JWTManager, protectedMethods map[string]bool) *AuthInteceptor {
-	return &AuthInteceptor{jwtManager: jwtManager, protectedMethods: protectedMethods}
+func NewAuthInterceptor(
+	jwtManager *JWTManager,
+	accountStatus *AccountStatusCache,
+	revocations *TokenRevocationList,
+	certificates *ClientCertificateCache,
+	protectedMethods map[string]bool,
+	admins []string,
+) *AuthInteceptor {
+	return &AuthInteceptor{
+		jwtManager:       jwtManager,
+		accountStatus:    accountStatus,
+		revocations:      revocations,
+		certificates:     certificates,
+		protectedMethods: protectedMethods,
+		admins:           newAdminSet(admins),
+	}
 }
 
 func (interceptor *AuthInteceptor) Unary() grpc.UnaryServerInterceptor {
@@ -72,14 +92,16 @@ func (interceptor *AuthInteceptor) authorize(ctx context.Context, method string)
 		return ctx, nil
 	}
 
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return ctx, status.Errorf(codes.Unauthenticated, "metadata is not provided")
-	}
-
+	// A call with a token goes by the token. Without one a client
+	// certificate verified in the handshake stands in for it.
+	md, _ := metadata.FromIncomingContext(ctx)
 	values := md["authorization"]
 	if len(values) == 0 {
-		return ctx, status.Errorf(codes.Unauthenticated, "authorization token is not provided")
+		cert := verifiedClientCertificate(ctx)
+		if cert == nil || interceptor.certificates == nil {
+			return ctx, status.Errorf(codes.Unauthenticated, "authorization token is not provided")
+		}
+		return interceptor.authorizeCertificate(ctx, method, cert)
 	}
 
 	accessToken := values[0]
@@ -88,9 +110,87 @@ func (interceptor *AuthInteceptor) authorize(ctx context.Context, method string)
 		return ctx, status.Errorf(codes.Unauthenticated, "access token is invalid: %v", err)
 	}
 
-	md.Append("username", claims.Username)
-	ctx = metadata.NewIncomingContext(ctx, md)
+	if interceptor.revocations.IsRevoked(claims.Id) {
+		return ctx, status.Error(codes.Unauthenticated, "access token is revoked")
+	}
+
+	// A block takes the live tokens of the account too.
+	account, err := interceptor.accountStatus.Get(ctx, claims.Username)
+	if err != nil {
+		return ctx, status.Errorf(codes.Internal, "cannot check account: %v", err)
+	}
+	if account.Blocked {
+		return ctx, status.Error(codes.PermissionDenied, "account is blocked")
+	}
+	if claims.SessionEpoch < account.SessionEpoch {
+		return ctx, status.Error(codes.Unauthenticated, "access token is revoked")
+	}
+	// The token of a deleted account must not pass for a new account with
+	// the same username. Tokens without the account ID go by the username.
+	if claims.AccountID != 0 && claims.AccountID != account.ID {
+		return ctx, status.Error(codes.Unauthenticated, "access token is revoked")
+	}
+
+	principal := interceptor.newPrincipal(account.ID, claims.Username)
+	principal.TokenID = claims.Id
+	principal.TokenExpiresAt = time.Unix(claims.ExpiresAt, 0)
+	ctx = contextWithPrincipal(ctx, principal)
 
 	log.Info().Msgf("Request authorized for method: %s, user: %s", method, claims.Username)
 	return ctx, nil
 }
+
+// authorizeCertificate authorizes the call for the account the verified
+// client certificate is mapped to. The certificate is not a session, it
+// keeps working until the mapping is removed or the account blocked.
+func (interceptor *AuthInteceptor) authorizeCertificate(ctx context.Context, method string, cert *x509.Certificate) (context.Context, error) {
+	var mapped certificateAccount
+	found := false
+	for _, identity := range certificateIdentities(cert) {
+		var err error
+		mapped, found, err = interceptor.certificates.Get(ctx, identity)
+		if err != nil {
+			return ctx, status.Errorf(codes.Internal, "cannot check client certificate: %v", err)
+		}
+		if found {
+			break
+		}
+	}
+	if !found {
+		return ctx, status.Errorf(codes.Unauthenticated, "client certificate %s is not mapped to an account", cert.Subject)
+	}
+
+	account, err := interceptor.accountStatus.Get(ctx, mapped.Username)
+	if err != nil {
+		return ctx, status.Errorf(codes.Internal, "cannot check account: %v", err)
+	}
+	if account.Blocked {
+		return ctx, status.Error(codes.PermissionDenied, "account is blocked")
+	}
+	if account.ID != mapped.AccountID {
+		return ctx, status.Errorf(codes.Unauthenticated, "client certificate %s is not mapped to an account", cert.Subject)
+	}
+
+	ctx = contextWithPrincipal(ctx, interceptor.newPrincipal(account.ID, mapped.Username))
+
+	log.Info().Msgf("Request authorized for method: %s, user: %s, client certificate: %s", method, mapped.Username, cert.Subject)
+	return ctx, nil
+}
+
+// newAdminSet returns the set of the usernames configured as admins.
+func newAdminSet(admins []string) map[string]bool {
+	adminSet := make(map[string]bool, len(admins))
+	for _, admin := range admins {
+		adminSet[admin] = true
+	}
+	return adminSet
+}
+
+// newPrincipal returns the caller with the roles the config gives it.
+func (interceptor *AuthInteceptor) newPrincipal(accountID int64, username string) *Principal {
+	principal := &Principal{AccountID: accountID, Username: username}
+	if interceptor.admins[username] {
+		principal.Roles = append(principal.Roles, RoleAdmin)
+	}
+	return principal
+}
@@ -2,7 +2,12 @@ package server
 
 import (
 	"context"
-	"fmt"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
 
 	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
 	pb "github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
@@ -13,41 +18,164 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+const refreshTokenSize = 32
+
 type AuthServer struct {
 	pb.UnimplementedAuthenticationServer
-	accountStore db.Store
-	jwtManager   *JWTManager
+	accountStore         db.Store
+	jwtManager           *JWTManager
+	accountStatus        *AccountStatusCache
+	revocations          *TokenRevocationList
+	loginLimiter         *LoginLimiter
+	twoFactor            *TwoFactor
+	passwordPolicy       PasswordPolicy
+	admins               map[string]bool
+	refreshTokenDuration time.Duration
+}
+
+func NewAuthServer(
+	store db.Store,
+	jwtManager *JWTManager,
+	accountStatus *AccountStatusCache,
+	revocations *TokenRevocationList,
+	loginLimiter *LoginLimiter,
+	twoFactor *TwoFactor,
+	passwordPolicy PasswordPolicy,
+	admins []string,
+	refreshTokenDuration time.Duration,
+) *AuthServer {
+	return &AuthServer{
+		pb.UnimplementedAuthenticationServer{},
+		store,
+		jwtManager,
+		accountStatus,
+		revocations,
+		loginLimiter,
+		twoFactor,
+		passwordPolicy,
+		newAdminSet(admins),
+		refreshTokenDuration,
+	}
+}
+
+// newRefreshToken returns a random refresh token and the hash it is stored by.
+func newRefreshToken() (string, string, error) {
+	raw := make([]byte, refreshTokenSize)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
 }
 
-func NewAuthServer(store db.Store, jwtManager *JWTManager) *AuthServer {
-	return &AuthServer{pb.UnimplementedAuthenticationServer{}, store, jwtManager}
+// issueTokens starts a new refresh token family for the account and returns
+// the access and the refresh token.
+func (s *AuthServer) issueTokens(ctx context.Context, acc *db.Account) (string, string, error) {
+	token, err := s.jwtManager.GeneratetToken(acc)
+	if err != nil {
+		return "", "", status.Errorf(codes.Internal, "cannot generate access token")
+	}
+
+	refreshToken, hash, err := newRefreshToken()
+	if err != nil {
+		return "", "", status.Errorf(codes.Internal, "cannot generate refresh token: %v", err)
+	}
+
+	family := make([]byte, 16)
+	_, err = rand.Read(family)
+	if err != nil {
+		return "", "", status.Errorf(codes.Internal, "cannot generate refresh token: %v", err)
+	}
+
+	_, err = s.accountStore.CreateRefreshToken(ctx, db.CreateRefreshTokenParams{
+		AccountID: acc.ID,
+		FamilyID:  hex.EncodeToString(family),
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.refreshTokenDuration),
+	})
+	if err != nil {
+		return "", "", status.Errorf(codes.Internal, "cannot store refresh token: %v", err)
+	}
+
+	return token, refreshToken, nil
 }
 
 func (s *AuthServer) Login(ctx context.Context, in *pb.LoginRequest) (*pb.LoginResponse, error) {
-	log.Info().Msg(fmt.Sprintf("Got SignIn request for login '%s', password '%s'", in.Login, in.Password))
+	log.Info().Msgf("Got SignIn request for login '%s'", in.Login)
 
-	acc, err := s.accountStore.GetAccount(ctx, in.Login)
+	ip := peerIP(ctx)
+	now := time.Now()
+	attempt, err := s.loginLimiter.Reserve(ctx, in.Login, ip, now)
 	if err != nil {
+		return nil, err
+	}
+	defer attempt.Release(ctx)
+
+	acc, err := s.accountStore.GetAccount(ctx, in.Login)
+	if err != nil && err != sql.ErrNoRows {
 		return nil, status.Errorf(codes.Internal, "cannot find user: %v", err)
 	}
 
-	if !acc.IsCorrectPassword(in.Password) {
+	// An unknown username fails like a wrong password, so guessing cannot
+	// tell them apart.
+	if err == sql.ErrNoRows || !acc.IsCorrectPassword(in.Password) {
+		attempt.Failed()
 		return nil, status.Error(codes.NotFound, "username/password incorrect")
 	}
 
-	token, err := s.jwtManager.GeneratetToken(&acc)
+	if acc.Blocked {
+		return nil, logError(status.Error(codes.PermissionDenied, "account is blocked"))
+	}
+
+	// The failures are kept until the second factor is right too, knowing
+	// the password does not buy more attempts at the code.
+	enabled, err := s.twoFactor.Enabled(ctx, acc.ID)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "cannot generate access token")
+		return nil, logError(status.Errorf(codes.Internal, "cannot check two-factor authentication: %v", err))
+	}
+	if enabled {
+		challenge, err := s.twoFactor.NewChallenge(ctx, acc.ID, now)
+		if err != nil {
+			return nil, logError(status.Errorf(codes.Internal, "cannot create login challenge: %v", err))
+		}
+		return &pb.LoginResponse{Login: acc.Username, Challenge: challenge}, nil
+	}
+
+	attempt.Succeeded(ctx)
+
+	token, refreshToken, err := s.issueTokens(ctx, &acc)
+	if err != nil {
+		return nil, err
 	}
 
 	return &pb.LoginResponse{
-		Login: acc.Username,
-		Token: token,
+		Login:        acc.Username,
+		Token:        token,
+		RefreshToken: refreshToken,
 	}, nil
 }
 
 func (s *AuthServer) Register(ctx context.Context, in *pb.RegisterRequest) (*pb.RegisterResponse, error) {
-	log.Info().Msg(fmt.Sprintf("Got SignUp request for login '%s', password '%s'", in.Login, in.Password))
+	log.Info().Msgf("Got SignUp request for login '%s'", in.Login)
+
+	// The admin role goes with the username, so a configured admin name
+	// nobody holds yet must not be taken by whoever registers it first.
+	// Admins register before their names are added to the config.
+	if s.admins[in.Login] {
+		return nil, logError(status.Errorf(codes.PermissionDenied, "username %s is reserved", in.Login))
+	}
+
+	err := s.passwordPolicy.Validate(in.Login, in.Password)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.InvalidArgument, "password is too weak: %v", err))
+	}
 
 	hash, err := util.HashPassword(in.Password)
 	if err != nil {
@@ -70,13 +198,426 @@ func (s *AuthServer) Register(ctx context.Context, in *pb.RegisterRequest) (*pb.
 		return nil, status.Errorf(codes.Internal, "failed to create user: %s", err)
 	}
 
-	token, err := s.jwtManager.GeneratetToken(&newAcc)
+	token, refreshToken, err := s.issueTokens(ctx, &newAcc)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "cannot generate access token")
+		return nil, err
 	}
 
 	return &pb.RegisterResponse{
-		Login: newAcc.Username,
-		Token: token,
+		Login:        newAcc.Username,
+		Token:        token,
+		RefreshToken: refreshToken,
 	}, nil
 }
+
+// RefreshToken exchanges the refresh token for a new pair of tokens. Each
+// refresh token works once, the client has to keep the returned one.
+func (s *AuthServer) RefreshToken(ctx context.Context, in *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	newRefreshToken, hash, err := newRefreshToken()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot generate refresh token: %v", err)
+	}
+
+	now := time.Now()
+	result, err := s.accountStore.RotateRefreshTokenTx(ctx, db.RotateRefreshTokenTxParams{
+		TokenHash:    hashRefreshToken(in.GetRefreshToken()),
+		NewTokenHash: hash,
+		ExpiresAt:    now.Add(s.refreshTokenDuration),
+		Now:          now,
+	})
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows, db.ErrRefreshTokenExpired, db.ErrRefreshTokenRevoked:
+			return nil, logError(status.Error(codes.Unauthenticated, "refresh token is invalid"))
+		case db.ErrRefreshTokenReused:
+			return nil, logError(status.Error(codes.Unauthenticated, "refresh token is reused, all tokens of the session are revoked"))
+		case db.ErrAccountBlocked:
+			return nil, logError(status.Error(codes.PermissionDenied, "account is blocked"))
+		}
+		return nil, logError(status.Errorf(codes.Internal, "cannot rotate refresh token: %v", err))
+	}
+
+	token, err := s.jwtManager.GeneratetToken(&result.Account)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot generate access token")
+	}
+
+	return &pb.RefreshTokenResponse{
+		Login:        result.Account.Username,
+		Token:        token,
+		RefreshToken: newRefreshToken,
+	}, nil
+}
+
+// RunRefreshTokenCleanup deletes expired refresh tokens every interval until
+// ctx is done. Rotation leaves a row per refresh behind.
+func (s *AuthServer) RunRefreshTokenCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.accountStore.DeleteExpiredRefreshTokens(ctx, time.Now())
+			if err != nil {
+				log.Error().Err(err).Msg("cannot delete expired refresh tokens")
+			}
+			if deleted > 0 {
+				log.Info().Msgf("Deleted %d expired refresh tokens", deleted)
+			}
+		}
+	}
+}
+
+// getCallerAccount returns the account of the caller.
+func (s *AuthServer) getCallerAccount(ctx context.Context) (*Principal, db.Account, error) {
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, db.Account{}, err
+	}
+
+	acc, err := s.accountStore.GetAccountByID(ctx, caller.AccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, db.Account{}, logError(status.Errorf(codes.NotFound, "cannot find account %s", caller.Username))
+		}
+		return nil, db.Account{}, logError(status.Errorf(codes.Internal, "cannot get account from db. Err :%s", err))
+	}
+
+	return caller, acc, nil
+}
+
+// Logout revokes the access token of the call. The refresh token of the
+// request is revoked with the whole family, so the session cannot be renewed.
+func (s *AuthServer) Logout(ctx context.Context, in *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if caller.TokenID == "" {
+		return nil, logError(status.Error(codes.FailedPrecondition, "call is not authorized with an access token"))
+	}
+
+	if in.GetRefreshToken() != "" {
+		// A token of another account or an unknown one revokes nothing.
+		_, err = s.accountStore.RevokeRefreshTokenFamilyOfToken(ctx, db.RevokeRefreshTokenFamilyOfTokenParams{
+			TokenHash: hashRefreshToken(in.GetRefreshToken()),
+			AccountID: caller.AccountID,
+		})
+		if err != nil {
+			return nil, logError(status.Errorf(codes.Internal, "cannot revoke refresh token: %v", err))
+		}
+	}
+
+	err = s.revocations.Revoke(ctx, caller.TokenID, caller.AccountID, caller.TokenExpiresAt)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot revoke access token: %v", err))
+	}
+
+	log.Info().Msgf("User %s logged out", caller.Username)
+	return &pb.LogoutResponse{}, nil
+}
+
+// RevokeAllSessions revokes every access and refresh token of the account,
+// including the one of the call. The other server instances stop accepting
+// the access tokens within accountStatusTTL.
+func (s *AuthServer) RevokeAllSessions(ctx context.Context, in *pb.RevokeAllSessionsRequest) (*pb.RevokeAllSessionsResponse, error) {
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.accountStore.RevokeAllSessionsTx(ctx, db.RevokeAllSessionsTxParams{AccountID: caller.AccountID})
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot revoke sessions: %v", err))
+	}
+	s.accountStatus.Invalidate(caller.Username)
+
+	log.Info().Msgf("All sessions of user %s revoked", caller.Username)
+	return &pb.RevokeAllSessionsResponse{}, nil
+}
+
+// ChangePassword replaces the password of the caller. Every session of the
+// account is revoked, the caller goes on with the returned tokens.
+func (s *AuthServer) ChangePassword(ctx context.Context, in *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	_, acc, err := s.getCallerAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkCallerPassword(ctx, acc, in.GetOldPassword(), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if in.GetNewPassword() == in.GetOldPassword() {
+		return nil, logError(status.Error(codes.InvalidArgument, "new password is the same as the old one"))
+	}
+
+	err = s.passwordPolicy.Validate(acc.Username, in.GetNewPassword())
+	if err != nil {
+		return nil, logError(status.Errorf(codes.InvalidArgument, "password is too weak: %v", err))
+	}
+
+	hash, err := util.HashPassword(in.GetNewPassword())
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot hash password: %v", err))
+	}
+
+	acc, err = s.accountStore.ChangePasswordTx(ctx, db.ChangePasswordTxParams{
+		AccountID: acc.ID,
+		Passhash:  hash,
+	})
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot change password: %v", err))
+	}
+	s.accountStatus.Invalidate(acc.Username)
+
+	// The tokens carry the new session epoch, they outlive the revocation.
+	token, refreshToken, err := s.issueTokens(ctx, &acc)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Msgf("User %s changed the password", acc.Username)
+	return &pb.ChangePasswordResponse{
+		Login:        acc.Username,
+		Token:        token,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// checkCallerPassword verifies the password before a change of the
+// credentials. A stolen access token must not help guessing the password, so
+// the failures count like failed logins.
+func (s *AuthServer) checkCallerPassword(ctx context.Context, acc db.Account, password string, now time.Time) error {
+	attempt, err := s.loginLimiter.Reserve(ctx, acc.Username, peerIP(ctx), now)
+	if err != nil {
+		return err
+	}
+	defer attempt.Release(ctx)
+
+	if !acc.IsCorrectPassword(password) {
+		attempt.Failed()
+		return logError(status.Error(codes.PermissionDenied, "password is incorrect"))
+	}
+
+	return nil
+}
+
+// checkCallerCode verifies a code of the app or a recovery code before a
+// change of the second factor, the failures count like failed logins.
+func (s *AuthServer) checkCallerCode(ctx context.Context, acc db.Account, code string, now time.Time) error {
+	enabled, err := s.twoFactor.Enabled(ctx, acc.ID)
+	if err != nil {
+		return logError(status.Errorf(codes.Internal, "cannot check two-factor authentication: %v", err))
+	}
+	if !enabled {
+		return logError(status.Error(codes.FailedPrecondition, "two-factor authentication is not enabled"))
+	}
+
+	attempt, err := s.loginLimiter.Reserve(ctx, acc.Username, peerIP(ctx), now)
+	if err != nil {
+		return err
+	}
+	defer attempt.Release(ctx)
+
+	ok, err := s.twoFactor.Verify(ctx, acc.ID, code, now)
+	if err != nil {
+		return logError(status.Errorf(codes.Internal, "cannot verify code: %v", err))
+	}
+	if !ok {
+		attempt.Failed()
+		return logError(status.Error(codes.PermissionDenied, "code is incorrect"))
+	}
+
+	return nil
+}
+
+// CompleteLogin finishes a login of an account with two-factor
+// authentication. The tokens are only issued here.
+func (s *AuthServer) CompleteLogin(ctx context.Context, in *pb.CompleteLoginRequest) (*pb.CompleteLoginResponse, error) {
+	now := time.Now()
+	challenge, err := s.twoFactor.AttemptChallenge(ctx, in.GetChallenge(), now)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, logError(status.Error(codes.Unauthenticated, "login challenge is invalid or expired"))
+		}
+		return nil, logError(status.Errorf(codes.Internal, "cannot check login challenge: %v", err))
+	}
+
+	acc, err := s.accountStore.GetAccountByID(ctx, challenge.AccountID)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot get account from db. Err :%s", err))
+	}
+
+	attempt, err := s.loginLimiter.Reserve(ctx, acc.Username, peerIP(ctx), now)
+	if err != nil {
+		return nil, err
+	}
+	defer attempt.Release(ctx)
+
+	ok, err := s.twoFactor.Verify(ctx, acc.ID, in.GetCode(), now)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot verify code: %v", err))
+	}
+	if !ok {
+		attempt.Failed()
+		return nil, logError(status.Error(codes.Unauthenticated, "code is incorrect"))
+	}
+
+	err = s.accountStore.DeleteLoginChallenge(ctx, challenge.ID)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot delete login challenge: %v", err))
+	}
+
+	if acc.Blocked {
+		return nil, logError(status.Error(codes.PermissionDenied, "account is blocked"))
+	}
+
+	attempt.Succeeded(ctx)
+
+	token, refreshToken, err := s.issueTokens(ctx, &acc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CompleteLoginResponse{
+		Login:        acc.Username,
+		Token:        token,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// Enroll2FA hands out a new TOTP secret with recovery codes. The login asks
+// for a code once Confirm2FA took the first one.
+func (s *AuthServer) Enroll2FA(ctx context.Context, in *pb.Enroll2FARequest) (*pb.Enroll2FAResponse, error) {
+	_, acc, err := s.getCallerAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkCallerPassword(ctx, acc, in.GetPassword(), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment, err := s.twoFactor.Enroll(ctx, acc)
+	if err != nil {
+		if err == db.ErrTotpEnabled {
+			return nil, logError(status.Error(codes.FailedPrecondition, "two-factor authentication is already enabled"))
+		}
+		return nil, logError(status.Errorf(codes.Internal, "cannot enroll two-factor authentication: %v", err))
+	}
+
+	log.Info().Msgf("User %s enrolled two-factor authentication", acc.Username)
+	return &pb.Enroll2FAResponse{
+		ProvisioningUri: enrollment.ProvisioningURI,
+		Secret:          enrollment.Secret,
+		RecoveryCodes:   enrollment.RecoveryCodes,
+	}, nil
+}
+
+// Confirm2FA enables two-factor authentication with the first code of the
+// enrolled secret.
+func (s *AuthServer) Confirm2FA(ctx context.Context, in *pb.Confirm2FARequest) (*pb.Confirm2FAResponse, error) {
+	_, acc, err := s.getCallerAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.twoFactor.Confirm(ctx, acc.ID, in.GetCode(), time.Now())
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot confirm two-factor authentication: %v", err))
+	}
+	if !ok {
+		return nil, logError(status.Error(codes.InvalidArgument, "code is incorrect or there is no enrollment to confirm"))
+	}
+
+	log.Info().Msgf("User %s enabled two-factor authentication", acc.Username)
+	return &pb.Confirm2FAResponse{}, nil
+}
+
+// Disable2FA turns two-factor authentication off, it takes the password and
+// a code of the app or a recovery code.
+func (s *AuthServer) Disable2FA(ctx context.Context, in *pb.Disable2FARequest) (*pb.Disable2FAResponse, error) {
+	_, acc, err := s.getCallerAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.checkCallerPassword(ctx, acc, in.GetPassword(), now)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkCallerCode(ctx, acc, in.GetCode(), now)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.twoFactor.Disable(ctx, acc.ID)
+	if err != nil {
+		if err == db.ErrTotpNotEnabled {
+			return nil, logError(status.Error(codes.FailedPrecondition, "two-factor authentication is not enabled"))
+		}
+		return nil, logError(status.Errorf(codes.Internal, "cannot disable two-factor authentication: %v", err))
+	}
+
+	log.Info().Msgf("User %s disabled two-factor authentication", acc.Username)
+	return &pb.Disable2FAResponse{}, nil
+}
+
+// RegenerateRecoveryCodes replaces all the recovery codes, e.g. once most of
+// them are used or they may have leaked. It takes the password and a code of
+// the app or a recovery code.
+func (s *AuthServer) RegenerateRecoveryCodes(ctx context.Context, in *pb.RegenerateRecoveryCodesRequest) (*pb.RegenerateRecoveryCodesResponse, error) {
+	_, acc, err := s.getCallerAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.checkCallerPassword(ctx, acc, in.GetPassword(), now)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkCallerCode(ctx, acc, in.GetCode(), now)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := s.twoFactor.RegenerateRecoveryCodes(ctx, acc.ID)
+	if err != nil {
+		if err == db.ErrTotpNotEnabled {
+			return nil, logError(status.Error(codes.FailedPrecondition, "two-factor authentication is not enabled"))
+		}
+		return nil, logError(status.Errorf(codes.Internal, "cannot regenerate recovery codes: %v", err))
+	}
+
+	log.Info().Msgf("User %s regenerated the recovery codes", acc.Username)
+	return &pb.RegenerateRecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// GetPublicKeys returns the keys other services verify the access tokens with.
+func (s *AuthServer) GetPublicKeys(ctx context.Context, in *pb.GetPublicKeysRequest) (*pb.GetPublicKeysResponse, error) {
+	res := &pb.GetPublicKeysResponse{}
+	for _, key := range s.jwtManager.PublicKeys() {
+		res.Keys = append(res.Keys, &pb.PublicKey{
+			Kty: key.KeyType,
+			Kid: key.KeyID,
+			Alg: key.Algorithm,
+			Use: key.Use,
+			N:   key.N,
+			E:   key.E,
+			Crv: key.Curve,
+			X:   key.X,
+		})
+	}
+
+	return res, nil
+}
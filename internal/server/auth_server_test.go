@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/Jay-T/go-devops-advanced-diploma/db/mock"
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRefreshToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	jwtManager := newTestJWTManager(t, time.Minute)
+	server := NewAuthServer(store, jwtManager, NewAccountStatusCache(store, time.Minute), NewTokenRevocationList(store), NewLoginLimiter(store, LoginLimits{}), NewTwoFactor(store, nil, "test"), PasswordPolicy{}, nil, time.Hour)
+
+	account := db.Account{ID: 1, Username: "user"}
+	var stored string
+	store.EXPECT().
+		RotateRefreshTokenTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.RotateRefreshTokenTxParams) (db.RotateRefreshTokenTxResult, error) {
+			require.Equal(t, hashRefreshToken("old"), arg.TokenHash)
+			require.True(t, arg.ExpiresAt.After(arg.Now))
+			stored = arg.NewTokenHash
+			return db.RotateRefreshTokenTxResult{Account: account}, nil
+		})
+
+	res, err := server.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: "old"})
+	require.NoError(t, err)
+	require.Equal(t, "user", res.GetLogin())
+	// Only the hash of the new token reaches the database.
+	require.Equal(t, stored, hashRefreshToken(res.GetRefreshToken()))
+
+	claims, err := jwtManager.Verify(res.GetToken())
+	require.NoError(t, err)
+	require.Equal(t, "user", claims.Username)
+
+	store.EXPECT().
+		RotateRefreshTokenTx(gomock.Any(), gomock.Any()).
+		Return(db.RotateRefreshTokenTxResult{}, db.ErrRefreshTokenReused)
+
+	_, err = server.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: "old"})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	store.EXPECT().
+		RotateRefreshTokenTx(gomock.Any(), gomock.Any()).
+		Return(db.RotateRefreshTokenTxResult{}, db.ErrAccountBlocked)
+
+	_, err = server.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: "old"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestRegisterPasswordPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	policy := PasswordPolicy{MinLength: 8, MinClasses: 2}
+	server := NewAuthServer(store, newTestJWTManager(t, time.Minute), NewAccountStatusCache(store, time.Minute), NewTokenRevocationList(store), NewLoginLimiter(store, LoginLimits{}), NewTwoFactor(store, nil, "test"), policy, nil, time.Hour)
+
+	// A refused password never reaches the database.
+	for _, password := range []string{"", "short1", "password1"} {
+		_, err := server.Register(context.Background(), &pb.RegisterRequest{Login: "user", Password: password})
+		require.Equal(t, codes.InvalidArgument, status.Code(err), password)
+	}
+
+	store.EXPECT().
+		CreateAccount(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.CreateAccountParams) (db.Account, error) {
+			return db.Account{ID: 1, Username: arg.Username, Passhash: arg.Passhash}, nil
+		})
+	store.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(db.RefreshToken{}, nil)
+
+	res, err := server.Register(context.Background(), &pb.RegisterRequest{Login: "user", Password: "correct horse 1"})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.GetToken())
+}
+
+func TestRegisterAdminName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := NewAuthServer(store, newTestJWTManager(t, time.Minute), NewAccountStatusCache(store, time.Minute), NewTokenRevocationList(store), NewLoginLimiter(store, LoginLimits{}), NewTwoFactor(store, nil, "test"), PasswordPolicy{}, []string{"admin"}, time.Hour)
+
+	// A configured admin name never reaches the database.
+	_, err := server.Register(context.Background(), &pb.RegisterRequest{Login: "admin", Password: "correct horse 1"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	jwtManager := newTestJWTManager(t, time.Minute)
+	accountStatus := NewAccountStatusCache(store, time.Hour)
+	revocations := NewTokenRevocationList(store)
+	interceptor := NewAuthInterceptor(jwtManager, accountStatus, revocations, nil, map[string]bool{"/test/Method": true}, nil)
+	policy := PasswordPolicy{MinLength: 8, MinClasses: 2}
+	server := NewAuthServer(store, jwtManager, accountStatus, revocations, NewLoginLimiter(store, LoginLimits{MaxFailures: 3}), NewTwoFactor(store, nil, "test"), policy, nil, time.Hour)
+
+	hash, err := util.HashPassword("old secret 1")
+	require.NoError(t, err)
+	account := db.Account{ID: 4, Username: "user", Passhash: hash}
+	store.EXPECT().
+		GetAccount(gomock.Any(), "user").
+		DoAndReturn(func(context.Context, string) (db.Account, error) { return account, nil }).
+		AnyTimes()
+	store.EXPECT().
+		GetAccountByID(gomock.Any(), account.ID).
+		DoAndReturn(func(context.Context, int64) (db.Account, error) { return account, nil }).
+		AnyTimes()
+
+	token, err := jwtManager.GeneratetToken(&account)
+	require.NoError(t, err)
+	ctx, err := interceptor.authorize(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", token)), "/test/Method")
+	require.NoError(t, err)
+
+	// A wrong old password counts as a failed login, the attempt is not
+	// taken back.
+	reserveLogin := func(_ context.Context, arg db.ReserveLoginAttemptTxParams) (db.ReserveLoginAttemptTxResult, error) {
+		return reservedLogin(arg, 1), nil
+	}
+	store.EXPECT().ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).DoAndReturn(reserveLogin)
+	_, err = server.ChangePassword(ctx, &pb.ChangePasswordRequest{OldPassword: "wrong", NewPassword: "new secret 2"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	store.EXPECT().ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).DoAndReturn(reserveLogin).AnyTimes()
+	store.EXPECT().UndoLoginFailure(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	for _, password := range []string{"old secret 1", "short", "password1"} {
+		_, err = server.ChangePassword(ctx, &pb.ChangePasswordRequest{OldPassword: "old secret 1", NewPassword: password})
+		require.Equal(t, codes.InvalidArgument, status.Code(err), password)
+	}
+
+	store.EXPECT().
+		ChangePasswordTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.ChangePasswordTxParams) (db.Account, error) {
+			require.Equal(t, account.ID, arg.AccountID)
+			account.Passhash = arg.Passhash
+			account.SessionEpoch++
+			return account, nil
+		})
+	store.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(db.RefreshToken{}, nil)
+
+	res, err := server.ChangePassword(ctx, &pb.ChangePasswordRequest{OldPassword: "old secret 1", NewPassword: "new secret 2"})
+	require.NoError(t, err)
+	require.True(t, account.IsCorrectPassword("new secret 2"))
+
+	// The old access token is revoked, the returned one works.
+	_, err = interceptor.authorize(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", token)), "/test/Method")
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = interceptor.authorize(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", res.GetToken())), "/test/Method")
+	require.NoError(t, err)
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// The identities of a client certificate an account can be mapped by. The
+// subject is the distinguished name as printed by x509.Certificate.Subject,
+// e.g. "subject:CN=ci-agent,O=Example".
+const (
+	certIdentitySubject = "subject:"
+	certIdentityDNS     = "dns:"
+	certIdentityEmail   = "email:"
+	certIdentityURI     = "uri:"
+)
+
+// NewServerTLSConfig returns the TLS config of the gRPC listener, nil when
+// no certificate is configured and the server speaks plaintext. With a
+// client CA bundle the clients may present a certificate signed by it, the
+// ones without a certificate log in with a token as before.
+func NewServerTLSConfig(cfg *Config) (*tls.Config, error) {
+	if cfg.TLSCertFile == "" && cfg.TLSKeyFile == "" {
+		if cfg.TLSClientCAFile != "" {
+			return nil, fmt.Errorf("client certificates need the server certificate and key")
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load server certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if cfg.TLSClientCAFile != "" {
+		bundle, err := os.ReadFile(cfg.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read client CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("client CA bundle %s has no certificates", cfg.TLSClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return tlsConfig, nil
+}
+
+// verifiedClientCertificate returns the client certificate of the call if
+// the TLS handshake verified it against the client CA bundle.
+func verifiedClientCertificate(ctx context.Context) *x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return tlsInfo.State.VerifiedChains[0][0]
+}
+
+// certificateIdentities lists the identities of the certificate in the order
+// they are looked up: the SANs first, the subject last.
+func certificateIdentities(cert *x509.Certificate) []string {
+	var identities []string
+	for _, uri := range cert.URIs {
+		identities = append(identities, certIdentityURI+uri.String())
+	}
+	for _, name := range cert.DNSNames {
+		identities = append(identities, certIdentityDNS+name)
+	}
+	for _, email := range cert.EmailAddresses {
+		identities = append(identities, certIdentityEmail+email)
+	}
+	if subject := cert.Subject.String(); subject != "" {
+		identities = append(identities, certIdentitySubject+subject)
+	}
+	return identities
+}
+
+// validateCertificateIdentity checks an identity before it is mapped to an
+// account.
+func validateCertificateIdentity(identity string) error {
+	for _, prefix := range []string{certIdentitySubject, certIdentityDNS, certIdentityEmail, certIdentityURI} {
+		if strings.HasPrefix(identity, prefix) {
+			if strings.TrimSpace(identity[len(prefix):]) == "" {
+				return fmt.Errorf("identity %q has no value", identity)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("identity %q must start with %s, %s, %s or %s", identity, certIdentitySubject, certIdentityDNS, certIdentityEmail, certIdentityURI)
+}
+
+// certificateAccount is the account a certificate identity is mapped to.
+type certificateAccount struct {
+	AccountID int64
+	Username  string
+}
+
+type certificateEntry struct {
+	account   certificateAccount
+	found     bool
+	checkedAt time.Time
+}
+
+// ClientCertificateCache maps the certificate identities to accounts, so the
+// calls authorized by a certificate do not all hit the database. A removed
+// mapping reaches the other server instances within the ttl.
+type ClientCertificateCache struct {
+	store db.Store
+	ttl   time.Duration
+
+	mu         sync.Mutex
+	identities map[string]certificateEntry
+}
+
+func NewClientCertificateCache(store db.Store, ttl time.Duration) *ClientCertificateCache {
+	return &ClientCertificateCache{
+		store:      store,
+		ttl:        ttl,
+		identities: make(map[string]certificateEntry),
+	}
+}
+
+// Get returns the account the identity is mapped to, found is false for an
+// identity of no account.
+func (c *ClientCertificateCache) Get(ctx context.Context, identity string) (account certificateAccount, found bool, err error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.identities[identity]
+	c.mu.Unlock()
+	if ok && now.Sub(entry.checkedAt) < c.ttl {
+		return entry.account, entry.found, nil
+	}
+
+	row, err := c.store.GetClientCertificateAccount(ctx, identity)
+	if err != nil && err != sql.ErrNoRows {
+		return certificateAccount{}, false, err
+	}
+	entry = certificateEntry{
+		account:   certificateAccount{AccountID: row.AccountID, Username: row.Username},
+		found:     err == nil,
+		checkedAt: now,
+	}
+
+	c.mu.Lock()
+	for name, cached := range c.identities {
+		if now.Sub(cached.checkedAt) >= c.ttl {
+			delete(c.identities, name)
+		}
+	}
+	c.identities[identity] = entry
+	c.mu.Unlock()
+
+	return entry.account, entry.found, nil
+}
+
+// Invalidate drops the cached mapping, the next call reads the database.
+func (c *ClientCertificateCache) Invalidate(identity string) {
+	c.mu.Lock()
+	delete(c.identities, identity)
+	c.mu.Unlock()
+}
@@ -0,0 +1,344 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mockdb "github.com/Jay-T/go-devops-advanced-diploma/db/mock"
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert, key}
+}
+
+// issue signs a certificate for the template, the serial and validity are
+// filled in.
+func (ca *testCA) issue(t *testing.T, template *x509.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func writePEM(t *testing.T, name string, blockType string, der []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+// writeServerTLSFiles writes the certificate of the server and the client CA
+// bundle the way the config refers to them.
+func writeServerTLSFiles(t *testing.T, ca *testCA) *Config {
+	cert := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "localhost"},
+		DNSNames:    []string{"localhost"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	require.NoError(t, err)
+
+	return &Config{
+		TLSCertFile:     writePEM(t, "server.crt", "CERTIFICATE", cert.Certificate[0]),
+		TLSKeyFile:      writePEM(t, "server.key", "PRIVATE KEY", key),
+		TLSClientCAFile: writePEM(t, "ca.crt", "CERTIFICATE", ca.cert.Raw),
+	}
+}
+
+func TestCertificateIdentities(t *testing.T) {
+	uri, err := url.Parse("spiffe://example.org/ci")
+	require.NoError(t, err)
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "agent", Organization: []string{"Example"}},
+		DNSNames:       []string{"agent.ci.internal"},
+		EmailAddresses: []string{"ci@example.org"},
+		URIs:           []*url.URL{uri},
+	}
+
+	require.Equal(t, []string{
+		"uri:spiffe://example.org/ci",
+		"dns:agent.ci.internal",
+		"email:ci@example.org",
+		"subject:CN=agent,O=Example",
+	}, certificateIdentities(cert))
+
+	for _, identity := range certificateIdentities(cert) {
+		require.NoError(t, validateCertificateIdentity(identity))
+	}
+	for _, identity := range []string{"", "agent", "dns:", "subject: ", "ip:10.0.0.1"} {
+		require.Error(t, validateCertificateIdentity(identity), identity)
+	}
+}
+
+func TestNewServerTLSConfig(t *testing.T) {
+	tlsConfig, err := NewServerTLSConfig(&Config{})
+	require.NoError(t, err)
+	require.Nil(t, tlsConfig)
+
+	_, err = NewServerTLSConfig(&Config{TLSClientCAFile: "ca.crt"})
+	require.Error(t, err)
+
+	cfg := writeServerTLSFiles(t, newTestCA(t))
+	tlsConfig, err = NewServerTLSConfig(cfg)
+	require.NoError(t, err)
+	require.Equal(t, tls.VerifyClientCertIfGiven, tlsConfig.ClientAuth)
+	require.NotNil(t, tlsConfig.ClientCAs)
+
+	// Without the bundle the clients are not asked for a certificate.
+	cfg.TLSClientCAFile = ""
+	tlsConfig, err = NewServerTLSConfig(cfg)
+	require.NoError(t, err)
+	require.Equal(t, tls.NoClientCert, tlsConfig.ClientAuth)
+
+	cfg.TLSClientCAFile = cfg.TLSKeyFile
+	_, err = NewServerTLSConfig(cfg)
+	require.Error(t, err)
+}
+
+func TestClientCertificateLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ca := newTestCA(t)
+	cfg := writeServerTLSFiles(t, ca)
+	tlsConfig, err := NewServerTLSConfig(cfg)
+	require.NoError(t, err)
+
+	store := mockdb.NewMockStore(ctrl)
+	jwtManager := newTestJWTManager(t, time.Minute)
+	accountStatus := NewAccountStatusCache(store, time.Minute)
+	certificates := NewClientCertificateCache(store, time.Minute)
+	interceptor := NewAuthInterceptor(jwtManager, accountStatus, NewTokenRevocationList(store), certificates, protectedMethods(), []string{"admin"})
+
+	server := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(tlsConfig)),
+		grpc.UnaryInterceptor(interceptor.Unary()),
+	)
+	pb.RegisterAdminServer(server, NewAdminServer(store, nil, accountStatus, certificates))
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	dial := func(certs ...tls.Certificate) pb.AdminClient {
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.cert)
+		conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			ServerName:   "localhost",
+			RootCAs:      roots,
+			Certificates: certs,
+		})))
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return pb.NewAdminClient(conn)
+	}
+
+	admin := db.Account{ID: 1, Username: "admin"}
+	store.EXPECT().GetAccount(gomock.Any(), "admin").Return(admin, nil).AnyTimes()
+
+	// The SAN of the agent is mapped to the admin account.
+	agent := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "agent"},
+		DNSNames:    []string{"agent.ci.internal"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	store.EXPECT().
+		GetClientCertificateAccount(gomock.Any(), "dns:agent.ci.internal").
+		Return(db.GetClientCertificateAccountRow{AccountID: admin.ID, Username: admin.Username}, nil)
+	store.EXPECT().
+		ListAccounts(gomock.Any(), gomock.Any()).
+		Return([]db.Account{admin}, nil).
+		Times(2)
+
+	client := dial(agent)
+	res, err := client.ListAccounts(context.Background(), &pb.ListAccountsRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.GetAccounts(), 1)
+
+	// The mapping is cached.
+	_, err = client.ListAccounts(context.Background(), &pb.ListAccountsRequest{})
+	require.NoError(t, err)
+
+	// A certificate of the CA nobody is mapped to gets no account.
+	stranger := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "stranger"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	store.EXPECT().
+		GetClientCertificateAccount(gomock.Any(), "subject:CN=stranger").
+		Return(db.GetClientCertificateAccountRow{}, sql.ErrNoRows)
+	_, err = dial(stranger).ListAccounts(context.Background(), &pb.ListAccountsRequest{})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Without a certificate the call needs a token as before.
+	anonymous := dial()
+	_, err = anonymous.ListAccounts(context.Background(), &pb.ListAccountsRequest{})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	token, err := jwtManager.GeneratetToken(&admin)
+	require.NoError(t, err)
+	store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Return([]db.Account{admin}, nil)
+	_, err = anonymous.ListAccounts(metadata.AppendToOutgoingContext(context.Background(), "authorization", token), &pb.ListAccountsRequest{})
+	require.NoError(t, err)
+
+	// The handshake refuses a certificate of another CA.
+	forged := newTestCA(t).issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "agent"},
+		DNSNames:    []string{"agent.ci.internal"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	_, err = dial(forged).ListAccounts(context.Background(), &pb.ListAccountsRequest{})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestClientCertificateBlockedAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	certificates := NewClientCertificateCache(store, time.Minute)
+	interceptor := NewAuthInterceptor(newTestJWTManager(t, time.Minute), NewAccountStatusCache(store, time.Minute), NewTokenRevocationList(store), certificates, nil, nil)
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "agent"}}
+
+	store.EXPECT().
+		GetClientCertificateAccount(gomock.Any(), "subject:CN=agent").
+		Return(db.GetClientCertificateAccountRow{AccountID: 2, Username: "user"}, nil).
+		Times(2)
+	store.EXPECT().GetAccount(gomock.Any(), "user").Return(db.Account{ID: 2, Username: "user", Blocked: true}, nil)
+	_, err := interceptor.authorizeCertificate(context.Background(), "/test/Method", cert)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// A mapping of a deleted account does not pass for a new one with the
+	// same username.
+	certificates.Invalidate("subject:CN=agent")
+	interceptor.accountStatus.Invalidate("user")
+	store.EXPECT().GetAccount(gomock.Any(), "user").Return(db.Account{ID: 3, Username: "user"}, nil)
+	_, err = interceptor.authorizeCertificate(context.Background(), "/test/Method", cert)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// A certificate call has no access token to log out.
+	server := NewAuthServer(store, newTestJWTManager(t, time.Minute), NewAccountStatusCache(store, time.Minute), NewTokenRevocationList(store), NewLoginLimiter(store, LoginLimits{}), NewTwoFactor(store, nil, "test"), PasswordPolicy{}, nil, time.Hour)
+	_, err = server.Logout(principalContext(2, "user"), &pb.LogoutRequest{})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestClientCertificateAdmin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	certificates := NewClientCertificateCache(store, time.Hour)
+	server := NewAdminServer(store, nil, NewAccountStatusCache(store, time.Minute), certificates)
+	ctx := principalContext(1, "admin", RoleAdmin)
+	identity := "dns:agent.ci.internal"
+
+	_, err := server.AddClientCertificate(principalContext(2, "user"), &pb.AddClientCertificateRequest{Username: "user", Identity: identity})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = server.AddClientCertificate(ctx, &pb.AddClientCertificateRequest{Username: "user", Identity: "agent.ci.internal"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	store.EXPECT().GetAccount(gomock.Any(), "missing").Return(db.Account{}, sql.ErrNoRows)
+	_, err = server.AddClientCertificate(ctx, &pb.AddClientCertificateRequest{Username: "missing", Identity: identity})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// The identity is known as unmapped until it is added.
+	store.EXPECT().GetClientCertificateAccount(gomock.Any(), identity).Return(db.GetClientCertificateAccountRow{}, sql.ErrNoRows)
+	_, found, err := certificates.Get(context.Background(), identity)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	account := db.Account{ID: 2, Username: "user"}
+	cert := db.ClientCertificate{ID: 1, Identity: identity, AccountID: account.ID, CreatedAt: time.Now()}
+	store.EXPECT().GetAccount(gomock.Any(), "user").Return(account, nil).Times(2)
+	store.EXPECT().
+		CreateClientCertificate(gomock.Any(), db.CreateClientCertificateParams{Identity: identity, AccountID: account.ID}).
+		Return(cert, nil)
+	res, err := server.AddClientCertificate(ctx, &pb.AddClientCertificateRequest{Username: "user", Identity: identity})
+	require.NoError(t, err)
+	require.Equal(t, "user", res.GetCertificate().GetUsername())
+
+	store.EXPECT().
+		GetClientCertificateAccount(gomock.Any(), identity).
+		Return(db.GetClientCertificateAccountRow{AccountID: account.ID, Username: account.Username}, nil)
+	mapped, found, err := certificates.Get(context.Background(), identity)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, account.ID, mapped.AccountID)
+
+	store.EXPECT().ListClientCertificates(gomock.Any(), account.ID).Return([]db.ClientCertificate{cert}, nil)
+	list, err := server.ListClientCertificates(ctx, &pb.ListClientCertificatesRequest{Username: "user"})
+	require.NoError(t, err)
+	require.Len(t, list.GetCertificates(), 1)
+	require.Equal(t, identity, list.GetCertificates()[0].GetIdentity())
+
+	store.EXPECT().DeleteClientCertificate(gomock.Any(), "dns:other").Return(db.ClientCertificate{}, sql.ErrNoRows)
+	_, err = server.RemoveClientCertificate(ctx, &pb.RemoveClientCertificateRequest{Identity: "dns:other"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	store.EXPECT().DeleteClientCertificate(gomock.Any(), identity).Return(cert, nil)
+	store.EXPECT().GetAccountByID(gomock.Any(), account.ID).Return(account, nil)
+	_, err = server.RemoveClientCertificate(ctx, &pb.RemoveClientCertificateRequest{Identity: identity})
+	require.NoError(t, err)
+
+	// The removal takes the cached mapping at once.
+	store.EXPECT().GetClientCertificateAccount(gomock.Any(), identity).Return(db.GetClientCertificateAccountRow{}, sql.ErrNoRows)
+	_, found, err = certificates.Get(context.Background(), identity)
+	require.NoError(t, err)
+	require.False(t, found)
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	CodecIdentity = "identity"
+	CodecGzip     = "gzip"
+	CodecZstd     = "zstd"
+)
+
+// sniffLen is the amount of content http.DetectContentType looks at.
+const sniffLen = 512
+
+// compressibleExtensions lists files worth compressing whatever their content looks like.
+var compressibleExtensions = map[string]bool{
+	".txt":  true,
+	".log":  true,
+	".csv":  true,
+	".tsv":  true,
+	".json": true,
+	".xml":  true,
+	".yaml": true,
+	".yml":  true,
+	".toml": true,
+	".ini":  true,
+	".conf": true,
+	".cfg":  true,
+	".env":  true,
+	".md":   true,
+	".sql":  true,
+	".html": true,
+	".css":  true,
+	".js":   true,
+}
+
+func validateCodec(codec string) error {
+	switch codec {
+	case CodecIdentity, CodecGzip, CodecZstd:
+		return nil
+	default:
+		return fmt.Errorf("unknown compression codec '%s'", codec)
+	}
+}
+
+// chooseCodec returns the configured codec for text content and identity for
+// anything else, e.g. images or archives which are compressed already.
+func chooseCodec(codec string, filename string, head []byte) string {
+	if codec == CodecIdentity {
+		return CodecIdentity
+	}
+
+	if compressibleExtensions[strings.ToLower(filepath.Ext(filename))] {
+		return codec
+	}
+
+	if strings.HasPrefix(http.DetectContentType(head), "text/") {
+		return codec
+	}
+
+	return CodecIdentity
+}
+
+// compressContent copies src to dst encoding it with the codec.
+func compressContent(codec string, dst io.Writer, src io.Reader) error {
+	var w io.WriteCloser
+	var err error
+
+	switch codec {
+	case CodecIdentity:
+		_, err = io.Copy(dst, src)
+		return err
+	case CodecGzip:
+		w = gzip.NewWriter(dst)
+	case CodecZstd:
+		w, err = zstd.NewWriter(dst)
+		if err != nil {
+			return err
+		}
+	default:
+		return validateCodec(codec)
+	}
+
+	_, err = io.Copy(w, src)
+	if err != nil {
+		w.Close()
+		return err
+	}
+
+	return w.Close()
+}
+
+// decompressContent returns a reader decoding src with the codec on the fly.
+// Closing the reader closes src.
+func decompressContent(codec string, src io.ReadCloser) (io.ReadCloser, error) {
+	switch codec {
+	case CodecIdentity:
+		return src, nil
+	case CodecGzip:
+		r, err := gzip.NewReader(src)
+		if err != nil {
+			return nil, err
+		}
+		return &decompressingReader{r, func() error { return r.Close() }, src}, nil
+	case CodecZstd:
+		r, err := zstd.NewReader(src)
+		if err != nil {
+			return nil, err
+		}
+		return &decompressingReader{r, func() error { r.Close(); return nil }, src}, nil
+	default:
+		return nil, validateCodec(codec)
+	}
+}
+
+type decompressingReader struct {
+	io.Reader
+	closeDecoder func() error
+	src          io.Closer
+}
+
+func (r *decompressingReader) Close() error {
+	err := r.closeDecoder()
+	if srcErr := r.src.Close(); err == nil {
+		err = srcErr
+	}
+	return err
+}
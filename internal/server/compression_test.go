@@ -0,0 +1,48 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressContent(t *testing.T) {
+	content := []byte(strings.Repeat(util.RandomString(100)+"\n", 100))
+
+	for _, codec := range []string{CodecIdentity, CodecGzip, CodecZstd} {
+		t.Run(codec, func(t *testing.T) {
+			var compressed bytes.Buffer
+			err := compressContent(codec, &compressed, bytes.NewReader(content))
+			require.NoError(t, err)
+
+			if codec != CodecIdentity {
+				require.Less(t, compressed.Len(), len(content))
+			}
+
+			r, err := decompressContent(codec, io.NopCloser(&compressed))
+			require.NoError(t, err)
+
+			got, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+			require.Equal(t, content, got)
+		})
+	}
+
+	err := compressContent("lz4", io.Discard, bytes.NewReader(content))
+	require.Error(t, err)
+}
+
+func TestChooseCodec(t *testing.T) {
+	text := []byte("key = value\n")
+	binary := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0, 0}
+
+	require.Equal(t, CodecZstd, chooseCodec(CodecZstd, "notes", text))
+	require.Equal(t, CodecGzip, chooseCodec(CodecGzip, "dump.SQL", binary))
+	require.Equal(t, CodecIdentity, chooseCodec(CodecZstd, "image.png", binary))
+	require.Equal(t, CodecIdentity, chooseCodec(CodecIdentity, "notes.txt", text))
+}
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"time"
 
@@ -12,24 +13,151 @@ import (
 )
 
 const (
-	defaultAddress       string        = "127.0.0.1:53000"
-	defaultDBAddress     string        = "postgres://localhost/mydb?sslmode=disable"
-	defaultTokenLifeTime time.Duration = time.Minute * 2
-	defaultConfig        string        = "config.json"
+	defaultAddress              string        = "127.0.0.1:53000"
+	defaultHTTPAddress          string        = "127.0.0.1:53080"
+	defaultDBAddress            string        = "postgres://localhost/mydb?sslmode=disable"
+	defaultTokenLifeTime        time.Duration = time.Minute * 2
+	defaultRefreshTokenLifeTime time.Duration = 30 * 24 * time.Hour
+	defaultConfig               string        = "config.json"
+	defaultQuotaBytes           int64         = 1 << 30
+	defaultQuotaFiles           int64         = 1000
+	defaultQuotaSecrets         int64         = 1000
+	defaultStorage              string        = StorageBackendDisk
+	defaultStoragePath          string        = "fs"
+	defaultS3Region             string        = "us-east-1"
+	defaultS3PartSize           uint64        = 16 << 20
+	defaultCompression          string        = CodecIdentity
+	defaultVersionsKeep         int64         = 10
+	defaultVersionsAge          time.Duration = 30 * 24 * time.Hour
+	defaultPruneInterval        time.Duration = time.Hour
+	defaultAuthCleanupInterval  time.Duration = time.Hour
+	defaultFsckGrace            time.Duration = 24 * time.Hour
+	defaultFsckInterval         time.Duration = 24 * time.Hour
+	defaultJWTIssuer            string        = "gophkeeper"
+	defaultJWTAudience          string        = "gophkeeper"
+	defaultLoginMaxFailures     int64         = 5
+	defaultLoginMaxIPFailures   int64         = 20
+	defaultLoginBackoff         time.Duration = time.Second
+	defaultLoginLockout         time.Duration = 15 * time.Minute
+	defaultTOTPIssuer           string        = "GophKeeper"
+	defaultPasswordMinLength    int64         = 8
+	defaultPasswordMinClasses   int64         = 2
 )
 
 type Config struct {
-	Address       string        `env:"ADDRESS"`
-	DBAddress     string        `env:"DB_ADDRESS"`
-	ConfigFile    string        `env:"CONFIG"`
-	TokenLifeTime time.Duration `env:"TOKEN_DURATION"`
-	Environment   string        `env:"ENVIRONMENT"`
+	Address              string        `env:"ADDRESS"`
+	HTTPAddress          string        `env:"HTTP_ADDRESS"`
+	DBAddress            string        `env:"DB_ADDRESS"`
+	ConfigFile           string        `env:"CONFIG"`
+	TokenLifeTime        time.Duration `env:"TOKEN_DURATION"`
+	RefreshTokenLifeTime time.Duration `env:"REFRESH_TOKEN_DURATION"`
+	Environment          string        `env:"ENVIRONMENT"`
+	QuotaBytes           int64         `env:"QUOTA_BYTES"`
+	QuotaFiles           int64         `env:"QUOTA_FILES"`
+	QuotaSecrets         int64         `env:"QUOTA_SECRETS"`
+	StorageBackend       string        `env:"STORAGE_BACKEND"`
+	StoragePath          string        `env:"STORAGE_PATH"`
+	S3Endpoint           string        `env:"S3_ENDPOINT"`
+	S3Region             string        `env:"S3_REGION"`
+	S3Bucket             string        `env:"S3_BUCKET"`
+	S3AccessKey          string        `env:"S3_ACCESS_KEY"`
+	S3SecretKey          string        `env:"S3_SECRET_KEY"`
+	S3UseSSL             bool          `env:"S3_USE_SSL"`
+	S3PartSize           uint64        `env:"S3_PART_SIZE"`
+	Compression          string        `env:"COMPRESSION"`
+	EncryptionKey        string        `env:"ENCRYPTION_KEY"`
+	VersionsKeep         int64         `env:"VERSIONS_KEEP"`
+	VersionsAge          time.Duration `env:"VERSIONS_MAX_AGE"`
+	PruneInterval        time.Duration `env:"VERSIONS_PRUNE_INTERVAL"`
+	AuthCleanupInterval  time.Duration `env:"AUTH_CLEANUP_INTERVAL"`
+	FsckGrace            time.Duration `env:"FSCK_GRACE"`
+	FsckInterval         time.Duration `env:"FSCK_INTERVAL"`
+	FsckRepair           bool          `env:"FSCK_REPAIR"`
+	ShareKey             string        `env:"SHARE_KEY"`
+	ShareBaseURL         string        `env:"SHARE_BASE_URL"`
+	JWTKey               string        `env:"JWT_KEY"`
+	JWTKeyFile           string        `env:"JWT_KEY_FILE"`
+	JWTIssuer            string        `env:"JWT_ISSUER"`
+	JWTAudience          string        `env:"JWT_AUDIENCE"`
+	AdminUsers           []string      `env:"ADMIN_USERS" envSeparator:","`
+	LoginMaxFailures     int64         `env:"LOGIN_MAX_FAILURES"`
+	LoginMaxIPFailures   int64         `env:"LOGIN_MAX_IP_FAILURES"`
+	LoginBackoff         time.Duration `env:"LOGIN_BACKOFF"`
+	LoginLockout         time.Duration `env:"LOGIN_LOCKOUT"`
+	TOTPIssuer           string        `env:"TOTP_ISSUER"`
+	PasswordMinLength    int64         `env:"PASSWORD_MIN_LENGTH"`
+	PasswordMinClasses   int64         `env:"PASSWORD_MIN_CLASSES"`
+	TLSCertFile          string        `env:"TLS_CERT_FILE"`
+	TLSKeyFile           string        `env:"TLS_KEY_FILE"`
+	TLSClientCAFile      string        `env:"TLS_CLIENT_CA_FILE"`
 }
 
 type ConfigFile struct {
-	Address       string        `json:"address"`
-	DBAddress     string        `json:"db_address"`
-	TokenLifeTime time.Duration `json:"token_duration"`
+	Address              string        `json:"address"`
+	HTTPAddress          string        `json:"http_address"`
+	DBAddress            string        `json:"db_address"`
+	TokenLifeTime        time.Duration `json:"token_duration"`
+	RefreshTokenLifeTime time.Duration `json:"refresh_token_duration"`
+	QuotaBytes           int64         `json:"quota_bytes"`
+	QuotaFiles           int64         `json:"quota_files"`
+	QuotaSecrets         int64         `json:"quota_secrets"`
+	StorageBackend       string        `json:"storage_backend"`
+	StoragePath          string        `json:"storage_path"`
+	S3Endpoint           string        `json:"s3_endpoint"`
+	S3Region             string        `json:"s3_region"`
+	S3Bucket             string        `json:"s3_bucket"`
+	S3AccessKey          string        `json:"s3_access_key"`
+	S3SecretKey          string        `json:"s3_secret_key"`
+	S3UseSSL             bool          `json:"s3_use_ssl"`
+	S3PartSize           uint64        `json:"s3_part_size"`
+	Compression          string        `json:"compression"`
+	EncryptionKey        string        `json:"encryption_key"`
+	VersionsKeep         int64         `json:"versions_keep"`
+	VersionsAge          time.Duration `json:"versions_max_age"`
+	PruneInterval        time.Duration `json:"versions_prune_interval"`
+	AuthCleanupInterval  time.Duration `json:"auth_cleanup_interval"`
+	FsckGrace            time.Duration `json:"fsck_grace"`
+	FsckInterval         time.Duration `json:"fsck_interval"`
+	FsckRepair           bool          `json:"fsck_repair"`
+	ShareKey             string        `json:"share_key"`
+	ShareBaseURL         string        `json:"share_base_url"`
+	JWTKey               string        `json:"jwt_key"`
+	JWTKeyFile           string        `json:"jwt_key_file"`
+	JWTIssuer            string        `json:"jwt_issuer"`
+	JWTAudience          string        `json:"jwt_audience"`
+	AdminUsers           []string      `json:"admin_users"`
+	LoginMaxFailures     int64         `json:"login_max_failures"`
+	LoginMaxIPFailures   int64         `json:"login_max_ip_failures"`
+	LoginBackoff         time.Duration `json:"login_backoff"`
+	LoginLockout         time.Duration `json:"login_lockout"`
+	TOTPIssuer           string        `json:"totp_issuer"`
+	PasswordMinLength    int64         `json:"password_min_length"`
+	PasswordMinClasses   int64         `json:"password_min_classes"`
+	TLSCertFile          string        `json:"tls_cert_file"`
+	TLSKeyFile           string        `json:"tls_key_file"`
+	TLSClientCAFile      string        `json:"tls_client_ca_file"`
+}
+
+// String prints the config with the keys and the database password masked,
+// it is what the logs get of the config.
+func (c Config) String() string {
+	type plainConfig Config
+	redacted := plainConfig(c)
+
+	for _, key := range []*string{&redacted.S3AccessKey, &redacted.S3SecretKey, &redacted.EncryptionKey, &redacted.ShareKey, &redacted.JWTKey} {
+		if *key != "" {
+			*key = redactedValue
+		}
+	}
+	// Only the password of a URL is known, a key=value address is masked
+	// as a whole.
+	if dbURL, err := url.Parse(redacted.DBAddress); err == nil && dbURL.Scheme != "" {
+		redacted.DBAddress = dbURL.Redacted()
+	} else if redacted.DBAddress != "" {
+		redacted.DBAddress = redactedValue
+	}
+
+	return fmt.Sprintf("%+v", redacted)
 }
 
 func (config *ConfigFile) UnmarshalJSON(b []byte) error {
@@ -37,7 +165,15 @@ func (config *ConfigFile) UnmarshalJSON(b []byte) error {
 
 	unmarshalledJSON := &struct {
 		*MyTypeAlias
-		TokenLifeTime string `json:"token_duration"`
+		TokenLifeTime        string `json:"token_duration"`
+		RefreshTokenLifeTime string `json:"refresh_token_duration"`
+		VersionsAge          string `json:"versions_max_age"`
+		PruneInterval        string `json:"versions_prune_interval"`
+		AuthCleanupInterval  string `json:"auth_cleanup_interval"`
+		FsckGrace            string `json:"fsck_grace"`
+		FsckInterval         string `json:"fsck_interval"`
+		LoginBackoff         string `json:"login_backoff"`
+		LoginLockout         string `json:"login_lockout"`
 	}{
 		MyTypeAlias: (*MyTypeAlias)(config),
 	}
@@ -51,6 +187,62 @@ func (config *ConfigFile) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
+	if unmarshalledJSON.RefreshTokenLifeTime != "" {
+		config.RefreshTokenLifeTime, err = time.ParseDuration(unmarshalledJSON.RefreshTokenLifeTime)
+		if err != nil {
+			return err
+		}
+	}
+
+	if unmarshalledJSON.VersionsAge != "" {
+		config.VersionsAge, err = time.ParseDuration(unmarshalledJSON.VersionsAge)
+		if err != nil {
+			return err
+		}
+	}
+
+	if unmarshalledJSON.PruneInterval != "" {
+		config.PruneInterval, err = time.ParseDuration(unmarshalledJSON.PruneInterval)
+		if err != nil {
+			return err
+		}
+	}
+
+	if unmarshalledJSON.AuthCleanupInterval != "" {
+		config.AuthCleanupInterval, err = time.ParseDuration(unmarshalledJSON.AuthCleanupInterval)
+		if err != nil {
+			return err
+		}
+	}
+
+	if unmarshalledJSON.FsckGrace != "" {
+		config.FsckGrace, err = time.ParseDuration(unmarshalledJSON.FsckGrace)
+		if err != nil {
+			return err
+		}
+	}
+
+	if unmarshalledJSON.FsckInterval != "" {
+		config.FsckInterval, err = time.ParseDuration(unmarshalledJSON.FsckInterval)
+		if err != nil {
+			return err
+		}
+	}
+
+	if unmarshalledJSON.LoginBackoff != "" {
+		config.LoginBackoff, err = time.ParseDuration(unmarshalledJSON.LoginBackoff)
+		if err != nil {
+			return err
+		}
+	}
+
+	if unmarshalledJSON.LoginLockout != "" {
+		config.LoginLockout, err = time.ParseDuration(unmarshalledJSON.LoginLockout)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -77,6 +269,10 @@ func loadConfigFromFile(c *Config) error {
 		c.Address = cfgFromFile.Address
 	}
 
+	if c.HTTPAddress == defaultHTTPAddress && cfgFromFile.HTTPAddress != "" {
+		c.HTTPAddress = cfgFromFile.HTTPAddress
+	}
+
 	if c.DBAddress == defaultDBAddress && cfgFromFile.DBAddress != "" {
 		c.DBAddress = cfgFromFile.DBAddress
 	}
@@ -85,6 +281,162 @@ func loadConfigFromFile(c *Config) error {
 		c.TokenLifeTime = cfgFromFile.TokenLifeTime
 	}
 
+	if c.RefreshTokenLifeTime == defaultRefreshTokenLifeTime && cfgFromFile.RefreshTokenLifeTime != 0 {
+		c.RefreshTokenLifeTime = cfgFromFile.RefreshTokenLifeTime
+	}
+
+	if c.QuotaBytes == defaultQuotaBytes && cfgFromFile.QuotaBytes != 0 {
+		c.QuotaBytes = cfgFromFile.QuotaBytes
+	}
+
+	if c.QuotaFiles == defaultQuotaFiles && cfgFromFile.QuotaFiles != 0 {
+		c.QuotaFiles = cfgFromFile.QuotaFiles
+	}
+
+	if c.QuotaSecrets == defaultQuotaSecrets && cfgFromFile.QuotaSecrets != 0 {
+		c.QuotaSecrets = cfgFromFile.QuotaSecrets
+	}
+
+	if c.StorageBackend == defaultStorage && cfgFromFile.StorageBackend != "" {
+		c.StorageBackend = cfgFromFile.StorageBackend
+	}
+
+	if c.StoragePath == defaultStoragePath && cfgFromFile.StoragePath != "" {
+		c.StoragePath = cfgFromFile.StoragePath
+	}
+
+	if c.S3Endpoint == "" && cfgFromFile.S3Endpoint != "" {
+		c.S3Endpoint = cfgFromFile.S3Endpoint
+	}
+
+	if c.S3Region == defaultS3Region && cfgFromFile.S3Region != "" {
+		c.S3Region = cfgFromFile.S3Region
+	}
+
+	if c.S3Bucket == "" && cfgFromFile.S3Bucket != "" {
+		c.S3Bucket = cfgFromFile.S3Bucket
+	}
+
+	if c.S3AccessKey == "" && cfgFromFile.S3AccessKey != "" {
+		c.S3AccessKey = cfgFromFile.S3AccessKey
+	}
+
+	if c.S3SecretKey == "" && cfgFromFile.S3SecretKey != "" {
+		c.S3SecretKey = cfgFromFile.S3SecretKey
+	}
+
+	if !c.S3UseSSL && cfgFromFile.S3UseSSL {
+		c.S3UseSSL = cfgFromFile.S3UseSSL
+	}
+
+	if c.S3PartSize == defaultS3PartSize && cfgFromFile.S3PartSize != 0 {
+		c.S3PartSize = cfgFromFile.S3PartSize
+	}
+
+	if c.Compression == defaultCompression && cfgFromFile.Compression != "" {
+		c.Compression = cfgFromFile.Compression
+	}
+
+	if c.EncryptionKey == "" && cfgFromFile.EncryptionKey != "" {
+		c.EncryptionKey = cfgFromFile.EncryptionKey
+	}
+
+	if c.VersionsKeep == defaultVersionsKeep && cfgFromFile.VersionsKeep != 0 {
+		c.VersionsKeep = cfgFromFile.VersionsKeep
+	}
+
+	if c.VersionsAge == defaultVersionsAge && cfgFromFile.VersionsAge != 0 {
+		c.VersionsAge = cfgFromFile.VersionsAge
+	}
+
+	if c.PruneInterval == defaultPruneInterval && cfgFromFile.PruneInterval != 0 {
+		c.PruneInterval = cfgFromFile.PruneInterval
+	}
+
+	if c.AuthCleanupInterval == defaultAuthCleanupInterval && cfgFromFile.AuthCleanupInterval != 0 {
+		c.AuthCleanupInterval = cfgFromFile.AuthCleanupInterval
+	}
+
+	if c.FsckGrace == defaultFsckGrace && cfgFromFile.FsckGrace != 0 {
+		c.FsckGrace = cfgFromFile.FsckGrace
+	}
+
+	if c.FsckInterval == defaultFsckInterval && cfgFromFile.FsckInterval != 0 {
+		c.FsckInterval = cfgFromFile.FsckInterval
+	}
+
+	if !c.FsckRepair && cfgFromFile.FsckRepair {
+		c.FsckRepair = cfgFromFile.FsckRepair
+	}
+
+	if c.ShareKey == "" && cfgFromFile.ShareKey != "" {
+		c.ShareKey = cfgFromFile.ShareKey
+	}
+
+	if c.ShareBaseURL == "" && cfgFromFile.ShareBaseURL != "" {
+		c.ShareBaseURL = cfgFromFile.ShareBaseURL
+	}
+
+	if c.JWTKey == "" && cfgFromFile.JWTKey != "" {
+		c.JWTKey = cfgFromFile.JWTKey
+	}
+
+	if c.JWTKeyFile == "" && cfgFromFile.JWTKeyFile != "" {
+		c.JWTKeyFile = cfgFromFile.JWTKeyFile
+	}
+
+	if c.JWTIssuer == defaultJWTIssuer && cfgFromFile.JWTIssuer != "" {
+		c.JWTIssuer = cfgFromFile.JWTIssuer
+	}
+
+	if c.JWTAudience == defaultJWTAudience && cfgFromFile.JWTAudience != "" {
+		c.JWTAudience = cfgFromFile.JWTAudience
+	}
+
+	if len(c.AdminUsers) == 0 && len(cfgFromFile.AdminUsers) != 0 {
+		c.AdminUsers = cfgFromFile.AdminUsers
+	}
+
+	if c.LoginMaxFailures == defaultLoginMaxFailures && cfgFromFile.LoginMaxFailures != 0 {
+		c.LoginMaxFailures = cfgFromFile.LoginMaxFailures
+	}
+
+	if c.LoginMaxIPFailures == defaultLoginMaxIPFailures && cfgFromFile.LoginMaxIPFailures != 0 {
+		c.LoginMaxIPFailures = cfgFromFile.LoginMaxIPFailures
+	}
+
+	if c.LoginBackoff == defaultLoginBackoff && cfgFromFile.LoginBackoff != 0 {
+		c.LoginBackoff = cfgFromFile.LoginBackoff
+	}
+
+	if c.LoginLockout == defaultLoginLockout && cfgFromFile.LoginLockout != 0 {
+		c.LoginLockout = cfgFromFile.LoginLockout
+	}
+
+	if c.TOTPIssuer == defaultTOTPIssuer && cfgFromFile.TOTPIssuer != "" {
+		c.TOTPIssuer = cfgFromFile.TOTPIssuer
+	}
+
+	if c.PasswordMinLength == defaultPasswordMinLength && cfgFromFile.PasswordMinLength != 0 {
+		c.PasswordMinLength = cfgFromFile.PasswordMinLength
+	}
+
+	if c.PasswordMinClasses == defaultPasswordMinClasses && cfgFromFile.PasswordMinClasses != 0 {
+		c.PasswordMinClasses = cfgFromFile.PasswordMinClasses
+	}
+
+	if c.TLSCertFile == "" && cfgFromFile.TLSCertFile != "" {
+		c.TLSCertFile = cfgFromFile.TLSCertFile
+	}
+
+	if c.TLSKeyFile == "" && cfgFromFile.TLSKeyFile != "" {
+		c.TLSKeyFile = cfgFromFile.TLSKeyFile
+	}
+
+	if c.TLSClientCAFile == "" && cfgFromFile.TLSClientCAFile != "" {
+		c.TLSClientCAFile = cfgFromFile.TLSClientCAFile
+	}
+
 	return nil
 }
 
@@ -92,8 +444,41 @@ func GetConfig() (*Config, error) {
 	c := &Config{}
 
 	flag.StringVar(&c.Address, "a", defaultAddress, "Socket to listen on")
+	flag.StringVar(&c.HTTPAddress, "http-address", defaultHTTPAddress, "Socket of the share link downloads and the JWKS document, empty disables them")
+	flag.StringVar(&c.ShareBaseURL, "share-base-url", "", "Public URL of the share link listener, http://<http-address> by default")
 	flag.StringVar(&c.DBAddress, "d", defaultDBAddress, "Database address")
 	flag.DurationVar(&c.TokenLifeTime, "t", defaultTokenLifeTime, "User token lifetime duration")
+	flag.StringVar(&c.JWTKeyFile, "jwt-key-file", "", "File of the token signing keys, reloaded on SIGHUP")
+	flag.StringVar(&c.JWTIssuer, "jwt-issuer", defaultJWTIssuer, "Issuer claim of the access tokens")
+	flag.StringVar(&c.JWTAudience, "jwt-audience", defaultJWTAudience, "Audience claim of the access tokens")
+	flag.DurationVar(&c.RefreshTokenLifeTime, "refresh-token-duration", defaultRefreshTokenLifeTime, "Refresh token lifetime duration, rotation starts it anew")
+	flag.Int64Var(&c.QuotaBytes, "quota-bytes", defaultQuotaBytes, "Default limit of stored file bytes per account, 0 is unlimited")
+	flag.Int64Var(&c.QuotaFiles, "quota-files", defaultQuotaFiles, "Default limit of files per account, 0 is unlimited")
+	flag.Int64Var(&c.QuotaSecrets, "quota-secrets", defaultQuotaSecrets, "Default limit of secrets per account, 0 is unlimited")
+	flag.StringVar(&c.StorageBackend, "storage", defaultStorage, "File content storage backend: disk, s3 or postgres")
+	flag.StringVar(&c.StoragePath, "storage-path", defaultStoragePath, "Folder for the disk storage, key prefix for s3")
+	flag.StringVar(&c.S3Endpoint, "s3-endpoint", "", "S3 compatible storage endpoint")
+	flag.StringVar(&c.S3Region, "s3-region", defaultS3Region, "S3 region")
+	flag.StringVar(&c.S3Bucket, "s3-bucket", "", "S3 bucket for file content")
+	flag.Uint64Var(&c.S3PartSize, "s3-part-size", defaultS3PartSize, "S3 multipart upload part size")
+	flag.StringVar(&c.Compression, "compression", defaultCompression, "Codec for text file content: identity, gzip or zstd")
+	flag.Int64Var(&c.VersionsKeep, "versions-keep", defaultVersionsKeep, "Number of old file versions kept regardless of their age")
+	flag.DurationVar(&c.VersionsAge, "versions-max-age", defaultVersionsAge, "Age after which old file versions past versions-keep are removed")
+	flag.DurationVar(&c.PruneInterval, "versions-prune-interval", defaultPruneInterval, "Interval of the old file versions cleanup, 0 disables it")
+	flag.DurationVar(&c.AuthCleanupInterval, "auth-cleanup-interval", defaultAuthCleanupInterval, "Interval of the expired refresh tokens, revoked tokens, login failures and login challenges cleanup, 0 disables it")
+	flag.DurationVar(&c.FsckGrace, "fsck-grace", defaultFsckGrace, "Time without a sign of life after which unfinished uploads are taken for failed ones by the storage check")
+	flag.DurationVar(&c.FsckInterval, "fsck-interval", defaultFsckInterval, "Interval of the storage check, 0 disables it")
+	flag.BoolVar(&c.FsckRepair, "fsck-repair", false, "Repair the problems found by the storage check instead of only reporting them, the files whose content is missing are only dropped by the fsck subcommand")
+	flag.Int64Var(&c.LoginMaxFailures, "login-max-failures", defaultLoginMaxFailures, "Failed logins of a username before it is locked out, 0 disables the limit")
+	flag.Int64Var(&c.LoginMaxIPFailures, "login-max-ip-failures", defaultLoginMaxIPFailures, "Failed logins from a peer address before it is locked out, 0 disables the limit")
+	flag.DurationVar(&c.LoginBackoff, "login-backoff", defaultLoginBackoff, "Wait after the first failed login, doubled with every further failure")
+	flag.DurationVar(&c.LoginLockout, "login-lockout", defaultLoginLockout, "Lockout after too many failed logins, doubled with every further failure")
+	flag.StringVar(&c.TOTPIssuer, "totp-issuer", defaultTOTPIssuer, "Issuer shown by the authenticator apps for the two-factor codes")
+	flag.Int64Var(&c.PasswordMinLength, "password-min-length", defaultPasswordMinLength, "Least number of characters of a new password")
+	flag.Int64Var(&c.PasswordMinClasses, "password-min-classes", defaultPasswordMinClasses, "Least number of lower case, upper case, digit and symbol classes a new password mixes")
+	flag.StringVar(&c.TLSCertFile, "tls-cert-file", "", "PEM certificate of the gRPC listener, empty serves plaintext")
+	flag.StringVar(&c.TLSKeyFile, "tls-key-file", "", "PEM private key of the gRPC listener certificate")
+	flag.StringVar(&c.TLSClientCAFile, "tls-client-ca-file", "", "PEM bundle of the CAs client certificates are verified against, empty disables the certificate login")
 	flag.StringVar(&c.ConfigFile, "config", defaultConfig, "Config file name")
 	flag.StringVar(&c.ConfigFile, "c", defaultConfig, "Config file name")
 	flag.Parse()
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *FileServer) MakeDirectory(ctx context.Context, in *pb.MakeDirectoryRequest) (*pb.MakeDirectoryResponse, error) {
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Msgf("receive an MakeDirectory request from user %s", caller.Username)
+
+	dir, err := dirPath(in.GetPath())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.fileStore.MakeDirectoryTx(ctx, db.MakeDirectoryTxParams{
+		AccountID: caller.AccountID,
+		Path:      dir,
+	})
+	if err != nil {
+		if err == db.ErrPathConflict {
+			return nil, logError(status.Errorf(codes.AlreadyExists, "path '/%s' is taken by a file", dir))
+		}
+		return nil, logError(status.Errorf(codes.Internal, "cannot make directory: %v", err))
+	}
+
+	return &pb.MakeDirectoryResponse{Path: dir}, nil
+}
+
+func (s *FileServer) RemoveDirectory(ctx context.Context, in *pb.RemoveDirectoryRequest) (*pb.RemoveDirectoryResponse, error) {
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Msgf("receive an RemoveDirectory request from user %s", caller.Username)
+
+	dir, err := dirPath(in.GetPath())
+	if err != nil {
+		return nil, err
+	}
+
+	if dir == "" {
+		return nil, logError(status.Error(codes.InvalidArgument, "cannot remove the root directory"))
+	}
+
+	releaser := newBlobReleaser(ctx, s.fileStore, s.fileContentSaver)
+	arg := db.RemoveDirectoryTxParams{
+		AccountID:    caller.AccountID,
+		Path:         dir,
+		Recursive:    in.GetRecursive(),
+		AfterRelease: releaser.AfterRelease,
+	}
+
+	removed, err := s.fileStore.RemoveDirectoryTx(ctx, arg)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, logError(status.Errorf(codes.NotFound, "cannot find directory '/%s'", dir))
+		case db.ErrDirectoryNotEmpty:
+			return nil, logError(status.Errorf(codes.FailedPrecondition, "directory '/%s' is not empty", dir))
+		}
+		return nil, logError(status.Errorf(codes.Internal, "cannot remove directory: %v", err))
+	}
+	releaser.Committed()
+
+	log.Info().Msgf("Removed directory '/%s' with %d files", dir, removed)
+	return &pb.RemoveDirectoryResponse{RemovedFiles: removed}, nil
+}
+
+// MoveFile moves or renames a file. Only the files row changes, the content
+// stays where it is in the storage.
+func (s *FileServer) MoveFile(ctx context.Context, in *pb.MoveFileRequest) (*pb.MoveFileResponse, error) {
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Msgf("receive an MoveFile request from user %s", caller.Username)
+
+	fromDir, fromName, err := fileKey(in.GetFrom())
+	if err != nil {
+		return nil, err
+	}
+
+	to := &pb.FileInfo{
+		Filepath: in.GetTo().GetFilepath(),
+		Filename: in.GetTo().GetFilename(),
+	}
+	if to.Filename == "" {
+		to.Filename = fromName
+	}
+
+	toDir, toName, err := fileKey(to)
+	if err != nil {
+		return nil, err
+	}
+
+	arg := db.MoveFileTxParams{
+		UpdateFilePathParams: db.UpdateFilePathParams{
+			NewFilepath: toDir,
+			NewFilename: toName,
+			Filename:    fromName,
+			AccountID:   caller.AccountID,
+			Filepath:    fromDir,
+		},
+	}
+
+	err = s.fileStore.MoveFileTx(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, logError(status.Error(codes.NotFound, "cannot find file"))
+		}
+		if err == db.ErrPathConflict {
+			return nil, logError(status.Errorf(codes.AlreadyExists, "path '/%s/%s' is taken by a directory", toDir, toName))
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return nil, logError(status.Errorf(codes.AlreadyExists, "file '/%s/%s' already exists", toDir, toName))
+		}
+		return nil, logError(status.Errorf(codes.Internal, "cannot move file: %v", err))
+	}
+
+	log.Info().Msgf("Moved file '/%s/%s' to '/%s/%s'", fromDir, fromName, toDir, toName)
+	return &pb.MoveFileResponse{
+		Info: &pb.FileInfo{
+			Filepath: toDir,
+			Filename: toName,
+		},
+	}, nil
+}
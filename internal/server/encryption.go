@@ -0,0 +1,274 @@
+package server
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// segmentSize is the amount of plaintext sealed as a single AES-GCM message.
+	segmentSize = 64 << 10
+	// sealedSegmentSize is the stored size of a full segment with the GCM tag.
+	sealedSegmentSize = segmentSize + 16
+	// dataKeySize is the size of the random AES-256 key of each blob.
+	dataKeySize = 32
+)
+
+var (
+	errCorrupted = errors.New("encrypted content is corrupted")
+	errTruncated = fmt.Errorf("%w: content is truncated", errCorrupted)
+)
+
+// KeyWrapper encrypts the per-blob data keys with the server master key, so
+// only the wrapped keys are kept in the database.
+type KeyWrapper struct {
+	aead cipher.AEAD
+}
+
+// NewKeyWrapper takes the hex encoded 32 byte master key.
+func NewKeyWrapper(masterKey string) (*KeyWrapper, error) {
+	key, err := hex.DecodeString(masterKey)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key must be hex encoded: %w", err)
+	}
+
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes long", dataKeySize)
+	}
+
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyWrapper{aead: aead}, nil
+}
+
+// NewDataKey returns a fresh data key wrapped for the blob with the given hash.
+func (w *KeyWrapper) NewDataKey(hash string) ([]byte, error) {
+	key := make([]byte, dataKeySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// The blob hash is authenticated with the key, so a wrapped key copied
+	// to another blob row does not unwrap.
+	return w.Seal(key, hash)
+}
+
+// Unwrap returns the data key of the blob with the given hash.
+func (w *KeyWrapper) Unwrap(hash string, wrapped []byte) ([]byte, error) {
+	key, err := w.Open(wrapped, hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unwrap data key: %w", err)
+	}
+
+	return key, nil
+}
+
+// Seal encrypts a small value with the master key. The value only opens with
+// the same aad, which ties it to the row it is stored in.
+func (w *KeyWrapper) Seal(plaintext []byte, aad string) ([]byte, error) {
+	nonce := make([]byte, w.aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.aead.Seal(nonce, nonce, plaintext, []byte(aad)), nil
+}
+
+// Open decrypts a value sealed with Seal.
+func (w *KeyWrapper) Open(sealed []byte, aad string) ([]byte, error) {
+	nonceSize := w.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, fmt.Errorf("sealed value is too short")
+	}
+
+	return w.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(aad))
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// segmentNonce derives the nonce from the segment index and marks the last
+// segment, so reordered, dropped or appended segments fail authentication.
+// Every blob has its own key, so the nonces never repeat under one key.
+func segmentNonce(index uint64, last bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], index)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// encryptedSize returns the size of size bytes of plaintext once encrypted.
+// Empty content still takes a single, empty, last segment.
+func encryptedSize(size int64) int64 {
+	segments := (size + segmentSize - 1) / segmentSize
+	if segments == 0 {
+		segments = 1
+	}
+	return size + segments*(sealedSegmentSize-segmentSize)
+}
+
+type encryptingWriter struct {
+	dst    io.Writer
+	aead   cipher.AEAD
+	buffer []byte
+	index  uint64
+}
+
+// newEncryptingWriter seals everything written to it segment by segment. Close
+// writes the last segment and must be called.
+func newEncryptingWriter(dst io.Writer, key []byte) (io.WriteCloser, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptingWriter{
+		dst:    dst,
+		aead:   aead,
+		buffer: make([]byte, 0, segmentSize),
+	}, nil
+}
+
+func (w *encryptingWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// A full segment is held back until more data arrives, only then
+		// it is known not to be the last one.
+		if len(w.buffer) == segmentSize {
+			err := w.seal(false)
+			if err != nil {
+				return written, err
+			}
+		}
+
+		n := copy(w.buffer[len(w.buffer):segmentSize], p)
+		w.buffer = w.buffer[:len(w.buffer)+n]
+		p = p[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+func (w *encryptingWriter) seal(last bool) error {
+	sealed := w.aead.Seal(nil, segmentNonce(w.index, last), w.buffer, nil)
+	w.index++
+	w.buffer = w.buffer[:0]
+
+	_, err := w.dst.Write(sealed)
+	return err
+}
+
+func (w *encryptingWriter) Close() error {
+	return w.seal(true)
+}
+
+// decryptContent returns a reader decrypting src on the fly, src starts with
+// the segment of the given index. Closing the reader closes src, src is closed
+// on error as well.
+func decryptContent(src io.ReadCloser, key []byte, index uint64) (io.ReadCloser, error) {
+	r, err := newDecryptingReader(src, key, index)
+	if err != nil {
+		src.Close()
+		return nil, err
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, src}, nil
+}
+
+type decryptingReader struct {
+	src    *bufio.Reader
+	aead   cipher.AEAD
+	buffer []byte
+	plain  []byte
+	index  uint64
+	done   bool
+}
+
+// newDecryptingReader opens the segments of src one at a time, holding no
+// more than a single segment in memory.
+func newDecryptingReader(src io.Reader, key []byte, index uint64) (io.Reader, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptingReader{
+		src:    bufio.NewReader(src),
+		aead:   aead,
+		buffer: make([]byte, sealedSegmentSize),
+		index:  index,
+	}, nil
+}
+
+func (r *decryptingReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		err := r.open()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+func (r *decryptingReader) open() error {
+	n, err := io.ReadFull(r.src, r.buffer)
+	switch {
+	case err == io.EOF:
+		return errTruncated
+	case err == io.ErrUnexpectedEOF:
+		r.done = true
+	case err != nil:
+		return err
+	default:
+		// A full segment is the last one only if nothing follows it.
+		_, err = r.src.Peek(1)
+		if err == io.EOF {
+			r.done = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	plain, err := r.aead.Open(r.buffer[:0], segmentNonce(r.index, r.done), r.buffer[:n], nil)
+	if err != nil {
+		if r.done {
+			return errTruncated
+		}
+		return fmt.Errorf("%w: cannot decrypt segment %d", errCorrupted, r.index)
+	}
+
+	r.index++
+	r.plain = plain
+	return nil
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyWrapper(t *testing.T) *KeyWrapper {
+	w, err := NewKeyWrapper(hex.EncodeToString([]byte(util.RandomString(dataKeySize))))
+	require.NoError(t, err)
+	return w
+}
+
+func encryptTestContent(t *testing.T, key []byte, content []byte) []byte {
+	var encrypted bytes.Buffer
+	w, err := newEncryptingWriter(&encrypted, key)
+	require.NoError(t, err)
+
+	_, err = io.Copy(w, bytes.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.EqualValues(t, encryptedSize(int64(len(content))), encrypted.Len())
+
+	return encrypted.Bytes()
+}
+
+func decryptTestContent(key []byte, encrypted []byte) ([]byte, error) {
+	r, err := decryptContent(io.NopCloser(bytes.NewReader(encrypted)), key, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+func TestKeyWrapper(t *testing.T) {
+	w := newTestKeyWrapper(t)
+
+	wrapped, err := w.NewDataKey("hash")
+	require.NoError(t, err)
+
+	key, err := w.Unwrap("hash", wrapped)
+	require.NoError(t, err)
+	require.Len(t, key, dataKeySize)
+
+	_, err = w.Unwrap("other", wrapped)
+	require.Error(t, err)
+
+	_, err = newTestKeyWrapper(t).Unwrap("hash", wrapped)
+	require.Error(t, err)
+
+	_, err = NewKeyWrapper("not hex")
+	require.Error(t, err)
+
+	_, err = NewKeyWrapper(hex.EncodeToString([]byte("short")))
+	require.Error(t, err)
+}
+
+func TestEncryptContent(t *testing.T) {
+	key := []byte(util.RandomString(dataKeySize))
+
+	for _, size := range []int{0, 1, segmentSize - 1, segmentSize, segmentSize + 1, 3 * segmentSize} {
+		content := []byte(util.RandomString(size))
+
+		encrypted := encryptTestContent(t, key, content)
+		if size > 16 {
+			require.NotContains(t, string(encrypted), string(content[:(size+1)/2]))
+		}
+
+		got, err := decryptTestContent(key, encrypted)
+		require.NoError(t, err)
+		require.Equal(t, len(content), len(got))
+		require.Equal(t, content, got)
+	}
+}
+
+func TestEncryptContentTampering(t *testing.T) {
+	key := []byte(util.RandomString(dataKeySize))
+	content := []byte(util.RandomString(3*segmentSize + 10))
+	encrypted := encryptTestContent(t, key, content)
+	sealedSize := segmentSize + 16
+
+	testCases := []struct {
+		name   string
+		tamper func(data []byte) []byte
+	}{
+		{
+			name: "FlippedBit",
+			tamper: func(data []byte) []byte {
+				data[sealedSize+10] ^= 1
+				return data
+			},
+		},
+		{
+			name: "TruncatedSegments",
+			tamper: func(data []byte) []byte {
+				return data[:2*sealedSize]
+			},
+		},
+		{
+			name: "TruncatedTail",
+			tamper: func(data []byte) []byte {
+				return data[:len(data)-1]
+			},
+		},
+		{
+			name: "ReorderedSegments",
+			tamper: func(data []byte) []byte {
+				reordered := append([]byte{}, data[sealedSize:2*sealedSize]...)
+				reordered = append(reordered, data[:sealedSize]...)
+				return append(reordered, data[2*sealedSize:]...)
+			},
+		},
+		{
+			name: "AppendedSegment",
+			tamper: func(data []byte) []byte {
+				return append(data, data[:sealedSize]...)
+			},
+		},
+		{
+			name: "Empty",
+			tamper: func(data []byte) []byte {
+				return nil
+			},
+		},
+		{
+			name: "WrongKey",
+			tamper: func(data []byte) []byte {
+				key = []byte(util.RandomString(dataKeySize))
+				return data
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			tampered := tc.tamper(append([]byte{}, encrypted...))
+
+			_, err := decryptTestContent(key, tampered)
+			require.ErrorIs(t, err, errCorrupted)
+		})
+	}
+}
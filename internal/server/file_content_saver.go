@@ -1,14 +1,136 @@
 package server
 
 import (
-	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/rs/zerolog/log"
+)
+
+var blobHashRegexp = regexp.MustCompile("^[0-9a-f]{64}$")
+
+const (
+	StorageBackendDisk     = "disk"
+	StorageBackendS3       = "s3"
+	StorageBackendPostgres = "postgres"
 )
 
+// FileContentSaver keeps file content addressed by the hex encoded SHA-256 of the content.
+// Open starts reading at offset, so a range of a large blob is read without
+// going through the bytes before it. Walk calls fn for every stored blob, modTime
+// is zero when the backend does not track it.
 type FileContentSaver interface {
-	Save(filename string, filepath string, fileData bytes.Buffer) error
+	Save(ctx context.Context, hash string, content io.Reader, size int64) error
+	Open(ctx context.Context, hash string, offset int64) (io.ReadCloser, error)
+	Exists(ctx context.Context, hash string) (bool, error)
+	Delete(ctx context.Context, hash string) error
+	Walk(ctx context.Context, fn func(hash string, modTime time.Time) error) error
+}
+
+// PartialContentCleaner is implemented by backends which leave partially
+// written content behind when the server stops in the middle of a Save.
+// CleanPartial removes what was started before the given time and returns
+// its names, with dryRun it only returns them.
+type PartialContentCleaner interface {
+	CleanPartial(ctx context.Context, before time.Time, dryRun bool) ([]string, error)
+}
+
+// TxFileContentSaver is implemented by backends keeping content in the database.
+// WithTx binds the backend to a transaction, so the content is written together
+// with the files row.
+type TxFileContentSaver interface {
+	FileContentSaver
+	WithTx(q db.Querier) FileContentSaver
+}
+
+// contentSaverInTx binds the saver to the transaction q if the saver supports it.
+func contentSaverInTx(saver FileContentSaver, q db.Querier) FileContentSaver {
+	if txSaver, ok := saver.(TxFileContentSaver); ok {
+		return txSaver.WithTx(q)
+	}
+	return saver
+}
+
+// blobReleaser removes the content of the blobs a transaction releases. The
+// postgres backend removes it in the transaction. The content of the other
+// backends would outlive a rollback, it is removed once the transaction has
+// committed.
+type blobReleaser struct {
+	ctx      context.Context
+	store    db.Store
+	saver    FileContentSaver
+	released []string
+}
+
+func newBlobReleaser(ctx context.Context, store db.Store, saver FileContentSaver) *blobReleaser {
+	return &blobReleaser{ctx: ctx, store: store, saver: saver}
+}
+
+// AfterRelease is the AfterRelease callback of the transactions.
+func (r *blobReleaser) AfterRelease(q db.Querier, blob db.Blob) error {
+	if txSaver, ok := r.saver.(TxFileContentSaver); ok {
+		return txSaver.WithTx(q).Delete(r.ctx, blob.Hash)
+	}
+	r.released = append(r.released, blob.Hash)
+	return nil
+}
+
+// Committed removes the content released by the committed transaction. The
+// blob lock is taken anew, content uploaded again in the meantime stays. A
+// failed removal leaves an orphan to the storage check.
+func (r *blobReleaser) Committed() {
+	for _, hash := range r.released {
+		hash := hash
+		_, err := r.store.DeleteOrphanContentTx(r.ctx, db.DeleteOrphanContentTxParams{
+			Hash: hash,
+			DeleteContent: func(db.Querier) error {
+				return r.saver.Delete(r.ctx, hash)
+			},
+		})
+		if err != nil {
+			log.Error().Err(err).Msgf("cannot remove content '%s', left to the storage check", hash)
+		}
+	}
+	r.released = nil
+}
+
+// NewFileContentSaver returns the storage backend selected in the config.
+func NewFileContentSaver(cfg *Config, store db.Store) (FileContentSaver, error) {
+	switch cfg.StorageBackend {
+	case StorageBackendDisk:
+		return NewDiskFileContentSaver(cfg.StoragePath), nil
+	case StorageBackendS3:
+		return NewS3FileContentSaver(S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			Prefix:    cfg.StoragePath,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			UseSSL:    cfg.S3UseSSL,
+			PartSize:  cfg.S3PartSize,
+		})
+	case StorageBackendPostgres:
+		return NewPostgresFileContentSaver(store), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend '%s'", cfg.StorageBackend)
+	}
+}
+
+// validateBlobHash keeps anything but a digest from turning into a storage path.
+func validateBlobHash(hash string) error {
+	if !blobHashRegexp.MatchString(hash) {
+		return fmt.Errorf("invalid blob hash '%s'", hash)
+	}
+	return nil
 }
 
 type DiskFileContentSaver struct {
@@ -19,25 +141,201 @@ func NewDiskFileContentSaver(fileFolder string) FileContentSaver {
 	return &DiskFileContentSaver{fileFolder: fileFolder}
 }
 
-func (fs *DiskFileContentSaver) Save(filename string, filepath string, fileData bytes.Buffer) error {
-	path := fmt.Sprintf("%s/%s", fs.fileFolder, filepath)
+func (fs *DiskFileContentSaver) blobPath(hash string) (string, error) {
+	if err := validateBlobHash(hash); err != nil {
+		return "", err
+	}
 
-	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
-		err := os.Mkdir(path, os.ModePerm)
-		if err != nil {
-			return fmt.Errorf("cannot create directory: %w", err)
-		}
+	return filepath.Join(fs.fileFolder, hash[:2], hash), nil
+}
+
+// Save writes the content to a temporary file first and renames it afterwards,
+// so a blob is either fully present on disk or missing.
+func (fs *DiskFileContentSaver) Save(ctx context.Context, hash string, content io.Reader, size int64) error {
+	path, err := fs.blobPath(hash)
+	if err != nil {
+		return err
 	}
 
-	filePath := fmt.Sprintf("%s/%s", path, filename)
-	file, err := os.Create(filePath)
+	dir := filepath.Dir(path)
+	err = os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("cannot create directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(dir, hash+".tmp-*")
 	if err != nil {
 		return fmt.Errorf("cannot create file: %w", err)
 	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	written, err := io.Copy(file, content)
+	if err != nil {
+		return fmt.Errorf("cannot write content to file: %w", err)
+	}
+	if written != size {
+		return fmt.Errorf("content size mismatch: %d != %d", written, size)
+	}
+
+	err = file.Sync()
+	if err != nil {
+		return fmt.Errorf("cannot sync file: %w", err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("cannot close file: %w", err)
+	}
+
+	err = os.Rename(file.Name(), path)
+	if err != nil {
+		return fmt.Errorf("cannot move file in place: %w", err)
+	}
+
+	return nil
+}
+
+func (fs *DiskFileContentSaver) Open(ctx context.Context, hash string, offset int64) (io.ReadCloser, error) {
+	path, err := fs.blobPath(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = f.Seek(offset, io.SeekStart)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (fs *DiskFileContentSaver) Exists(ctx context.Context, hash string) (bool, error) {
+	path, err := fs.blobPath(hash)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (fs *DiskFileContentSaver) Delete(ctx context.Context, hash string) error {
+	path, err := fs.blobPath(hash)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cannot remove file: %w", err)
+	}
+
+	return nil
+}
+
+// Walk goes through the two character directories of the storage folder,
+// anything but blob files is skipped.
+func (fs *DiskFileContentSaver) Walk(ctx context.Context, fn func(hash string, modTime time.Time) error) error {
+	return fs.walkDirs(func(dir string, entry os.DirEntry) error {
+		hash := entry.Name()
+		if !blobHashRegexp.MatchString(hash) || hash[:2] != dir {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return fn(hash, info.ModTime())
+	})
+}
+
+// CleanPartial removes the temporary files Save did not get to rename.
+func (fs *DiskFileContentSaver) CleanPartial(ctx context.Context, before time.Time, dryRun bool) ([]string, error) {
+	var removed []string
+
+	err := fs.walkDirs(func(dir string, entry os.DirEntry) error {
+		name := entry.Name()
+		hash, _, found := strings.Cut(name, ".tmp-")
+		if !found || !blobHashRegexp.MatchString(hash) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !info.ModTime().Before(before) {
+			return nil
+		}
+
+		path := filepath.Join(fs.fileFolder, dir, name)
+		if !dryRun {
+			err = os.Remove(path)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("cannot remove file: %w", err)
+			}
+		}
 
-	_, err = fileData.WriteTo(file)
+		removed = append(removed, path)
+		return nil
+	})
+
+	return removed, err
+}
+
+// walkDirs calls fn for every regular file in the blob directories.
+func (fs *DiskFileContentSaver) walkDirs(fn func(dir string, entry os.DirEntry) error) error {
+	dirs, err := os.ReadDir(fs.fileFolder)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("cannot write image to file: %w", err)
+		return fmt.Errorf("cannot read directory: %w", err)
+	}
+
+	for _, dir := range dirs {
+		if !dir.IsDir() || len(dir.Name()) != 2 {
+			continue
+		}
+
+		entries, err := os.ReadDir(filepath.Join(fs.fileFolder, dir.Name()))
+		if err != nil {
+			return fmt.Errorf("cannot read directory: %w", err)
+		}
+
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() {
+				continue
+			}
+
+			err = fn(dir.Name(), entry)
+			if err != nil {
+				return err
+			}
+		}
 	}
+
 	return nil
 }
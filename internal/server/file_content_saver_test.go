@@ -0,0 +1,220 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mockdb "github.com/Jay-T/go-devops-advanced-diploma/db/mock"
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func randomContent(size int) ([]byte, string) {
+	content := []byte(util.RandomString(size))
+	sum := sha256.Sum256(content)
+	return content, hex.EncodeToString(sum[:])
+}
+
+func requireContent(t *testing.T, saver FileContentSaver, hash string, content []byte) {
+	requireContentAt(t, saver, hash, content, 0)
+}
+
+func requireContentAt(t *testing.T, saver FileContentSaver, hash string, content []byte, offset int64) {
+	r, err := saver.Open(context.Background(), hash, offset)
+	require.NoError(t, err)
+	defer r.Close()
+
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, content[offset:], got)
+}
+
+// testFileContentSaver is the conformance suite every storage backend has to pass.
+func testFileContentSaver(t *testing.T, saver FileContentSaver) {
+	ctx := context.Background()
+
+	t.Run("SaveOpenDelete", func(t *testing.T) {
+		content, hash := randomContent(100)
+
+		exists, err := saver.Exists(ctx, hash)
+		require.NoError(t, err)
+		require.False(t, exists)
+
+		err = saver.Save(ctx, hash, bytes.NewReader(content), int64(len(content)))
+		require.NoError(t, err)
+
+		exists, err = saver.Exists(ctx, hash)
+		require.NoError(t, err)
+		require.True(t, exists)
+
+		requireContent(t, saver, hash, content)
+
+		err = saver.Delete(ctx, hash)
+		require.NoError(t, err)
+
+		exists, err = saver.Exists(ctx, hash)
+		require.NoError(t, err)
+		require.False(t, exists)
+	})
+
+	t.Run("SaveTwice", func(t *testing.T) {
+		content, hash := randomContent(100)
+
+		require.NoError(t, saver.Save(ctx, hash, bytes.NewReader(content), int64(len(content))))
+		require.NoError(t, saver.Save(ctx, hash, bytes.NewReader(content), int64(len(content))))
+		requireContent(t, saver, hash, content)
+		require.NoError(t, saver.Delete(ctx, hash))
+	})
+
+	t.Run("EmptyContent", func(t *testing.T) {
+		content, hash := randomContent(0)
+
+		require.NoError(t, saver.Save(ctx, hash, bytes.NewReader(content), int64(len(content))))
+		requireContent(t, saver, hash, content)
+		require.NoError(t, saver.Delete(ctx, hash))
+	})
+
+	t.Run("LargeContent", func(t *testing.T) {
+		content, hash := randomContent(2*minS3PartSize + 1)
+
+		require.NoError(t, saver.Save(ctx, hash, bytes.NewReader(content), int64(len(content))))
+		requireContent(t, saver, hash, content)
+		require.NoError(t, saver.Delete(ctx, hash))
+	})
+
+	t.Run("Offset", func(t *testing.T) {
+		content, hash := randomContent(2*pgChunkSize + 10)
+
+		require.NoError(t, saver.Save(ctx, hash, bytes.NewReader(content), int64(len(content))))
+		for _, offset := range []int64{0, 1, pgChunkSize, pgChunkSize + 7, 2 * pgChunkSize, int64(len(content))} {
+			requireContentAt(t, saver, hash, content, offset)
+		}
+		require.NoError(t, saver.Delete(ctx, hash))
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		_, hash := randomContent(100)
+
+		_, err := saver.Open(ctx, hash, 0)
+		require.Error(t, err)
+
+		_, err = saver.Open(ctx, hash, 2*pgChunkSize)
+		require.Error(t, err)
+
+		require.NoError(t, saver.Delete(ctx, hash))
+	})
+
+	t.Run("Walk", func(t *testing.T) {
+		content, hash := randomContent(100)
+		require.NoError(t, saver.Save(ctx, hash, bytes.NewReader(content), int64(len(content))))
+
+		found := false
+		err := saver.Walk(ctx, func(walked string, modTime time.Time) error {
+			require.NoError(t, validateBlobHash(walked))
+			if walked == hash {
+				found = true
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.True(t, found)
+
+		require.NoError(t, saver.Delete(ctx, hash))
+	})
+
+	t.Run("InvalidHash", func(t *testing.T) {
+		for _, hash := range []string{"", "ABC", "../../etc/passwd", "../" + util.RandomString(61)} {
+			require.Error(t, saver.Save(ctx, hash, bytes.NewReader(nil), 0))
+
+			_, err := saver.Open(ctx, hash, 0)
+			require.Error(t, err)
+
+			_, err = saver.Exists(ctx, hash)
+			require.Error(t, err)
+
+			require.Error(t, saver.Delete(ctx, hash))
+		}
+	})
+}
+
+func TestDiskFileContentSaver(t *testing.T) {
+	testFileContentSaver(t, NewDiskFileContentSaver(t.TempDir()))
+}
+
+func TestDiskCleanPartial(t *testing.T) {
+	folder := t.TempDir()
+	saver := NewDiskFileContentSaver(folder).(*DiskFileContentSaver)
+	ctx := context.Background()
+
+	content, hash := randomContent(100)
+	require.NoError(t, saver.Save(ctx, hash, bytes.NewReader(content), int64(len(content))))
+
+	dir := filepath.Join(folder, hash[:2])
+	old := filepath.Join(dir, hash+".tmp-1")
+	fresh := filepath.Join(dir, hash+".tmp-2")
+	require.NoError(t, os.WriteFile(old, content[:10], 0o600))
+	require.NoError(t, os.WriteFile(fresh, content[:10], 0o600))
+	require.NoError(t, os.Chtimes(old, time.Now().Add(-time.Hour), time.Now().Add(-time.Hour)))
+
+	before := time.Now().Add(-time.Minute)
+
+	removed, err := saver.CleanPartial(ctx, before, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{old}, removed)
+	require.FileExists(t, old)
+
+	removed, err = saver.CleanPartial(ctx, before, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{old}, removed)
+	require.NoFileExists(t, old)
+	require.FileExists(t, fresh)
+
+	// Temporary files are not blobs.
+	var walked []string
+	err = saver.Walk(ctx, func(hash string, modTime time.Time) error {
+		walked = append(walked, hash)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{hash}, walked)
+	requireContent(t, saver, hash, content)
+}
+
+func TestBlobReleaser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	store := mockdb.NewMockStore(ctrl)
+	saver := NewDiskFileContentSaver(t.TempDir())
+	content, hash := randomContent(100)
+	require.NoError(t, saver.Save(ctx, hash, bytes.NewReader(content), int64(len(content))))
+
+	// The content stays while the transaction may still roll back.
+	releaser := newBlobReleaser(ctx, store, saver)
+	require.NoError(t, releaser.AfterRelease(nil, db.Blob{Hash: hash}))
+	requireContent(t, saver, hash, content)
+
+	store.EXPECT().
+		DeleteOrphanContentTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.DeleteOrphanContentTxParams) (bool, error) {
+			require.Equal(t, hash, arg.Hash)
+			return true, arg.DeleteContent(nil)
+		})
+	releaser.Committed()
+	exists, err := saver.Exists(ctx, hash)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// Nothing is left for a second call.
+	releaser.Committed()
+}
@@ -1,9 +1,16 @@
 package server
 
 import (
-	"bytes"
 	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"io"
+	"os"
+	"strings"
+	"time"
 
 	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
 	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
@@ -11,31 +18,44 @@ import (
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
-	maxImageSize = 1 << 10
+	chunkSize = 64 << 10
+	// pendingFileCleanupTimeout bounds the removal of the row of a failed
+	// upload, which cannot use the request context gone with the client.
+	pendingFileCleanupTimeout = 10 * time.Second
+	// pendingFileHeartbeat is how often a running upload refreshes its row,
+	// the storage check leaves rows refreshed within its grace alone.
+	pendingFileHeartbeat = time.Minute
 )
 
+var errVersionGone = errors.New("file version is gone")
+
 type FileServer struct {
 	fileStore        db.Store
 	fileContentSaver FileContentSaver
+	quota            Quota
+	compression      string
+	keyWrapper       *KeyWrapper
 	pb.UnimplementedFileServer
 }
 
-func NewFileServer(fileStore db.Store) *FileServer {
-	fileContentSaver := NewDiskFileContentSaver("fs")
+func NewFileServer(fileStore db.Store, fileContentSaver FileContentSaver, quota Quota, compression string, keyWrapper *KeyWrapper) *FileServer {
 	return &FileServer{
 		fileStore,
 		fileContentSaver,
+		quota,
+		compression,
+		keyWrapper,
 		pb.UnimplementedFileServer{},
 	}
 }
 
 func (s *FileServer) CreateFile(stream pb.File_CreateFileServer) error {
-	// TODO(): make as TX !!!
 	ctx := stream.Context()
-	username, err := getUsernameFromContext(ctx)
+	caller, err := principalFromContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -45,20 +65,49 @@ func (s *FileServer) CreateFile(stream pb.File_CreateFileServer) error {
 		return logError(status.Errorf(codes.Unknown, "cannot receive file info"))
 	}
 
-	account, err := s.fileStore.GetAccount(ctx, username)
+	account, err := s.fileStore.GetAccountByID(ctx, caller.AccountID)
 	if err != nil {
 		return logError(status.Errorf(codes.Internal, "cannot get account from db. Err :%s", err))
 	}
-	log.Info().Msgf("receive an CreateFile request from user %s", username)
+	log.Info().Msgf("receive an CreateFile request from user %s", caller.Username)
+
+	expectedHash := strings.ToLower(req.GetInfo().GetSha256())
+	if expectedHash != "" && !blobHashRegexp.MatchString(expectedHash) {
+		return logError(status.Errorf(codes.InvalidArgument, "sha256 must be a hex encoded SHA-256 digest"))
+	}
+
+	dir, name, err := fileKey(req.GetInfo())
+	if err != nil {
+		return err
+	}
 
-	arg := db.CreateFileParams{
-		AccountID: account.ID,
-		Filename:  req.GetInfo().Filename,
-		Filepath:  req.GetInfo().Filepath,
+	userMetadata := req.GetInfo().GetMetadata().GetUser()
+	err = validateUserMetadata(userMetadata)
+	if err != nil {
+		return err
+	}
+
+	quota := s.quota.forAccount(account)
+
+	arg := db.CreateFileTxParams{
+		CreateFileParams: db.CreateFileParams{
+			AccountID: account.ID,
+			Filename:  name,
+			Filepath:  dir,
+		},
+		MaxFiles:    quota.Files,
+		Metadata:    userMetadata,
+		MaxMetadata: maxMetadataEntries,
 	}
 
-	file, err := s.fileStore.CreateFile(ctx, arg)
+	file, err := s.fileStore.CreateFileTx(ctx, arg)
 	if err != nil {
+		if err == db.ErrQuotaExceeded {
+			return logError(status.Errorf(codes.ResourceExhausted, "files quota exceeded: %d files allowed", quota.Files))
+		}
+		if err == db.ErrPathConflict {
+			return logError(status.Errorf(codes.AlreadyExists, "path '/%s/%s' is taken by a file or a directory", dir, name))
+		}
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
 			case "unique_violation":
@@ -68,88 +117,873 @@ func (s *FileServer) CreateFile(stream pb.File_CreateFileServer) error {
 		return logError(status.Errorf(codes.Internal, "failed to create file: Err: %s", err))
 	}
 
-	fileData := bytes.Buffer{}
-	fileSize := 0
+	// The pending row takes the path, a failed upload gives it back so the
+	// client can retry right away.
+	attached := false
+	defer func() {
+		if !attached {
+			s.removePendingFile(file)
+		}
+	}()
+	stopHeartbeat := s.touchPendingFile(ctx, file)
+	defer stopHeartbeat()
+
+	maxSize, err := s.bytesLeft(ctx, quota, file.AccountID)
+	if err != nil {
+		return err
+	}
+
+	content, err := receiveFileContent(ctx, func() ([]byte, error) {
+		req, err := stream.Recv()
+		return req.GetChunkData(), err
+	}, maxSize)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	if expectedHash != "" && expectedHash != content.hash {
+		return logError(status.Errorf(codes.DataLoss, "checksum mismatch: expected %s, received %s", expectedHash, content.hash))
+	}
+
+	err = s.attachContent(ctx, quota, file, content, nil)
+	if err != nil {
+		return err
+	}
+	attached = true
+
+	fileMetadata, err := s.getFileMetadata(ctx, file.ID)
+	if err != nil {
+		return err
+	}
+
+	res := &pb.CreateFileResponse{
+		Info: &pb.FileInfo{
+			Filename: file.Filename,
+			Filepath: file.Filepath,
+			Ready:    markFileReady(),
+			Sha256:   content.hash,
+			Metadata: fileMetadata,
+		},
+		Size: uint32(content.size),
+	}
+
+	err = stream.SendAndClose(res)
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot send response: %v", err))
+	}
+
+	log.Info().Msgf("Created file '/%s/%s' with size %d", file.Filepath, file.Filename, content.size)
+	return nil
+}
+
+// removePendingFile deletes the row CreateFile made for an upload which
+// failed. A row the upload got ready meanwhile is kept.
+func (s *FileServer) removePendingFile(file db.File) {
+	ctx, cancel := context.WithTimeout(context.Background(), pendingFileCleanupTimeout)
+	defer cancel()
+
+	err := s.fileStore.DeletePendingFile(ctx, file.ID)
+	if err != nil {
+		log.Error().Err(err).Msgf("cannot remove file '/%s/%s' after failed upload", file.Filepath, file.Filename)
+	}
+}
+
+// touchPendingFile refreshes the row CreateFile made every
+// pendingFileHeartbeat until the returned func is called, so the storage
+// check does not take a long upload for a failed one.
+func (s *FileServer) touchPendingFile(ctx context.Context, file db.File) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(pendingFileHeartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				err := s.fileStore.TouchFile(ctx, db.TouchFileParams{ID: file.ID, TouchedAt: now})
+				if err != nil && ctx.Err() == nil {
+					log.Error().Err(err).Msgf("cannot refresh pending file '/%s/%s'", file.Filepath, file.Filename)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (s *FileServer) UpdateFile(stream pb.File_UpdateFileServer) error {
+	ctx := stream.Context()
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := stream.Recv()
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot receive file info"))
+	}
+
+	account, err := s.fileStore.GetAccountByID(ctx, caller.AccountID)
+	if err != nil {
+		return logError(status.Errorf(codes.Internal, "cannot get account from db. Err :%s", err))
+	}
+	log.Info().Msgf("receive an UpdateFile request from user %s", caller.Username)
+
+	expectedHash := strings.ToLower(req.GetInfo().GetSha256())
+	if expectedHash != "" && !blobHashRegexp.MatchString(expectedHash) {
+		return logError(status.Errorf(codes.InvalidArgument, "sha256 must be a hex encoded SHA-256 digest"))
+	}
+
+	file, err := s.getReadyFile(ctx, account.ID, req.GetInfo())
+	if err != nil {
+		return err
+	}
+
+	userMetadata := req.GetInfo().GetMetadata().GetUser()
+	err = validateUserMetadata(userMetadata)
+	if err != nil {
+		return err
+	}
+
+	quota := s.quota.forAccount(account)
+
+	maxSize, err := s.bytesLeft(ctx, quota, file.AccountID)
+	if err != nil {
+		return err
+	}
+
+	content, err := receiveFileContent(ctx, func() ([]byte, error) {
+		req, err := stream.Recv()
+		return req.GetChunkData(), err
+	}, maxSize)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	if expectedHash != "" && expectedHash != content.hash {
+		return logError(status.Errorf(codes.DataLoss, "checksum mismatch: expected %s, received %s", expectedHash, content.hash))
+	}
+
+	err = s.attachContent(ctx, quota, file, content, userMetadata)
+	if err != nil {
+		return err
+	}
+
+	fileMetadata, err := s.getFileMetadata(ctx, file.ID)
+	if err != nil {
+		return err
+	}
+
+	res := &pb.UpdateFileResponse{
+		Info: &pb.FileInfo{
+			Filename: file.Filename,
+			Filepath: file.Filepath,
+			Ready:    markFileReady(),
+			Sha256:   content.hash,
+			Metadata: fileMetadata,
+		},
+	}
+
+	err = stream.SendAndClose(res)
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot send response: %v", err))
+	}
+
+	log.Info().Msgf("Updated file '/%s/%s' with size %d", file.Filepath, file.Filename, content.size)
+	return nil
+}
+
+func (s *FileServer) DeleteFile(ctx context.Context, in *pb.DeleteFileRequest) (*pb.DeleteFileResponse, error) {
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Msgf("receive an DeleteFile request from user %s", caller.Username)
+
+	dir, name, err := fileKey(in.GetInfo())
+	if err != nil {
+		return nil, err
+	}
+
+	releaser := newBlobReleaser(ctx, s.fileStore, s.fileContentSaver)
+	arg := db.DeleteFileTxParams{
+		DeleteFileParams: db.DeleteFileParams{
+			Filename:  name,
+			AccountID: caller.AccountID,
+			Filepath:  dir,
+		},
+		AfterRelease: releaser.AfterRelease,
+	}
+
+	file, err := s.fileStore.DeleteFileTx(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, logError(status.Error(codes.NotFound, "cannot find file"))
+		}
+		return nil, logError(status.Errorf(codes.Internal, "cannot delete file: %v", err))
+	}
+	releaser.Committed()
+
+	return &pb.DeleteFileResponse{
+		Info: &pb.FileInfo{
+			Filename: file.Filename,
+			Filepath: file.Filepath,
+		},
+	}, nil
+}
+
+func (s *FileServer) GetFile(in *pb.GetFileRequest, stream pb.File_GetFileServer) error {
+	ctx := stream.Context()
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msgf("receive an GetFile request from user %s", caller.Username)
+
+	file, err := s.getReadyFile(ctx, caller.AccountID, in.GetKey())
+	if err != nil {
+		return err
+	}
+
+	info := fileToProto(file)
+	info.Metadata, err = s.getFileMetadata(ctx, file.ID)
+	if err != nil {
+		return err
+	}
+
+	blobHash := file.BlobHash.String
+	if version := in.GetVersion(); version != 0 && version != file.Version {
+		fileVersion, err := s.getFileVersion(ctx, file, version)
+		if err != nil {
+			return err
+		}
+		blobHash = fileVersion.BlobHash
+		info.Version = fileVersion.Version
+		info.Sha256 = fileVersion.BlobHash
+	}
+
+	blob, err := s.fileStore.GetBlob(ctx, blobHash)
+	if err != nil {
+		return logError(status.Errorf(codes.Internal, "cannot get file blob: %v", err))
+	}
+
+	// The rest of the recorded metadata describes the current version.
+	info.Metadata.Size = blob.Size
+
+	offset, length, err := contentRange(in, blob.Size)
+	if err != nil {
+		return err
+	}
+	// A range is checked by the encryption layer only, the digest covers
+	// the whole content.
+	ranged := length != blob.Size
+
+	var content io.ReadCloser = io.NopCloser(strings.NewReader(""))
+	if length > 0 || !ranged {
+		content, err = s.openBlobContent(ctx, blob, in.GetRaw(), offset)
+		if err != nil {
+			if errors.Is(err, errCorrupted) {
+				return logError(status.Errorf(codes.DataLoss, "stored content is corrupted: %v", err))
+			}
+			return logError(status.Errorf(codes.Internal, "cannot open file content: %v", err))
+		}
+	}
+	defer content.Close()
+
+	var reader io.Reader = content
+	if ranged {
+		reader = io.LimitReader(content, length)
+	}
+
+	info.Compression = CodecIdentity
+	if in.GetRaw() {
+		info.Compression = blob.Codec
+	}
+
+	err = stream.Send(&pb.GetFileResponse{
+		Data: &pb.GetFileResponse_Info{
+			Info: info,
+		},
+	})
+	if err != nil {
+		return logError(status.Errorf(codes.Unknown, "cannot send file info: %v", err))
+	}
+
+	// The digest is checked once more on the way out, so content damaged in
+	// the storage ends the stream with DataLoss instead of a silent success.
+	// Raw content is left for the client to check after decompression.
+	hash := sha256.New()
+	buffer := make([]byte, chunkSize)
 
 	for {
-		err := contextError(stream.Context())
+		err := contextError(ctx)
 		if err != nil {
 			return err
 		}
+
+		n, err := reader.Read(buffer)
+		if n > 0 {
+			hash.Write(buffer[:n])
+
+			sendErr := stream.Send(&pb.GetFileResponse{
+				Data: &pb.GetFileResponse_ChunkData{
+					ChunkData: buffer[:n],
+				},
+			})
+			if sendErr != nil {
+				return logError(status.Errorf(codes.Unknown, "cannot send chunk data: %v", sendErr))
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, errCorrupted) {
+			return logError(status.Errorf(codes.DataLoss, "stored content is corrupted: %v", err))
+		}
+		if err != nil {
+			return logError(status.Errorf(codes.Internal, "cannot read file content: %v", err))
+		}
+	}
+
+	if digest := hex.EncodeToString(hash.Sum(nil)); !in.GetRaw() && !ranged && digest != blob.Hash {
+		return logError(status.Errorf(codes.DataLoss, "stored content is corrupted: expected %s, read %s", blob.Hash, digest))
+	}
+
+	return nil
+}
+
+// contentRange returns the offset and the length of the content range asked
+// for in the request, the whole content by default.
+func contentRange(in *pb.GetFileRequest, size int64) (int64, int64, error) {
+	offset, length := in.GetOffset(), in.GetLength()
+	if offset == 0 && length == 0 {
+		return 0, size, nil
+	}
+
+	// The range is in the bytes of the plain content, raw content does not
+	// have a known size.
+	if in.GetRaw() {
+		return 0, 0, logError(status.Error(codes.InvalidArgument, "byte range cannot be combined with raw content"))
+	}
+
+	if offset < 0 || length < 0 || offset > size || length > size-offset {
+		return 0, 0, logError(status.Errorf(codes.OutOfRange, "range of %d bytes at offset %d is out of %d bytes of content", length, offset, size))
+	}
+
+	if length == 0 {
+		length = size - offset
+	}
+
+	return offset, length, nil
+}
+
+func (s *FileServer) ListFile(ctx context.Context, in *pb.ListFileRequest) (*pb.ListFileResponse, error) {
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Msgf("receive an ListFile request from user %s", caller.Username)
+
+	// An empty filepath lists all the files, "/" lists the root directory.
+	filter := in.GetInfo().GetFilepath()
+	dir, err := dirPath(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := s.fileStore.ListFiles(ctx, caller.AccountID)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot list files: %v", err))
+	}
+
+	res := &pb.ListFileResponse{}
+	var fileIDs []int64
+	for _, file := range files {
+		if !file.Ready {
+			continue
+		}
+		if filter != "" && file.Filepath != dir {
+			continue
+		}
+
+		res.Info = append(res.Info, fileToProto(file))
+		fileIDs = append(fileIDs, file.ID)
+	}
+
+	rows, err := s.fileStore.ListFilesMetadata(ctx, fileIDs)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot get files metadata: %v", err))
+	}
+
+	byFile := make(map[int64][]db.FilesMetadatum)
+	for _, row := range rows {
+		byFile[row.FileID] = append(byFile[row.FileID], row)
+	}
+	for i, info := range res.Info {
+		info.Metadata = metadataToProto(byFile[fileIDs[i]])
+	}
+
+	return res, nil
+}
+
+func (s *FileServer) ListFileVersions(ctx context.Context, in *pb.ListFileVersionsRequest) (*pb.ListFileVersionsResponse, error) {
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Msgf("receive an ListFileVersions request from user %s", caller.Username)
+
+	file, err := s.getReadyFile(ctx, caller.AccountID, in.GetKey())
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.fileStore.ListFileVersions(ctx, file.ID)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot list file versions: %v", err))
+	}
+
+	blob, err := s.fileStore.GetBlob(ctx, file.BlobHash.String)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot get file blob: %v", err))
+	}
+
+	res := &pb.ListFileVersionsResponse{
+		Versions: []*pb.FileVersion{{
+			Version: file.Version,
+			Size:    blob.Size,
+			Sha256:  blob.Hash,
+			Current: true,
+		}},
+	}
+	for _, version := range versions {
+		blob, err := s.fileStore.GetBlob(ctx, version.BlobHash)
+		if err != nil {
+			return nil, logError(status.Errorf(codes.Internal, "cannot get file blob: %v", err))
+		}
+
+		res.Versions = append(res.Versions, &pb.FileVersion{
+			Version:    version.Version,
+			Size:       blob.Size,
+			Sha256:     blob.Hash,
+			ReplacedAt: timestamppb.New(version.CreatedAt),
+		})
+	}
+
+	return res, nil
+}
+
+// RestoreFileVersion makes the content of an older version current again.
+// The content it replaces becomes a version itself, so a restore is undone
+// by restoring that version.
+func (s *FileServer) RestoreFileVersion(ctx context.Context, in *pb.RestoreFileVersionRequest) (*pb.RestoreFileVersionResponse, error) {
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.fileStore.GetAccountByID(ctx, caller.AccountID)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot get account from db. Err :%s", err))
+	}
+	log.Info().Msgf("receive an RestoreFileVersion request from user %s", caller.Username)
+
+	file, err := s.getReadyFile(ctx, account.ID, in.GetKey())
+	if err != nil {
+		return nil, err
+	}
+
+	if in.GetVersion() == file.Version {
+		return &pb.RestoreFileVersionResponse{Info: fileToProto(file)}, nil
+	}
+
+	version, err := s.getFileVersion(ctx, file, in.GetVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := s.fileStore.GetBlob(ctx, version.BlobHash)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot get file blob: %v", err))
+	}
+
+	head, err := s.blobHead(ctx, blob)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot read file content: %v", err))
+	}
+
+	quota := s.quota.forAccount(account)
+
+	arg := db.AttachFileBlobTxParams{
+		AccountID:      account.ID,
+		FileID:         file.ID,
+		Hash:           blob.Hash,
+		Size:           blob.Size,
+		MaxBytes:       quota.Bytes,
+		SystemMetadata: uploadMetadata(ctx, file.Filename, head, blob.Size),
+		NewBlob: func() (string, []byte, error) {
+			// The version row holds a reference of its own, the blob is
+			// only gone when the retention dropped the version meanwhile.
+			return "", nil, errVersionGone
+		},
+	}
+
+	_, err = s.fileStore.AttachFileBlobTx(ctx, arg)
+	if err != nil {
+		if err == db.ErrQuotaExceeded {
+			return nil, logError(status.Errorf(codes.ResourceExhausted, "storage quota exceeded: %d bytes allowed", quota.Bytes))
+		}
+		if err == errVersionGone {
+			return nil, logError(status.Errorf(codes.NotFound, "cannot find file version %d", in.GetVersion()))
+		}
+		return nil, logError(status.Errorf(codes.Internal, "cannot restore file version: %v", err))
+	}
+
+	info := fileToProto(file)
+	info.Sha256 = blob.Hash
+	info.Version = file.Version + 1
+	info.Metadata, err = s.getFileMetadata(ctx, file.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Msgf("Restored file '/%s/%s' to version %d", file.Filepath, file.Filename, version.Version)
+	return &pb.RestoreFileVersionResponse{Info: info}, nil
+}
+
+// getReadyFile returns the uploaded file with the given key.
+func (s *FileServer) getReadyFile(ctx context.Context, accountID int64, key *pb.FileInfo) (db.File, error) {
+	dir, name, err := fileKey(key)
+	if err != nil {
+		return db.File{}, err
+	}
+
+	file, err := s.fileStore.GetFile(ctx, db.GetFileParams{
+		Filename:  name,
+		AccountID: accountID,
+		Filepath:  dir,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return file, logError(status.Error(codes.NotFound, "cannot find file"))
+		}
+		return file, logError(status.Errorf(codes.Internal, "cannot get file: %v", err))
+	}
+
+	if !file.Ready || !file.BlobHash.Valid {
+		return file, logError(status.Error(codes.FailedPrecondition, "file is not ready yet"))
+	}
+
+	return file, nil
+}
+
+// getFileVersion returns an older version of the file.
+func (s *FileServer) getFileVersion(ctx context.Context, file db.File, version int64) (db.FileVersion, error) {
+	fileVersion, err := s.fileStore.GetFileVersion(ctx, db.GetFileVersionParams{
+		FileID:  file.ID,
+		Version: version,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fileVersion, logError(status.Errorf(codes.NotFound, "cannot find file version %d", version))
+		}
+		return fileVersion, logError(status.Errorf(codes.Internal, "cannot get file version: %v", err))
+	}
+
+	return fileVersion, nil
+}
+
+// uploadedContent is the file content received from a client stream and
+// spooled to a temporary file.
+type uploadedContent struct {
+	file *os.File
+	hash string
+	size int64
+	// sniffed keeps the first sniffLen bytes seen while receiving.
+	sniffed []byte
+}
+
+// head returns the beginning of the content for type sniffing.
+func (c *uploadedContent) head() []byte {
+	return c.sniffed
+}
+
+func (c *uploadedContent) Close() error {
+	c.file.Close()
+	return os.Remove(c.file.Name())
+}
+
+// receiveFileContent reads chunks with recv until io.EOF, hashing them on the
+// way. A negative maxSize means the size is not limited by the quota.
+func receiveFileContent(ctx context.Context, recv func() ([]byte, error), maxSize int64) (*uploadedContent, error) {
+	fileData, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot create temporary file: %v", err))
+	}
+	content := &uploadedContent{file: fileData}
+
+	hash := sha256.New()
+	fileWriter := io.MultiWriter(fileData, hash)
+
+	for {
+		err := contextError(ctx)
+		if err != nil {
+			content.Close()
+			return nil, err
+		}
 		log.Info().Msg("waiting to receive more filedata")
 
-		req, err := stream.Recv()
+		chunk, err := recv()
 		if err == io.EOF {
 			log.Print("no more data")
 			break
 		}
 		if err != nil {
-			return logError(status.Errorf(codes.Unknown, "cannot receive chunk data: %v", err))
+			content.Close()
+			return nil, logError(status.Errorf(codes.Unknown, "cannot receive chunk data: %v", err))
 		}
 
-		chunk := req.GetChunkData()
-		size := len(chunk)
+		size := int64(len(chunk))
 
 		log.Printf("received a chunk with size: %d", size)
 
-		fileSize += size
-		if fileSize > maxImageSize {
-			return logError(status.Errorf(codes.InvalidArgument, "image is too large: %d > %d", fileSize, maxImageSize))
+		content.size += size
+		if maxSize >= 0 && content.size > maxSize {
+			content.Close()
+			return nil, logError(status.Errorf(codes.ResourceExhausted, "storage quota exceeded: %d bytes left", maxSize))
+		}
+
+		if left := sniffLen - len(content.sniffed); left > 0 {
+			if left > len(chunk) {
+				left = len(chunk)
+			}
+			content.sniffed = append(content.sniffed, chunk[:left]...)
 		}
 
-		_, err = fileData.Write(chunk)
+		_, err = fileWriter.Write(chunk)
 		if err != nil {
-			return logError(status.Errorf(codes.Internal, "cannot write chunk data: %v", err))
+			content.Close()
+			return nil, logError(status.Errorf(codes.Internal, "cannot write chunk data: %v", err))
 		}
 	}
 
-	err = s.fileContentSaver.Save(file.Filename, file.Filepath, fileData)
+	_, err = fileData.Seek(0, io.SeekStart)
 	if err != nil {
-		return logError(status.Errorf(codes.Internal, "cannot save file content to storage: %v", err))
+		content.Close()
+		return nil, logError(status.Errorf(codes.Internal, "cannot rewind temporary file: %v", err))
 	}
 
-	arg2 := db.MarkFileReadyParams{
-		Filename:  file.Filename,
-		AccountID: account.ID,
+	content.hash = hex.EncodeToString(hash.Sum(nil))
+	return content, nil
+}
+
+// bytesLeft returns how many bytes the file content may take without going
+// over the quota, or -1 if the quota is unlimited. The content being replaced
+// stays as a file version, so it is not given back. Parallel uploads may all
+// pass this check, the final word belongs to AttachFileBlobTx.
+func (s *FileServer) bytesLeft(ctx context.Context, quota Quota, accountID int64) (int64, error) {
+	if quota.Bytes <= 0 {
+		return -1, nil
 	}
 
-	err = s.fileStore.MarkFileReady(ctx, arg2)
+	usage, err := s.fileStore.GetFilesUsage(ctx, accountID)
 	if err != nil {
-		return logError(status.Errorf(codes.Internal, "cannot prepare file for work: %v", err))
+		return 0, logError(status.Errorf(codes.Internal, "cannot get files usage: %v", err))
 	}
 
-	res := &pb.CreateFileResponse{
-		Info: &pb.FileInfo{
-			Filename: req.GetInfo().Filename,
-			Filepath: req.GetInfo().Filepath,
-			Ready:    markFileReady(),
+	left := quota.Bytes - usage.TotalBytes
+	if left < 0 {
+		left = 0
+	}
+
+	return left, nil
+}
+
+// attachContent stores the uploaded content as the file blob and marks the file
+// ready. The metadata of the content is recorded with it, userMetadata entries
+// are added to those of the file.
+func (s *FileServer) attachContent(ctx context.Context, quota Quota, file db.File, content *uploadedContent, userMetadata map[string]string) error {
+	head := content.head()
+
+	arg := db.AttachFileBlobTxParams{
+		AccountID:      file.AccountID,
+		FileID:         file.ID,
+		Hash:           content.hash,
+		Size:           content.size,
+		MaxBytes:       quota.Bytes,
+		SystemMetadata: uploadMetadata(ctx, file.Filename, head, content.size),
+		Metadata:       userMetadata,
+		MaxMetadata:    maxMetadataEntries,
+		// The codec and the key are only chosen for a new blob, a blob
+		// referenced before keeps its own with its stored content.
+		NewBlob: func() (string, []byte, error) {
+			codec := chooseCodec(s.compression, file.Filename, head)
+			if s.keyWrapper == nil {
+				return codec, nil, nil
+			}
+
+			wrappedKey, err := s.keyWrapper.NewDataKey(content.hash)
+			if err != nil {
+				return "", nil, fmt.Errorf("cannot create file key: %w", err)
+			}
+			return codec, wrappedKey, nil
+		},
+		AfterCreate: func(q db.Querier, blob db.Blob) error {
+			return s.saveBlobContent(ctx, contentSaverInTx(s.fileContentSaver, q), blob, content)
 		},
-		Size: uint32(fileSize),
 	}
 
-	err = stream.SendAndClose(res)
+	_, err := s.fileStore.AttachFileBlobTx(ctx, arg)
 	if err != nil {
-		return logError(status.Errorf(codes.Unknown, "cannot send response: %v", err))
+		if err == db.ErrQuotaExceeded {
+			return logError(status.Errorf(codes.ResourceExhausted, "storage quota exceeded: %d bytes allowed", quota.Bytes))
+		}
+		if err == db.ErrMetadataLimit {
+			return logError(status.Errorf(codes.ResourceExhausted, "metadata limit exceeded: %d entries allowed", maxMetadataEntries))
+		}
+		return logError(status.Errorf(codes.Internal, "cannot save file content to storage: %v", err))
 	}
 
-	log.Info().Msgf("Created file '/%s/%s' with size %s", file.Filename, arg.Filepath, fileSize)
 	return nil
 }
 
-func (s *FileServer) UpdateFile(stream pb.File_UpdateFileServer) error {
-	return status.Errorf(codes.Unimplemented, "method UpdateFile not implemented")
-}
+// saveBlobContent puts the content of a new blob to the storage. Content of a
+// released blob with the same hash may still be stored with another key or
+// codec, so it is always overwritten. The content is compressed first and then
+// encrypted when the blob has a key. It is called with the blob lock held.
+func (s *FileServer) saveBlobContent(ctx context.Context, saver FileContentSaver, blob db.Blob, content *uploadedContent) error {
+	var stored io.Reader = content.file
+	size := content.size
+
+	if blob.Codec != CodecIdentity {
+		encoded, err := os.CreateTemp("", "encode-*")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(encoded.Name())
+		defer encoded.Close()
+
+		err = compressContent(blob.Codec, encoded, content.file)
+		if err != nil {
+			return fmt.Errorf("cannot compress content: %w", err)
+		}
+
+		size, err = encoded.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+
+		_, err = encoded.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+		stored = encoded
+	}
 
-func (s *FileServer) DeleteFile(context.Context, *pb.DeleteFileRequest) (*pb.DeleteFileResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DeleteFile not implemented")
+	if blob.WrappedKey != nil {
+		key, err := s.unwrapKey(blob)
+		if err != nil {
+			return err
+		}
+
+		// The encrypted size is known up front, so the content is encrypted
+		// on the fly while the storage reads it.
+		reader, writer := io.Pipe()
+		defer reader.Close()
+
+		go func(plain io.Reader) {
+			encrypter, err := newEncryptingWriter(writer, key)
+			if err == nil {
+				_, err = io.Copy(encrypter, plain)
+			}
+			if err == nil {
+				err = encrypter.Close()
+			}
+			writer.CloseWithError(err)
+		}(stored)
+
+		stored = reader
+		size = encryptedSize(size)
+	}
+
+	return saver.Save(ctx, blob.Hash, stored, size)
 }
 
-func (s *FileServer) GetFile(*pb.GetFileRequest, pb.File_GetFileServer) error {
-	return status.Errorf(codes.Unimplemented, "method GetFile not implemented")
+// openBlobContent opens the stored blob content at the given offset of the
+// plain content, decrypting it and, unless raw content is asked for,
+// decompressing it. Plain and encrypted content is read from the storage
+// starting at the offset, compressed content is decoded from the start and the
+// bytes before the offset are dropped.
+func (s *FileServer) openBlobContent(ctx context.Context, blob db.Blob, raw bool, offset int64) (io.ReadCloser, error) {
+	// position is the offset of the plain content the storage is read from.
+	position := offset
+	if blob.Codec != CodecIdentity {
+		position = 0
+	}
+
+	storedOffset := position
+	segment := uint64(position / segmentSize)
+	if blob.WrappedKey != nil {
+		position = int64(segment) * segmentSize
+		storedOffset = int64(segment) * sealedSegmentSize
+	}
+
+	content, err := s.fileContentSaver.Open(ctx, blob.Hash, storedOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	if blob.WrappedKey != nil {
+		key, err := s.unwrapKey(blob)
+		if err != nil {
+			content.Close()
+			return nil, err
+		}
+
+		content, err = decryptContent(content, key, segment)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !raw {
+		decompressed, err := decompressContent(blob.Codec, content)
+		if err != nil {
+			content.Close()
+			return nil, fmt.Errorf("cannot decompress content: %w", err)
+		}
+		content = decompressed
+	}
+
+	_, err = io.CopyN(io.Discard, content, offset-position)
+	if err != nil {
+		content.Close()
+		return nil, fmt.Errorf("cannot skip to offset %d: %w", offset, err)
+	}
+
+	return content, nil
 }
 
-func (s *FileServer) ListFile(context.Context, *pb.ListFileRequest) (*pb.ListFileResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ListFile not implemented")
+func (s *FileServer) unwrapKey(blob db.Blob) ([]byte, error) {
+	if s.keyWrapper == nil {
+		return nil, fmt.Errorf("blob %s is encrypted, but no encryption key is configured", blob.Hash)
+	}
+
+	return s.keyWrapper.Unwrap(blob.Hash, blob.WrappedKey)
 }
 
 func contextError(ctx context.Context) error {
@@ -170,6 +1004,16 @@ func logError(err error) error {
 	return err
 }
 
+func fileToProto(file db.File) *pb.FileInfo {
+	return &pb.FileInfo{
+		Filename: file.Filename,
+		Filepath: file.Filepath,
+		Ready:    &file.Ready,
+		Sha256:   file.BlobHash.String,
+		Version:  file.Version,
+	}
+}
+
 func markFileReady() *bool {
 	b := true
 	return &b
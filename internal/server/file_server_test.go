@@ -0,0 +1,222 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mockdb "github.com/Jay-T/go-devops-advanced-diploma/db/mock"
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestContentRange(t *testing.T) {
+	testCases := []struct {
+		name   string
+		req    *pb.GetFileRequest
+		offset int64
+		length int64
+		code   codes.Code
+	}{
+		{name: "Whole", req: &pb.GetFileRequest{}, offset: 0, length: 100},
+		{name: "WholeRaw", req: &pb.GetFileRequest{Raw: true}, offset: 0, length: 100},
+		{name: "Tail", req: &pb.GetFileRequest{Offset: 90}, offset: 90, length: 10},
+		{name: "Head", req: &pb.GetFileRequest{Length: 10}, offset: 0, length: 10},
+		{name: "Middle", req: &pb.GetFileRequest{Offset: 10, Length: 80}, offset: 10, length: 80},
+		{name: "AtEnd", req: &pb.GetFileRequest{Offset: 100}, offset: 100, length: 0},
+		{name: "PastEnd", req: &pb.GetFileRequest{Offset: 101}, code: codes.OutOfRange},
+		{name: "TooLong", req: &pb.GetFileRequest{Offset: 10, Length: 91}, code: codes.OutOfRange},
+		{name: "NegativeOffset", req: &pb.GetFileRequest{Offset: -1}, code: codes.OutOfRange},
+		{name: "NegativeLength", req: &pb.GetFileRequest{Length: -1}, code: codes.OutOfRange},
+		{name: "Raw", req: &pb.GetFileRequest{Offset: 10, Raw: true}, code: codes.InvalidArgument},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			offset, length, err := contentRange(tc.req, 100)
+			if tc.code != codes.OK {
+				require.Equal(t, tc.code, status.Code(err))
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.offset, offset)
+			require.Equal(t, tc.length, length)
+		})
+	}
+}
+
+func TestBlobContentOffset(t *testing.T) {
+	ctx := context.Background()
+	content, hash := randomContent(3*segmentSize + 10)
+
+	path := filepath.Join(t.TempDir(), "upload")
+	require.NoError(t, os.WriteFile(path, content, 0600))
+	upload, err := os.Open(path)
+	require.NoError(t, err)
+	defer upload.Close()
+
+	for _, codec := range []string{CodecIdentity, CodecZstd} {
+		for _, encrypted := range []bool{false, true} {
+			server := NewFileServer(nil, NewDiskFileContentSaver(t.TempDir()), Quota{}, codec, nil)
+			blob := db.Blob{Hash: hash, Size: int64(len(content)), Codec: codec}
+			if encrypted {
+				server.keyWrapper = newTestKeyWrapper(t)
+				blob.WrappedKey, err = server.keyWrapper.NewDataKey(hash)
+				require.NoError(t, err)
+			}
+
+			_, err = upload.Seek(0, io.SeekStart)
+			require.NoError(t, err)
+			err = server.saveBlobContent(ctx, server.fileContentSaver, blob, &uploadedContent{file: upload, hash: hash, size: blob.Size})
+			require.NoError(t, err)
+
+			for _, offset := range []int64{0, 1, segmentSize, 2*segmentSize + 7, blob.Size} {
+				r, err := server.openBlobContent(ctx, blob, false, offset)
+				require.NoError(t, err)
+
+				got, err := io.ReadAll(r)
+				require.NoError(t, err)
+				require.NoError(t, r.Close())
+				require.Equal(t, content[offset:], got, "codec %s, encrypted %v, offset %d", codec, encrypted, offset)
+			}
+		}
+	}
+}
+
+func TestSaveBlobContentOverwritesReleased(t *testing.T) {
+	ctx := context.Background()
+	saver := NewDiskFileContentSaver(t.TempDir())
+	server := NewFileServer(nil, saver, Quota{}, CodecIdentity, nil)
+
+	content, hash := randomContent(100)
+	path := filepath.Join(t.TempDir(), "upload")
+	require.NoError(t, os.WriteFile(path, content, 0600))
+	upload, err := os.Open(path)
+	require.NoError(t, err)
+	defer upload.Close()
+
+	// A released blob of the same hash left its content, stored with
+	// another key or codec.
+	stale := make([]byte, len(content))
+	require.NoError(t, saver.Save(ctx, hash, bytes.NewReader(stale), int64(len(stale))))
+
+	blob := db.Blob{Hash: hash, Size: int64(len(content)), Codec: CodecIdentity, Refcount: 1}
+	err = server.saveBlobContent(ctx, saver, blob, &uploadedContent{file: upload, hash: hash, size: blob.Size})
+	require.NoError(t, err)
+	requireContent(t, saver, hash, content)
+}
+
+type testCreateFileStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	reqs []*pb.CreateFileRequest
+	err  error
+}
+
+func (s *testCreateFileStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testCreateFileStream) Recv() (*pb.CreateFileRequest, error) {
+	if len(s.reqs) == 0 {
+		return nil, s.err
+	}
+	req := s.reqs[0]
+	s.reqs = s.reqs[1:]
+	return req, nil
+}
+
+func (s *testCreateFileStream) SendAndClose(*pb.CreateFileResponse) error {
+	return nil
+}
+
+func TestCreateFileRemovesPendingRow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := NewFileServer(store, NewDiskFileContentSaver(t.TempDir()), Quota{Bytes: 10}, CodecIdentity, nil)
+
+	account := db.Account{ID: 1, Username: "user"}
+	file := db.File{ID: 5, AccountID: account.ID, Filename: "notes.txt"}
+	store.EXPECT().GetAccountByID(gomock.Any(), account.ID).Return(account, nil).AnyTimes()
+	store.EXPECT().CreateFileTx(gomock.Any(), gomock.Any()).Return(file, nil).AnyTimes()
+	store.EXPECT().GetFilesUsage(gomock.Any(), account.ID).Return(db.GetFilesUsageRow{}, nil).AnyTimes()
+
+	info := &pb.CreateFileRequest{Data: &pb.CreateFileRequest_Info{Info: &pb.FileInfo{Filename: file.Filename}}}
+	chunk := func(size int) *pb.CreateFileRequest {
+		return &pb.CreateFileRequest{Data: &pb.CreateFileRequest_ChunkData{ChunkData: make([]byte, size)}}
+	}
+
+	testCases := []struct {
+		name   string
+		stream *testCreateFileStream
+		code   codes.Code
+	}{
+		{
+			name:   "StreamError",
+			stream: &testCreateFileStream{reqs: []*pb.CreateFileRequest{info, chunk(5)}, err: status.Error(codes.Canceled, "gone")},
+			code:   codes.Unknown,
+		},
+		{
+			name:   "OverQuota",
+			stream: &testCreateFileStream{reqs: []*pb.CreateFileRequest{info, chunk(11)}, err: io.EOF},
+			code:   codes.ResourceExhausted,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// The request context is done already, the row goes anyway.
+			ctx, cancel := context.WithCancel(principalContext(account.ID, account.Username))
+			tc.stream.ctx = ctx
+			store.EXPECT().
+				DeletePendingFile(gomock.Any(), file.ID).
+				DoAndReturn(func(ctx context.Context, id int64) error {
+					require.NoError(t, ctx.Err())
+					return nil
+				})
+
+			err := server.CreateFile(tc.stream)
+			cancel()
+			require.Equal(t, tc.code, status.Code(err))
+		})
+	}
+}
+
+func TestReceiveLargeFileContent(t *testing.T) {
+	// Content past the S3 part size goes through, only the quota limits it.
+	const size = 6 << 20
+	chunk := make([]byte, chunkSize)
+	chunks := func() func() ([]byte, error) {
+		sent := 0
+		return func() ([]byte, error) {
+			if sent >= size {
+				return nil, io.EOF
+			}
+			sent += len(chunk)
+			return chunk, nil
+		}
+	}
+
+	content, err := receiveFileContent(context.Background(), chunks(), -1)
+	require.NoError(t, err)
+	defer content.Close()
+	require.Equal(t, int64(size), content.size)
+
+	_, err = receiveFileContent(context.Background(), chunks(), size-1)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
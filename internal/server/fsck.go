@@ -0,0 +1,304 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/rs/zerolog/log"
+)
+
+// fsckBatchSize is the number of blobs checked per query.
+const fsckBatchSize = 100
+
+// FsckOptions controls a storage check. Uploads heard of within Grace may
+// still be running and are left alone. Without Repair the findings are only reported.
+// Missing content is only repaired with DropMissing, which deletes the files
+// and versions referring to it; a storage which is down or misconfigured
+// looks the same as lost content, so only the fsck subcommand sets it.
+type FsckOptions struct {
+	Grace       time.Duration
+	Repair      bool
+	DropMissing bool
+}
+
+func NewFsckOptions(cfg *Config) FsckOptions {
+	return FsckOptions{
+		Grace:  cfg.FsckGrace,
+		Repair: cfg.FsckRepair,
+	}
+}
+
+// FsckReport lists what a storage check found. Repaired counts the findings
+// fixed, a finding resolving itself meanwhile is not counted.
+type FsckReport struct {
+	// StaleFiles are file rows whose upload never finished.
+	StaleFiles []db.File
+	// PartialContent are leftovers of content saves which never finished.
+	PartialContent []string
+	// OrphanContent are hashes of stored content no blob row refers to.
+	OrphanContent []string
+	// MissingContent are hashes of blob rows whose content is not stored.
+	MissingContent []string
+	Repaired       int
+}
+
+// Problems returns the number of findings.
+func (r FsckReport) Problems() int {
+	return len(r.StaleFiles) + len(r.PartialContent) + len(r.OrphanContent) + len(r.MissingContent)
+}
+
+// Fsck reconciles the file rows with the content storage.
+func (s *FileServer) Fsck(ctx context.Context, opts FsckOptions) (FsckReport, error) {
+	var report FsckReport
+	before := time.Now().Add(-opts.Grace)
+
+	err := s.checkStaleFiles(ctx, opts, before, &report)
+	if err != nil {
+		return report, err
+	}
+
+	err = s.checkPartialContent(ctx, opts, before, &report)
+	if err != nil {
+		return report, err
+	}
+
+	err = s.checkOrphanContent(ctx, opts, before, &report)
+	if err != nil {
+		return report, err
+	}
+
+	err = s.checkMissingContent(ctx, opts, &report)
+	return report, err
+}
+
+// checkStaleFiles finds the rows CreateFile left not ready after a failed upload.
+func (s *FileServer) checkStaleFiles(ctx context.Context, opts FsckOptions, before time.Time, report *FsckReport) error {
+	files, err := s.fileStore.ListStaleFiles(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		log.Warn().Msgf("fsck: file '/%s/%s' of account %d was never uploaded", file.Filepath, file.Filename, file.AccountID)
+		report.StaleFiles = append(report.StaleFiles, file)
+
+		if !opts.Repair {
+			continue
+		}
+
+		// Nothing is deleted when the upload finished or showed a sign of
+		// life meanwhile.
+		rows, err := s.fileStore.DeleteStaleFile(ctx, db.DeleteStaleFileParams{
+			ID:        file.ID,
+			TouchedAt: before,
+		})
+		if err != nil {
+			return err
+		}
+		report.Repaired += int(rows)
+	}
+
+	return nil
+}
+
+// checkPartialContent finds content the storage started to save but never finished.
+func (s *FileServer) checkPartialContent(ctx context.Context, opts FsckOptions, before time.Time, report *FsckReport) error {
+	cleaner, ok := s.fileContentSaver.(PartialContentCleaner)
+	if !ok {
+		return nil
+	}
+
+	names, err := cleaner.CleanPartial(ctx, before, !opts.Repair)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		log.Warn().Msgf("fsck: partially saved content '%s'", name)
+	}
+	report.PartialContent = append(report.PartialContent, names...)
+	if opts.Repair {
+		report.Repaired += len(names)
+	}
+
+	return nil
+}
+
+// checkOrphanContent finds stored content without a blob row, e.g. left by an
+// upload whose transaction rolled back after the content was saved.
+func (s *FileServer) checkOrphanContent(ctx context.Context, opts FsckOptions, before time.Time, report *FsckReport) error {
+	var orphans []string
+
+	err := s.fileContentSaver.Walk(ctx, func(hash string, modTime time.Time) error {
+		if !modTime.IsZero() && !modTime.Before(before) {
+			return nil
+		}
+
+		_, err := s.fileStore.GetBlob(ctx, hash)
+		if err == sql.ErrNoRows {
+			orphans = append(orphans, hash)
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range orphans {
+		log.Warn().Msgf("fsck: content '%s' belongs to no blob", hash)
+		report.OrphanContent = append(report.OrphanContent, hash)
+
+		if !opts.Repair {
+			continue
+		}
+
+		// The blob row is checked again under the blob lock, an upload may
+		// have taken the content meanwhile.
+		deleted, err := s.fileStore.DeleteOrphanContentTx(ctx, db.DeleteOrphanContentTxParams{
+			Hash: hash,
+			DeleteContent: func(q db.Querier) error {
+				return contentSaverInTx(s.fileContentSaver, q).Delete(ctx, hash)
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if deleted {
+			report.Repaired++
+		}
+	}
+
+	return nil
+}
+
+// checkMissingContent finds blob rows whose content is gone from the storage.
+// The content cannot be brought back, so the repair removes the file versions
+// and the files referring to the blob when DropMissing is set.
+func (s *FileServer) checkMissingContent(ctx context.Context, opts FsckOptions, report *FsckReport) error {
+	after := ""
+
+	for {
+		blobs, err := s.fileStore.ListBlobs(ctx, db.ListBlobsParams{
+			Hash:  after,
+			Limit: fsckBatchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, blob := range blobs {
+			exists, err := s.fileContentSaver.Exists(ctx, blob.Hash)
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+
+			log.Warn().Msgf("fsck: content of blob '%s' is missing", blob.Hash)
+			report.MissingContent = append(report.MissingContent, blob.Hash)
+
+			if !opts.Repair || !opts.DropMissing {
+				continue
+			}
+
+			err = s.dropMissingBlob(ctx, blob.Hash)
+			if err != nil {
+				return err
+			}
+			report.Repaired++
+		}
+
+		if len(blobs) < fsckBatchSize {
+			return nil
+		}
+		after = blobs[len(blobs)-1].Hash
+	}
+}
+
+// dropMissingBlob removes everything referring to the blob, which removes the
+// blob row with the last reference.
+func (s *FileServer) dropMissingBlob(ctx context.Context, hash string) error {
+	releaser := newBlobReleaser(ctx, s.fileStore, s.fileContentSaver)
+	defer releaser.Committed()
+
+	versions, err := s.fileStore.ListBlobFileVersions(ctx, hash)
+	if err != nil {
+		return err
+	}
+
+	for _, version := range versions {
+		_, err = s.fileStore.DeleteFileVersionTx(ctx, db.DeleteFileVersionTxParams{
+			AccountID:    version.AccountID,
+			ID:           version.ID,
+			AfterRelease: releaser.AfterRelease,
+		})
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		log.Warn().Msgf("fsck: removed version %d of file '/%s/%s' of account %d", version.Version, version.Filepath, version.Filename, version.AccountID)
+	}
+
+	files, err := s.fileStore.ListBlobFiles(ctx, sql.NullString{String: hash, Valid: true})
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		_, err = s.fileStore.DeleteFileTx(ctx, db.DeleteFileTxParams{
+			DeleteFileParams: db.DeleteFileParams{
+				Filename:  file.Filename,
+				AccountID: file.AccountID,
+				Filepath:  file.Filepath,
+			},
+			AfterRelease: releaser.AfterRelease,
+		})
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		log.Warn().Msgf("fsck: removed file '/%s/%s' of account %d", file.Filepath, file.Filename, file.AccountID)
+	}
+
+	return nil
+}
+
+// RunFsck checks the storage every interval until ctx is done. Missing content
+// is only reported, the files referring to it are never dropped unattended.
+func (s *FileServer) RunFsck(ctx context.Context, opts FsckOptions, interval time.Duration) {
+	opts.DropMissing = false
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := s.Fsck(ctx, opts)
+			if err != nil {
+				log.Error().Err(err).Msg("cannot check file storage")
+			}
+			if report.Problems() > 0 {
+				log.Info().Msgf("Storage check found %d problems, repaired %d", report.Problems(), report.Repaired)
+			}
+		}
+	}
+}
+
+// Fsck checks the storage configured in cfg once, for the fsck subcommand.
+// With repair on, it drops the files whose content is missing as well.
+func Fsck(ctx context.Context, cfg *Config, store db.Store) (FsckReport, error) {
+	fileContentSaver, err := NewFileContentSaver(cfg, store)
+	if err != nil {
+		return FsckReport{}, err
+	}
+
+	fileServer := NewFileServer(store, fileContentSaver, NewQuota(cfg), cfg.Compression, nil)
+	opts := NewFsckOptions(cfg)
+	opts.DropMissing = opts.Repair
+	return fileServer.Fsck(ctx, opts)
+}
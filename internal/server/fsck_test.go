@@ -0,0 +1,141 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/Jay-T/go-devops-advanced-diploma/db/mock"
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFsck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	store := mockdb.NewMockStore(ctrl)
+	saver := NewDiskFileContentSaver(t.TempDir())
+	server := NewFileServer(store, saver, Quota{}, CodecIdentity, nil)
+	opts := FsckOptions{Grace: time.Hour, Repair: true, DropMissing: true}
+
+	content, stored := randomContent(100)
+	require.NoError(t, saver.Save(ctx, stored, bytes.NewReader(content), int64(len(content))))
+	_, missing := randomContent(100)
+
+	stale := db.File{ID: 1, AccountID: 1, Filename: "a", Ready: false}
+	var staleBefore time.Time
+	store.EXPECT().
+		ListStaleFiles(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, before time.Time) ([]db.File, error) {
+			require.WithinDuration(t, time.Now().Add(-opts.Grace), before, time.Minute)
+			staleBefore = before
+			return []db.File{stale}, nil
+		})
+	// An upload refreshed since the listing is not deleted.
+	store.EXPECT().
+		DeleteStaleFile(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, arg db.DeleteStaleFileParams) (int64, error) {
+			require.Equal(t, db.DeleteStaleFileParams{ID: stale.ID, TouchedAt: staleBefore}, arg)
+			return 1, nil
+		})
+
+	// Content saved just now may belong to a running upload, so the
+	// orphan check leaves it alone.
+	store.EXPECT().GetBlob(gomock.Any(), gomock.Any()).Times(0)
+
+	store.EXPECT().
+		ListBlobs(gomock.Any(), db.ListBlobsParams{Hash: "", Limit: fsckBatchSize}).
+		Return([]db.Blob{{Hash: missing}}, nil)
+
+	files := []db.File{{ID: 2, AccountID: 1, Filename: "b", BlobHash: sql.NullString{String: missing, Valid: true}}}
+	store.EXPECT().ListBlobFileVersions(gomock.Any(), missing).Return(nil, nil)
+	store.EXPECT().ListBlobFiles(gomock.Any(), files[0].BlobHash).Return(files, nil)
+	store.EXPECT().
+		DeleteFileTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, arg db.DeleteFileTxParams) (db.File, error) {
+			require.Equal(t, files[0].Filename, arg.Filename)
+			return files[0], nil
+		})
+
+	report, err := server.Fsck(ctx, opts)
+	require.NoError(t, err)
+	require.Equal(t, []db.File{stale}, report.StaleFiles)
+	require.Empty(t, report.OrphanContent)
+	require.Equal(t, []string{missing}, report.MissingContent)
+	require.Equal(t, 2, report.Problems())
+	require.Equal(t, 2, report.Repaired)
+}
+
+func TestFsckKeepsFilesOfMissingContent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	store := mockdb.NewMockStore(ctrl)
+	saver := NewDiskFileContentSaver(t.TempDir())
+	server := NewFileServer(store, saver, Quota{}, CodecIdentity, nil)
+
+	_, missing := randomContent(100)
+
+	store.EXPECT().ListStaleFiles(gomock.Any(), gomock.Any()).Return(nil, nil)
+	store.EXPECT().
+		ListBlobs(gomock.Any(), db.ListBlobsParams{Hash: "", Limit: fsckBatchSize}).
+		Return([]db.Blob{{Hash: missing}}, nil)
+
+	// The periodic check repairs, but a storage which is not mounted must
+	// not cost the users their files.
+	store.EXPECT().ListBlobFileVersions(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().ListBlobFiles(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().DeleteFileTx(gomock.Any(), gomock.Any()).Times(0)
+
+	report, err := server.Fsck(ctx, FsckOptions{Grace: time.Hour, Repair: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{missing}, report.MissingContent)
+	require.Zero(t, report.Repaired)
+}
+
+func TestFsckOrphanContent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	store := mockdb.NewMockStore(ctrl)
+	saver := NewDiskFileContentSaver(t.TempDir())
+	server := NewFileServer(store, saver, Quota{}, CodecIdentity, nil)
+
+	content, orphan := randomContent(100)
+	require.NoError(t, saver.Save(ctx, orphan, bytes.NewReader(content), int64(len(content))))
+
+	store.EXPECT().ListStaleFiles(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+	store.EXPECT().GetBlob(gomock.Any(), orphan).Return(db.Blob{}, sql.ErrNoRows).Times(2)
+	store.EXPECT().ListBlobs(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+	store.EXPECT().
+		DeleteOrphanContentTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, arg db.DeleteOrphanContentTxParams) (bool, error) {
+			require.Equal(t, orphan, arg.Hash)
+			return true, arg.DeleteContent(store)
+		})
+
+	// A grace period in the future makes all content old enough.
+	report, err := server.Fsck(ctx, FsckOptions{Grace: -time.Hour})
+	require.NoError(t, err)
+	require.Equal(t, []string{orphan}, report.OrphanContent)
+	require.Zero(t, report.Repaired)
+
+	exists, err := saver.Exists(ctx, orphan)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	report, err = server.Fsck(ctx, FsckOptions{Grace: -time.Hour, Repair: true})
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Repaired)
+
+	exists, err = saver.Exists(ctx, orphan)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+const jwksPath = "/.well-known/jwks.json"
+
+// JWKSHandler serves the public token keys as a JWKS document.
+func JWKSHandler(manager *JWTManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		// Verifiers pick up rotated keys within a few minutes.
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		err := json.NewEncoder(w).Encode(struct {
+			Keys []JWK `json:"keys"`
+		}{manager.PublicKeys()})
+		if err != nil {
+			log.Error().Err(err).Msg("cannot write jwks")
+		}
+	})
+}
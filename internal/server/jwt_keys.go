@@ -0,0 +1,157 @@
+package server
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	minJWTSecretSize = 32
+	minJWTRSABits    = 2048
+)
+
+// jwtKeyFile is the layout of the signing key file:
+//
+//	{"keys": [
+//	  {"kid": "2024-06", "alg": "EdDSA", "private_key_file": "2024-06.pem"},
+//	  {"kid": "2024-01", "alg": "HS256", "secret": "<hex>"}
+//	]}
+//
+// The first key signs new tokens. Rotation puts a new key in front, removed
+// keys keep verifying the tokens signed with them until those expire. Private
+// keys are PEM files, relative paths start at the directory of the key file.
+type jwtKeyFile struct {
+	Keys []struct {
+		ID             string `json:"kid"`
+		Algorithm      string `json:"alg"`
+		Secret         string `json:"secret"`
+		PrivateKeyFile string `json:"private_key_file"`
+	} `json:"keys"`
+}
+
+func decodeJWTSecret(secret string) ([]byte, error) {
+	key, err := hex.DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("jwt key must be hex encoded: %w", err)
+	}
+	if len(key) < minJWTSecretSize {
+		return nil, fmt.Errorf("jwt key must be at least %d bytes, got %d", minJWTSecretSize, len(key))
+	}
+	return key, nil
+}
+
+// loadJWTPrivateKey reads a PKCS #8 or PKCS #1 PEM private key.
+func loadJWTPrivateKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in '%s'", path)
+	}
+
+	var key interface{}
+	if block.Type == "RSA PRIVATE KEY" {
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse private key '%s': %w", path, err)
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < minJWTRSABits {
+			return nil, fmt.Errorf("RSA key '%s' must be at least %d bits", path, minJWTRSABits)
+		}
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T in '%s'", key, path)
+	}
+}
+
+// LoadJWTKeyFile reads the signing keys from the file.
+func LoadJWTKeyFile(path string) ([]JWTKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file jwtKeyFile
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse jwt key file '%s': %w", path, err)
+	}
+
+	keys := make([]JWTKey, 0, len(file.Keys))
+	for _, entry := range file.Keys {
+		key := JWTKey{ID: entry.ID, Algorithm: entry.Algorithm}
+
+		switch entry.Algorithm {
+		case "", JWTAlgorithmHS256:
+			key.Secret, err = decodeJWTSecret(entry.Secret)
+		default:
+			keyPath := entry.PrivateKeyFile
+			if keyPath != "" && !filepath.IsAbs(keyPath) {
+				keyPath = filepath.Join(filepath.Dir(path), keyPath)
+			}
+			key.PrivateKey, err = loadJWTPrivateKey(keyPath)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key '%s': %w", entry.ID, err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// LoadJWTKeys returns the signing keys of the config. The key file wins over
+// the single HMAC key, without either a random Ed25519 key is used and tokens
+// stop working when the server restarts.
+func LoadJWTKeys(cfg *Config) ([]JWTKey, error) {
+	if cfg.JWTKeyFile != "" {
+		return LoadJWTKeyFile(cfg.JWTKeyFile)
+	}
+
+	if cfg.JWTKey != "" {
+		secret, err := decodeJWTSecret(cfg.JWTKey)
+		if err != nil {
+			return nil, err
+		}
+		// The id follows the key, so tokens of a replaced key are told apart.
+		id := sha256.Sum256(secret)
+		return []JWTKey{{ID: hex.EncodeToString(id[:8]), Secret: secret}}, nil
+	}
+
+	log.Warn().Msg("jwt key is not set, tokens will not survive a restart")
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 8)
+	_, err = rand.Read(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return []JWTKey{{ID: hex.EncodeToString(id), Algorithm: JWTAlgorithmEdDSA, PrivateKey: privateKey}}, nil
+}
@@ -1,42 +1,212 @@
 package server
 
 import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"math/big"
+	"sort"
+	"sync"
 	"time"
 
 	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
 	"github.com/dgrijalva/jwt-go"
 )
 
+// Token signing algorithms.
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+	JWTAlgorithmEdDSA = "EdDSA"
+)
+
+// JWTKey is a key tokens are signed with, ID goes to the kid header.
+type JWTKey struct {
+	ID string
+	// Algorithm is HS256, RS256 or EdDSA, empty means HS256.
+	Algorithm string
+	// Secret is the key of HS256.
+	Secret []byte
+	// PrivateKey is the *rsa.PrivateKey of RS256 or the ed25519.PrivateKey of EdDSA.
+	PrivateKey crypto.Signer
+}
+
+// signing returns the method and the key the token is signed with.
+func (key JWTKey) signing() (jwt.SigningMethod, interface{}, error) {
+	switch key.Algorithm {
+	case "", JWTAlgorithmHS256:
+		if len(key.Secret) == 0 {
+			return nil, nil, fmt.Errorf("key '%s' has no secret", key.ID)
+		}
+		return jwt.SigningMethodHS256, key.Secret, nil
+	case JWTAlgorithmRS256:
+		privateKey, ok := key.PrivateKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, nil, fmt.Errorf("key '%s' is not an RSA key", key.ID)
+		}
+		return jwt.SigningMethodRS256, privateKey, nil
+	case JWTAlgorithmEdDSA:
+		privateKey, ok := key.PrivateKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, nil, fmt.Errorf("key '%s' is not an Ed25519 key", key.ID)
+		}
+		return SigningMethodEdDSA, privateKey, nil
+	default:
+		return nil, nil, fmt.Errorf("key '%s' has unknown algorithm '%s'", key.ID, key.Algorithm)
+	}
+}
+
+type jwtVerifyKey struct {
+	method jwt.SigningMethod
+	// key is the HMAC secret or the public key.
+	key interface{}
+	// retireAt is set for keys dropped by SetKeys, tokens signed with them
+	// are accepted until they expire.
+	retireAt time.Time
+}
+
+type jwtSigningKey struct {
+	id     string
+	method jwt.SigningMethod
+	key    interface{}
+}
+
 type JWTManager struct {
-	secretKey     string
 	tokenDuration time.Duration
+	issuer        string
+	audience      string
+
+	mu         sync.RWMutex
+	signingKey jwtSigningKey
+	keys       map[string]jwtVerifyKey
 }
 
-func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
-	return &JWTManager{
-		secretKey:     secretKey,
+// NewJWTManager signs tokens with the first of the keys and accepts tokens
+// signed with any of them. The tokens are issued by issuer for audience.
+func NewJWTManager(keys []JWTKey, tokenDuration time.Duration, issuer string, audience string) (*JWTManager, error) {
+	manager := &JWTManager{
 		tokenDuration: tokenDuration,
+		issuer:        issuer,
+		audience:      audience,
+		keys:          make(map[string]jwtVerifyKey),
+	}
+
+	err := manager.SetKeys(keys)
+	if err != nil {
+		return nil, err
 	}
+
+	return manager, nil
+}
+
+// SetKeys replaces the keys of the manager, the first one signs new tokens.
+// Keys left out keep verifying the tokens already issued until those expire.
+func (manager *JWTManager) SetKeys(keys []JWTKey) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("no signing keys")
+	}
+
+	var signingKey jwtSigningKey
+	updated := make(map[string]jwtVerifyKey, len(keys))
+	for i, key := range keys {
+		if key.ID == "" {
+			return fmt.Errorf("signing key without id")
+		}
+		if _, ok := updated[key.ID]; ok {
+			return fmt.Errorf("duplicate signing key id '%s'", key.ID)
+		}
+
+		method, signKey, err := key.signing()
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			signingKey = jwtSigningKey{id: key.ID, method: method, key: signKey}
+		}
+
+		verifyKey := signKey
+		if key.PrivateKey != nil {
+			verifyKey = key.PrivateKey.Public()
+		}
+		updated[key.ID] = jwtVerifyKey{method: method, key: verifyKey}
+	}
+
+	now := time.Now()
+
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	for id, key := range manager.keys {
+		if _, ok := updated[id]; ok {
+			continue
+		}
+		if key.retireAt.IsZero() {
+			key.retireAt = now.Add(manager.tokenDuration)
+		}
+		if now.Before(key.retireAt) {
+			updated[id] = key
+		}
+	}
+
+	manager.signingKey = signingKey
+	manager.keys = updated
+	return nil
+}
+
+// SigningKeyID returns the id of the key new tokens are signed with.
+func (manager *JWTManager) SigningKeyID() string {
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+
+	return manager.signingKey.id
 }
 
+// Claims of the access token. Subject and Username both hold the username,
+// the latter is kept for the clients reading it. Id is the jti the token is
+// revoked by.
 type Claims struct {
 	jwt.StandardClaims
-	Username string `json:"username"`
+	Username  string `json:"username"`
+	AccountID int64  `json:"account_id,omitempty"`
+	// SessionEpoch is the session epoch of the account at issue time, the
+	// token dies when the account revokes all its sessions.
+	SessionEpoch int64 `json:"session_epoch,omitempty"`
 }
 
 func (manager *JWTManager) GeneratetToken(acc *db.Account) (string, error) {
-	expirationTime := time.Now().Add(manager.tokenDuration)
+	now := time.Now()
+
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		return "", fmt.Errorf("Could not generate token for user sign in request.")
+	}
 
 	claims := &Claims{
-		Username: acc.Username,
+		Username:     acc.Username,
+		AccountID:    acc.ID,
+		SessionEpoch: acc.SessionEpoch,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expirationTime.Unix(),
+			Issuer:    manager.issuer,
+			Audience:  manager.audience,
+			Subject:   acc.Username,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(manager.tokenDuration).Unix(),
+			Id:        hex.EncodeToString(id),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(manager.secretKey))
+	manager.mu.RLock()
+	key := manager.signingKey
+	manager.mu.RUnlock()
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	tokenString, err := token.SignedString(key.key)
 
 	if err != nil {
 		return "", fmt.Errorf("Could not generate token for user sign in request.")
@@ -50,12 +220,18 @@ func (manager *JWTManager) Verify(accessToken string) (*Claims, error) {
 		accessToken,
 		&Claims{},
 		func(token *jwt.Token) (interface{}, error) {
-			_, ok := token.Method.(*jwt.SigningMethodHMAC)
-			if !ok {
+			kid, _ := token.Header["kid"].(string)
+			key, err := manager.verifyKey(kid)
+			if err != nil {
+				return nil, err
+			}
+
+			// The algorithm comes with the key, not with the token.
+			if token.Method.Alg() != key.method.Alg() {
 				return nil, fmt.Errorf("unexpected token signing method")
 			}
 
-			return []byte(manager.secretKey), nil
+			return key.key, nil
 		},
 	)
 
@@ -68,5 +244,89 @@ func (manager *JWTManager) Verify(accessToken string) (*Claims, error) {
 		return nil, fmt.Errorf("invalid token claims")
 	}
 
+	if !claims.VerifyIssuer(manager.issuer, true) {
+		return nil, fmt.Errorf("invalid token issuer")
+	}
+	if !claims.VerifyAudience(manager.audience, true) {
+		return nil, fmt.Errorf("invalid token audience")
+	}
+	if claims.Subject == "" || claims.Subject != claims.Username {
+		return nil, fmt.Errorf("invalid token subject")
+	}
+	// Tokens are revoked by id, one without it could not be.
+	if claims.Id == "" {
+		return nil, fmt.Errorf("invalid token id")
+	}
+
 	return claims, nil
 }
+
+func (manager *JWTManager) verifyKey(kid string) (jwtVerifyKey, error) {
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+
+	key, ok := manager.keys[kid]
+	if !ok {
+		return jwtVerifyKey{}, fmt.Errorf("unknown signing key '%s'", kid)
+	}
+	if !key.retireAt.IsZero() && !time.Now().Before(key.retireAt) {
+		return jwtVerifyKey{}, fmt.Errorf("signing key '%s' is retired", kid)
+	}
+
+	return key, nil
+}
+
+// JWK is a public key in the JSON Web Key form.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	// N and E are the modulus and the exponent of an RSA key.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Curve and X are the curve and the key of an OKP key.
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// PublicKeys returns the keys verifying the tokens in use, sorted by id.
+// HMAC keys are secret and left out.
+func (manager *JWTManager) PublicKeys() []JWK {
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+
+	now := time.Now()
+	res := []JWK{}
+	for id, key := range manager.keys {
+		if !key.retireAt.IsZero() && !now.Before(key.retireAt) {
+			continue
+		}
+
+		switch publicKey := key.key.(type) {
+		case *rsa.PublicKey:
+			res = append(res, JWK{
+				KeyType:   "RSA",
+				KeyID:     id,
+				Algorithm: JWTAlgorithmRS256,
+				Use:       "sig",
+				N:         base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			res = append(res, JWK{
+				KeyType:   "OKP",
+				KeyID:     id,
+				Algorithm: JWTAlgorithmEdDSA,
+				Use:       "sig",
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(publicKey),
+			})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].KeyID < res[j].KeyID
+	})
+	return res
+}
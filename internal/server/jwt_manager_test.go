@@ -0,0 +1,184 @@
+package server
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestJWTKey(id string) JWTKey {
+	return JWTKey{ID: id, Secret: bytes.Repeat([]byte(id), minJWTSecretSize)}
+}
+
+func newTestJWTManager(t *testing.T, tokenDuration time.Duration) *JWTManager {
+	manager, err := NewJWTManager([]JWTKey{newTestJWTKey("a")}, tokenDuration, "issuer", "audience")
+	require.NoError(t, err)
+	return manager
+}
+
+func TestJWTManagerRotation(t *testing.T) {
+	manager := newTestJWTManager(t, time.Minute)
+	account := &db.Account{Username: "user"}
+
+	oldToken, err := manager.GeneratetToken(account)
+	require.NoError(t, err)
+
+	require.NoError(t, manager.SetKeys([]JWTKey{newTestJWTKey("b")}))
+	require.Equal(t, "b", manager.SigningKeyID())
+
+	newToken, err := manager.GeneratetToken(account)
+	require.NoError(t, err)
+
+	// The old key is retired, its tokens keep working until they expire.
+	for _, token := range []string{oldToken, newToken} {
+		claims, err := manager.Verify(token)
+		require.NoError(t, err)
+		require.Equal(t, "user", claims.Username)
+	}
+
+	// A key with the id of a known one but another secret does not verify.
+	forged, err := NewJWTManager([]JWTKey{{ID: "b", Secret: bytes.Repeat([]byte("x"), minJWTSecretSize)}}, time.Minute, "issuer", "audience")
+	require.NoError(t, err)
+	token, err := forged.GeneratetToken(account)
+	require.NoError(t, err)
+	_, err = manager.Verify(token)
+	require.Error(t, err)
+
+	require.Error(t, manager.SetKeys(nil))
+	require.Error(t, manager.SetKeys([]JWTKey{newTestJWTKey("c"), newTestJWTKey("c")}))
+}
+
+func TestJWTManagerRetiredKeyExpires(t *testing.T) {
+	manager := newTestJWTManager(t, 0)
+	token, err := manager.GeneratetToken(&db.Account{Username: "user"})
+	require.NoError(t, err)
+
+	require.NoError(t, manager.SetKeys([]JWTKey{newTestJWTKey("b")}))
+	_, err = manager.Verify(token)
+	require.Error(t, err)
+	require.NotContains(t, manager.keys, "a")
+}
+
+func TestLoadJWTKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	secret := strings.Repeat("ab", minJWTSecretSize)
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys": [{"kid": "new", "secret": "`+secret+`"}, {"kid": "old", "secret": "`+secret+`"}]}`), 0o600))
+
+	keys, err := LoadJWTKeys(&Config{JWTKeyFile: path, JWTKey: "ignored"})
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.Equal(t, "new", keys[0].ID)
+
+	keys, err = LoadJWTKeys(&Config{JWTKey: secret})
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.NotEmpty(t, keys[0].ID)
+
+	_, err = LoadJWTKeys(&Config{JWTKey: "abcd"})
+	require.Error(t, err)
+
+	keys, err = LoadJWTKeys(&Config{})
+	require.NoError(t, err)
+	require.Equal(t, JWTAlgorithmEdDSA, keys[0].Algorithm)
+}
+
+func writeTestPrivateKey(t *testing.T, dir string, name string, key interface{}) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o600))
+}
+
+func TestJWTManagerAsymmetric(t *testing.T) {
+	dir := t.TempDir()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	writeTestPrivateKey(t, dir, "ed.pem", edKey)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, minJWTRSABits)
+	require.NoError(t, err)
+	writeTestPrivateKey(t, dir, "rsa.pem", rsaKey)
+
+	path := filepath.Join(dir, "keys.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys": [
+		{"kid": "ed", "alg": "EdDSA", "private_key_file": "ed.pem"},
+		{"kid": "rsa", "alg": "RS256", "private_key_file": "rsa.pem"},
+		{"kid": "hmac", "alg": "HS256", "secret": "`+strings.Repeat("ab", minJWTSecretSize)+`"}
+	]}`), 0o600))
+
+	keys, err := LoadJWTKeyFile(path)
+	require.NoError(t, err)
+	require.Len(t, keys, 3)
+
+	account := &db.Account{ID: 7, Username: "user"}
+	for _, order := range [][]JWTKey{keys, {keys[1], keys[0], keys[2]}} {
+		manager, err := NewJWTManager(order, time.Minute, "issuer", "audience")
+		require.NoError(t, err)
+
+		token, err := manager.GeneratetToken(account)
+		require.NoError(t, err)
+
+		claims, err := manager.Verify(token)
+		require.NoError(t, err)
+		require.Equal(t, "user", claims.Subject)
+		require.Equal(t, int64(7), claims.AccountID)
+		require.Equal(t, "issuer", claims.Issuer)
+		require.Equal(t, "audience", claims.Audience)
+		require.NotEmpty(t, claims.Id)
+		require.NotZero(t, claims.IssuedAt)
+	}
+
+	manager, err := NewJWTManager(keys, time.Minute, "issuer", "audience")
+	require.NoError(t, err)
+
+	// The secret key is not published.
+	jwks := manager.PublicKeys()
+	require.Len(t, jwks, 2)
+	require.Equal(t, JWK{KeyType: "OKP", KeyID: "ed", Algorithm: JWTAlgorithmEdDSA, Use: "sig", Curve: "Ed25519",
+		X: base64.RawURLEncoding.EncodeToString(edKey.Public().(ed25519.PublicKey))}, jwks[0])
+	require.Equal(t, "RSA", jwks[1].KeyType)
+	require.Equal(t, "AQAB", jwks[1].E)
+
+	// A token signed with the public key as an HMAC secret is turned away.
+	publicDER, err := x509.MarshalPKIXPublicKey(rsaKey.Public())
+	require.NoError(t, err)
+	confused, err := NewJWTManager([]JWTKey{{ID: "rsa", Secret: publicDER}}, time.Minute, "issuer", "audience")
+	require.NoError(t, err)
+	token, err := confused.GeneratetToken(account)
+	require.NoError(t, err)
+	_, err = manager.Verify(token)
+	require.Error(t, err)
+
+	// Tokens of another issuer or audience are turned away.
+	for _, other := range [][2]string{{"other", "audience"}, {"issuer", "other"}} {
+		otherManager, err := NewJWTManager(keys, time.Minute, other[0], other[1])
+		require.NoError(t, err)
+		token, err := otherManager.GeneratetToken(account)
+		require.NoError(t, err)
+		_, err = manager.Verify(token)
+		require.Error(t, err)
+	}
+
+	rec := httptest.NewRecorder()
+	JWKSHandler(manager).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, jwksPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var document struct {
+		Keys []JWK `json:"keys"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &document))
+	require.Equal(t, jwks, document.Keys)
+}
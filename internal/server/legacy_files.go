@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/rs/zerolog/log"
+)
+
+// LegacyStoragePath is the folder the server kept the file content in before
+// the blob store, relative to its working directory. The content of a file
+// was at <folder>/<filepath>/<filename>.
+const LegacyStoragePath = "fs"
+
+const legacyBatchSize = 100
+
+// LegacyBackfillReport lists what BackfillLegacyFiles did.
+type LegacyBackfillReport struct {
+	Migrated int
+	// Missing are the legacy files whose content is not in the legacy folder,
+	// they stay unreadable.
+	Missing []db.File
+}
+
+// BackfillLegacyFiles moves the content of the files uploaded before the blob
+// store into it. The files rows from then are ready but have no blob, which
+// leaves them unreadable until their content is hashed and attached. The
+// legacy content is only read, it can be removed once the report is checked.
+// It is meant to run once, before the server takes uploads again.
+func BackfillLegacyFiles(ctx context.Context, cfg *Config, store db.Store, legacyDir string) (LegacyBackfillReport, error) {
+	fileContentSaver, err := NewFileContentSaver(cfg, store)
+	if err != nil {
+		return LegacyBackfillReport{}, err
+	}
+
+	var keyWrapper *KeyWrapper
+	if cfg.EncryptionKey != "" {
+		keyWrapper, err = NewKeyWrapper(cfg.EncryptionKey)
+		if err != nil {
+			return LegacyBackfillReport{}, err
+		}
+	}
+
+	fileServer := NewFileServer(store, fileContentSaver, NewQuota(cfg), cfg.Compression, keyWrapper)
+	return fileServer.BackfillLegacyFiles(ctx, legacyDir)
+}
+
+func (s *FileServer) BackfillLegacyFiles(ctx context.Context, legacyDir string) (LegacyBackfillReport, error) {
+	var report LegacyBackfillReport
+	after := int64(0)
+
+	for {
+		files, err := s.fileStore.ListLegacyFiles(ctx, db.ListLegacyFilesParams{
+			ID:    after,
+			Limit: legacyBatchSize,
+		})
+		if err != nil {
+			return report, err
+		}
+
+		for _, file := range files {
+			after = file.ID
+
+			migrated, err := s.backfillLegacyFile(ctx, legacyDir, file)
+			if err != nil {
+				return report, err
+			}
+			if migrated {
+				report.Migrated++
+			} else {
+				report.Missing = append(report.Missing, file)
+			}
+		}
+
+		if len(files) < legacyBatchSize {
+			return report, nil
+		}
+	}
+}
+
+// backfillLegacyFile attaches the legacy content of the file as its blob. It
+// reports false when there is no content to attach.
+func (s *FileServer) backfillLegacyFile(ctx context.Context, legacyDir string, file db.File) (bool, error) {
+	path, err := legacyContentPath(legacyDir, file)
+	if err != nil {
+		log.Warn().Err(err).Msgf("backfill: skipped file %d", file.ID)
+		return false, nil
+	}
+
+	legacy, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Warn().Msgf("backfill: content of file '/%s/%s' of account %d is missing", file.Filepath, file.Filename, file.AccountID)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer legacy.Close()
+
+	buf := make([]byte, chunkSize)
+	content, err := receiveFileContent(ctx, func() ([]byte, error) {
+		n, err := legacy.Read(buf)
+		if n > 0 {
+			return buf[:n], nil
+		}
+		return nil, err
+	}, -1)
+	if err != nil {
+		return false, err
+	}
+	defer content.Close()
+
+	// The content was stored before there were quotas, it is taken as it is.
+	err = s.attachContent(ctx, Quota{}, file, content, nil)
+	if err != nil {
+		return false, err
+	}
+
+	log.Info().Msgf("backfill: file '/%s/%s' of account %d moved to blob '%s'", file.Filepath, file.Filename, file.AccountID, content.hash)
+	return true, nil
+}
+
+// legacyContentPath returns where the legacy server wrote the content of the
+// file. The paths were not checked back then, one leaving the folder is
+// refused.
+func legacyContentPath(legacyDir string, file db.File) (string, error) {
+	path := filepath.Join(legacyDir, file.Filepath, file.Filename)
+
+	rel, err := filepath.Rel(legacyDir, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path '/%s/%s' is outside of the legacy folder", file.Filepath, file.Filename)
+	}
+
+	return path, nil
+}
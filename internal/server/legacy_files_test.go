@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mockdb "github.com/Jay-T/go-devops-advanced-diploma/db/mock"
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfillLegacyFiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	saver := NewDiskFileContentSaver(t.TempDir())
+	server := NewFileServer(store, saver, Quota{Bytes: 1}, CodecIdentity, nil)
+
+	// The legacy server wrote the content to <folder>/<filepath>/<filename>.
+	legacyDir := t.TempDir()
+	content, hash := randomContent(500)
+	require.NoError(t, os.MkdirAll(filepath.Join(legacyDir, "docs"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(legacyDir, "docs", "notes.txt"), content, 0o600))
+
+	present := db.File{ID: 1, AccountID: 7, Filepath: "/docs", Filename: "notes.txt", Ready: true}
+	missing := db.File{ID: 2, AccountID: 7, Filepath: "docs", Filename: "gone.txt", Ready: true}
+	outside := db.File{ID: 3, AccountID: 8, Filepath: "../..", Filename: "passwd", Ready: true}
+
+	store.EXPECT().
+		ListLegacyFiles(gomock.Any(), db.ListLegacyFilesParams{ID: 0, Limit: legacyBatchSize}).
+		Return([]db.File{present, missing, outside}, nil)
+	store.EXPECT().
+		AttachFileBlobTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.AttachFileBlobTxParams) (db.Blob, error) {
+			require.Equal(t, present.ID, arg.FileID)
+			require.Equal(t, hash, arg.Hash)
+			require.Equal(t, int64(len(content)), arg.Size)
+			// The content predates the quotas.
+			require.Zero(t, arg.MaxBytes)
+
+			codec, wrappedKey, err := arg.NewBlob()
+			require.NoError(t, err)
+			blob := db.Blob{Hash: arg.Hash, Size: arg.Size, Codec: codec, WrappedKey: wrappedKey, Refcount: 1}
+			return blob, arg.AfterCreate(nil, blob)
+		})
+
+	report, err := server.BackfillLegacyFiles(context.Background(), legacyDir)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Migrated)
+	require.Equal(t, []db.File{missing, outside}, report.Missing)
+
+	requireContent(t, saver, hash, content)
+	// The legacy content is left for the operator to remove.
+	_, err = os.Stat(filepath.Join(legacyDir, "docs", "notes.txt"))
+	require.NoError(t, err)
+}
+
+func TestBackfillLegacyFilesPages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := NewFileServer(store, NewDiskFileContentSaver(t.TempDir()), Quota{}, CodecIdentity, nil)
+
+	page := make([]db.File, legacyBatchSize)
+	for i := range page {
+		page[i] = db.File{ID: int64(i + 1), Filepath: "docs", Filename: "missing", Ready: true, BlobHash: sql.NullString{}}
+	}
+	gomock.InOrder(
+		store.EXPECT().ListLegacyFiles(gomock.Any(), db.ListLegacyFilesParams{ID: 0, Limit: legacyBatchSize}).Return(page, nil),
+		store.EXPECT().ListLegacyFiles(gomock.Any(), db.ListLegacyFilesParams{ID: legacyBatchSize, Limit: legacyBatchSize}).Return(nil, nil),
+	)
+
+	report, err := server.BackfillLegacyFiles(context.Background(), t.TempDir())
+	require.NoError(t, err)
+	require.Len(t, report.Missing, legacyBatchSize)
+}
@@ -0,0 +1,320 @@
+package server
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	loginScopeUsername  = "username"
+	loginScopeIP        = "ip"
+	loginScopeShareLink = "share_link"
+	// loginFailureWindow is the quiet period after which the failures of a
+	// key are forgotten, it also caps the lockout.
+	loginFailureWindow = 24 * time.Hour
+)
+
+// LoginLimits are the thresholds of the protection against password
+// guessing. The failures are counted per username, or per share link for the
+// share link passwords, and per peer address.
+type LoginLimits struct {
+	// MaxFailures and MaxIPFailures are the failures of a username or a share
+	// link and of a peer address before the lockout, zero disables the limit.
+	MaxFailures   int64
+	MaxIPFailures int64
+	// Backoff is the wait after the first failure, doubled with every
+	// further one up to Lockout.
+	Backoff time.Duration
+	// Lockout is the wait after the last allowed failure, doubled with every
+	// further one up to loginFailureWindow.
+	Lockout time.Duration
+}
+
+func NewLoginLimits(cfg *Config) LoginLimits {
+	return LoginLimits{
+		MaxFailures:   cfg.LoginMaxFailures,
+		MaxIPFailures: cfg.LoginMaxIPFailures,
+		Backoff:       cfg.LoginBackoff,
+		Lockout:       cfg.LoginLockout,
+	}
+}
+
+// doubled returns base doubled times times, at most limit.
+func doubled(base time.Duration, times int64, limit time.Duration) time.Duration {
+	for ; times > 0 && base < limit; times-- {
+		base *= 2
+	}
+	if base > limit {
+		return limit
+	}
+	return base
+}
+
+// delay returns how long a key is locked after its failures-th failure.
+func (l LoginLimits) delay(failures int64, maxFailures int64) time.Duration {
+	if failures < maxFailures {
+		return doubled(l.Backoff, failures-1, l.Lockout)
+	}
+	return doubled(l.Lockout, failures-maxFailures, loginFailureWindow)
+}
+
+// LoginLimiter keeps the failed logins in the database, so the lockouts hold
+// across restarts and server instances.
+type LoginLimiter struct {
+	store  db.Store
+	limits LoginLimits
+}
+
+func NewLoginLimiter(store db.Store, limits LoginLimits) *LoginLimiter {
+	return &LoginLimiter{store: store, limits: limits}
+}
+
+// peerIP returns the address of the caller without the port, empty when it
+// is unknown.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+type loginKey struct {
+	scope       string
+	key         string
+	maxFailures int64
+}
+
+// keys returns the keys the login is limited by, the key of the scope is the
+// username or the share link the password is for.
+func (l *LoginLimiter) keys(scope string, key string, ip string) []loginKey {
+	var keys []loginKey
+	if l.limits.MaxFailures > 0 {
+		keys = append(keys, loginKey{scope, key, l.limits.MaxFailures})
+	}
+	if l.limits.MaxIPFailures > 0 && ip != "" {
+		keys = append(keys, loginKey{loginScopeIP, ip, l.limits.MaxIPFailures})
+	}
+	return keys
+}
+
+// LoginAttempt is a login counted as failed before the password is checked,
+// so parallel attempts cannot all slip under the limits. It ends with
+// Succeeded or Failed, Release takes it back otherwise.
+type LoginAttempt struct {
+	limiter  *LoginLimiter
+	keys     []loginKey
+	reserved db.ReserveLoginAttemptTxResult
+	done     bool
+}
+
+// Reserve counts a login of the username from the peer address. It returns
+// a ResourceExhausted error with the retry delay while either is locked out.
+func (l *LoginLimiter) Reserve(ctx context.Context, username string, ip string, now time.Time) (*LoginAttempt, error) {
+	attempt, lockedFor, err := l.reserve(ctx, loginScopeUsername, username, ip, now)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot check failed logins: %v", err))
+	}
+	if lockedFor > 0 {
+		return nil, logError(loginLockedError(lockedFor))
+	}
+
+	return attempt, nil
+}
+
+// ReserveShareLink counts a password of the share link from the peer address.
+// It returns how long the password cannot be tried instead while either is
+// locked out.
+func (l *LoginLimiter) ReserveShareLink(ctx context.Context, linkID int64, ip string, now time.Time) (*LoginAttempt, time.Duration, error) {
+	return l.reserve(ctx, loginScopeShareLink, shareLinkKey(linkID), ip, now)
+}
+
+// CheckShareLink returns how long the password of the share link cannot be
+// tried from the peer address, zero when it can.
+func (l *LoginLimiter) CheckShareLink(ctx context.Context, linkID int64, ip string, now time.Time) (time.Duration, error) {
+	return l.lockedFor(ctx, loginScopeShareLink, shareLinkKey(linkID), ip, now)
+}
+
+func shareLinkKey(linkID int64) string {
+	return strconv.FormatInt(linkID, 10)
+}
+
+func (l *LoginLimiter) reserve(ctx context.Context, scope string, key string, ip string, now time.Time) (*LoginAttempt, time.Duration, error) {
+	attempt := &LoginAttempt{limiter: l, keys: l.keys(scope, key, ip)}
+	if len(attempt.keys) == 0 {
+		return attempt, 0, nil
+	}
+
+	arg := db.ReserveLoginAttemptTxParams{
+		Now:         now,
+		WindowStart: now.Add(-loginFailureWindow),
+		LockedUntil: func(i int, failures int32) time.Time {
+			return now.Add(l.limits.delay(int64(failures), attempt.keys[i].maxFailures))
+		},
+	}
+	for _, key := range attempt.keys {
+		arg.Keys = append(arg.Keys, db.LoginKey{Scope: key.scope, Key: key.key})
+	}
+
+	reserved, err := l.store.ReserveLoginAttemptTx(ctx, arg)
+	if err == db.ErrLoginLocked {
+		return nil, reserved.LockedUntil.Sub(now), nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	attempt.reserved = reserved
+	return attempt, 0, nil
+}
+
+// lockedFor returns how long the key of the scope or the peer address is
+// still locked out.
+func (l *LoginLimiter) lockedFor(ctx context.Context, scope string, key string, ip string, now time.Time) (time.Duration, error) {
+	keys := l.keys(scope, key, ip)
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	rows, err := l.store.ListLoginFailures(ctx, db.ListLoginFailuresParams{
+		Scope: scope,
+		Key:   key,
+		Ip:    ip,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var lockedUntil time.Time
+	for _, row := range rows {
+		for _, key := range keys {
+			if row.Scope == key.scope && row.LockedUntil.After(lockedUntil) {
+				lockedUntil = row.LockedUntil
+			}
+		}
+	}
+	if !lockedUntil.After(now) {
+		return 0, nil
+	}
+
+	return lockedUntil.Sub(now), nil
+}
+
+func loginLockedError(retryDelay time.Duration) error {
+	retryDelay = (retryDelay + time.Second - 1).Truncate(time.Second)
+
+	st := status.Newf(codes.ResourceExhausted, "too many failed logins, retry in %s", retryDelay)
+	detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryDelay)})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// Failed keeps the attempt counted.
+func (a *LoginAttempt) Failed() {
+	if a.done {
+		return
+	}
+	a.done = true
+
+	for i, row := range a.reserved.Reserved {
+		if int64(row.Failures) >= a.keys[i].maxFailures {
+			log.Warn().Msgf("Logins of %s '%s' locked out after %d failures", row.Scope, row.Key, row.Failures)
+		}
+	}
+}
+
+// Succeeded forgets the failures of the username or the share link. Those of
+// the peer address are kept, a login to an own account does not clear them,
+// only the attempt is taken back.
+func (a *LoginAttempt) Succeeded(ctx context.Context) {
+	if a.done {
+		return
+	}
+	a.done = true
+
+	for i, key := range a.keys {
+		if key.scope == loginScopeIP {
+			a.undo(ctx, i)
+			continue
+		}
+
+		err := a.limiter.store.DeleteLoginFailures(ctx, db.DeleteLoginFailuresParams{
+			Scope: key.scope,
+			Key:   key.key,
+		})
+		if err != nil {
+			log.Error().Err(err).Msg("cannot reset failed logins")
+		}
+	}
+}
+
+// Release takes back the attempt unless it succeeded or failed, like when
+// the password is right but the login goes on with the second factor.
+func (a *LoginAttempt) Release(ctx context.Context) {
+	if a.done {
+		return
+	}
+	a.done = true
+
+	for i := range a.keys {
+		a.undo(ctx, i)
+	}
+}
+
+// undo takes the attempt back from the i-th key. Its lock is only restored
+// when no later failure has changed it.
+func (a *LoginAttempt) undo(ctx context.Context, i int) {
+	key := a.keys[i]
+	err := a.limiter.store.UndoLoginFailure(ctx, db.UndoLoginFailureParams{
+		ReservedUntil: a.reserved.Reserved[i].LockedUntil,
+		LockedUntil:   a.reserved.Previous[i].LockedUntil,
+		Scope:         key.scope,
+		Key:           key.key,
+	})
+	if err != nil {
+		log.Error().Err(err).Msgf("cannot take back login attempt of %s '%s'", key.scope, key.key)
+	}
+}
+
+// RunCleanup deletes the failures past loginFailureWindow every interval
+// until ctx is done.
+func (l *LoginLimiter) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			deleted, err := l.store.DeleteStaleLoginFailures(ctx, db.DeleteStaleLoginFailuresParams{
+				WindowStart: now.Add(-loginFailureWindow),
+				Now:         now,
+			})
+			if err != nil {
+				log.Error().Err(err).Msg("cannot delete stale login failures")
+			}
+			if deleted > 0 {
+				log.Info().Msgf("Deleted %d stale login failures", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"testing"
+	"time"
+
+	mockdb "github.com/Jay-T/go-devops-advanced-diploma/db/mock"
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoginLimitsDelay(t *testing.T) {
+	limits := LoginLimits{MaxFailures: 4, Backoff: time.Second, Lockout: 15 * time.Minute}
+
+	testCases := []struct {
+		failures int64
+		delay    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 15 * time.Minute},
+		{5, 30 * time.Minute},
+		{100, loginFailureWindow},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.delay, limits.delay(tc.failures, limits.MaxFailures), "failures %d", tc.failures)
+	}
+
+	// The backoff never outgrows the lockout, even far below the limit.
+	require.Equal(t, limits.Lockout, limits.delay(30, 50))
+}
+
+func TestLoginLockout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	limits := LoginLimits{MaxFailures: 2, MaxIPFailures: 10, Backoff: time.Second, Lockout: time.Minute}
+	server := NewAuthServer(
+		store,
+		newTestJWTManager(t, time.Minute),
+		NewAccountStatusCache(store, time.Minute),
+		NewTokenRevocationList(store),
+		NewLoginLimiter(store, limits),
+		NewTwoFactor(store, nil, "test"),
+		PasswordPolicy{},
+		nil,
+		time.Hour,
+	)
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 40000},
+	})
+	hash, err := util.HashPassword("secret")
+	require.NoError(t, err)
+	account := db.Account{ID: 1, Username: "user", Passhash: hash}
+
+	var lockedUntil time.Time
+	store.EXPECT().
+		ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.ReserveLoginAttemptTxParams) (db.ReserveLoginAttemptTxResult, error) {
+			require.Equal(t, []db.LoginKey{{Scope: loginScopeUsername, Key: "user"}, {Scope: loginScopeIP, Key: "192.0.2.1"}}, arg.Keys)
+			result := reservedLogin(arg, 2, 3)
+			lockedUntil = result.Reserved[0].LockedUntil
+			return result, nil
+		})
+	store.EXPECT().GetAccount(gomock.Any(), "user").Return(account, nil)
+
+	// The attempt is counted before the password is checked and stays so.
+	_, err = server.Login(ctx, &pb.LoginRequest{Login: "user", Password: "wrong"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	// The second failure of the username hits the limit.
+	require.WithinDuration(t, time.Now().Add(limits.Lockout), lockedUntil, 5*time.Second)
+
+	// Locked out, the password is not even checked.
+	store.EXPECT().
+		ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+		Return(db.ReserveLoginAttemptTxResult{LockedUntil: lockedUntil}, db.ErrLoginLocked)
+
+	_, err = server.Login(ctx, &pb.LoginRequest{Login: "user", Password: "secret"})
+	st := status.Convert(err)
+	require.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Len(t, st.Details(), 1)
+	retry, ok := st.Details()[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	require.InDelta(t, limits.Lockout.Seconds(), retry.GetRetryDelay().AsDuration().Seconds(), 5)
+
+	// Once the lock is over a good password clears the failures of the
+	// username and takes the attempt back from the peer address.
+	var reserved db.ReserveLoginAttemptTxResult
+	store.EXPECT().
+		ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.ReserveLoginAttemptTxParams) (db.ReserveLoginAttemptTxResult, error) {
+			reserved = reservedLogin(arg, 1, 4)
+			return reserved, nil
+		})
+	store.EXPECT().GetAccount(gomock.Any(), "user").Return(account, nil)
+	store.EXPECT().GetAccountTotp(gomock.Any(), account.ID).Return(db.AccountTotp{}, sql.ErrNoRows)
+	store.EXPECT().
+		DeleteLoginFailures(gomock.Any(), db.DeleteLoginFailuresParams{Scope: loginScopeUsername, Key: "user"}).
+		Return(nil)
+	store.EXPECT().
+		UndoLoginFailure(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.UndoLoginFailureParams) error {
+			require.Equal(t, db.UndoLoginFailureParams{
+				ReservedUntil: reserved.Reserved[1].LockedUntil,
+				LockedUntil:   reserved.Previous[1].LockedUntil,
+				Scope:         loginScopeIP,
+				Key:           "192.0.2.1",
+			}, arg)
+			return nil
+		})
+	store.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(db.RefreshToken{}, nil)
+
+	res, err := server.Login(ctx, &pb.LoginRequest{Login: "user", Password: "secret"})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.GetToken())
+}
+
+// reservedLogin returns the rows of a reserved login attempt, the i-th key
+// reaching failures[i] failures.
+func reservedLogin(arg db.ReserveLoginAttemptTxParams, failures ...int32) db.ReserveLoginAttemptTxResult {
+	var result db.ReserveLoginAttemptTxResult
+	for i, key := range arg.Keys {
+		result.Previous = append(result.Previous, db.LoginFailure{Scope: key.Scope, Key: key.Key, Failures: failures[i] - 1, LockedUntil: arg.Now})
+		result.Reserved = append(result.Reserved, db.LoginFailure{Scope: key.Scope, Key: key.Key, Failures: failures[i], LockedUntil: arg.LockedUntil(i, failures[i])})
+	}
+	return result
+}
@@ -0,0 +1,256 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxMetadataEntries  = 32
+	maxMetadataKeyLen   = 128
+	maxMetadataValueLen = 1024
+)
+
+// Keys of the metadata the server records on every upload.
+const (
+	metadataContentType   = "content_type"
+	metadataSize          = "size"
+	metadataUserAgent     = "user_agent"
+	metadataClientAddress = "client_address"
+)
+
+// validateMetadataKey rejects keys which would not survive a round trip
+// through clients and logs.
+func validateMetadataKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("key is empty")
+	}
+	if len(key) > maxMetadataKeyLen {
+		return fmt.Errorf("key is longer than %d bytes", maxMetadataKeyLen)
+	}
+	if !utf8.ValidString(key) {
+		return fmt.Errorf("key %q is not valid UTF-8", key)
+	}
+	if strings.IndexFunc(key, unicode.IsControl) >= 0 {
+		return fmt.Errorf("key %q contains a control character", key)
+	}
+	return nil
+}
+
+// validateUserMetadata checks the user entries of a request.
+func validateUserMetadata(entries map[string]string) error {
+	if len(entries) > maxMetadataEntries {
+		return logError(status.Errorf(codes.InvalidArgument, "invalid metadata: at most %d entries allowed", maxMetadataEntries))
+	}
+
+	for key, value := range entries {
+		err := validateMetadataKey(key)
+		if err != nil {
+			return logError(status.Errorf(codes.InvalidArgument, "invalid metadata: %v", err))
+		}
+		if len(value) > maxMetadataValueLen {
+			return logError(status.Errorf(codes.InvalidArgument, "invalid metadata: value of %q is longer than %d bytes", key, maxMetadataValueLen))
+		}
+		if !utf8.ValidString(value) {
+			return logError(status.Errorf(codes.InvalidArgument, "invalid metadata: value of %q is not valid UTF-8", key))
+		}
+	}
+
+	return nil
+}
+
+// detectContentType sniffs the MIME type of the content. Content the sniffer
+// does not recognize gets the type of the file extension, if there is one.
+func detectContentType(filename string, head []byte) string {
+	contentType := http.DetectContentType(head)
+	if contentType != "application/octet-stream" {
+		return contentType
+	}
+
+	if byExtension := mime.TypeByExtension(strings.ToLower(filepath.Ext(filename))); byExtension != "" {
+		return byExtension
+	}
+
+	return contentType
+}
+
+// uploadMetadata returns the entries the server records for uploaded content.
+func uploadMetadata(ctx context.Context, filename string, head []byte, size int64) map[string]string {
+	entries := map[string]string{
+		metadataContentType: detectContentType(filename, head),
+		metadataSize:        strconv.FormatInt(size, 10),
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if userAgent := strings.Join(md.Get("user-agent"), " "); userAgent != "" {
+			entries[metadataUserAgent] = truncateString(userAgent, maxMetadataValueLen)
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		entries[metadataClientAddress] = p.Addr.String()
+	}
+
+	return entries
+}
+
+func truncateString(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+
+	s = s[:maxLen]
+	// Do not leave half of a rune at the end.
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// metadataToProto splits the rows of a file into the server recorded
+// fields and the user entries.
+func metadataToProto(rows []db.FilesMetadatum) *pb.FileMetadata {
+	res := &pb.FileMetadata{}
+
+	for _, row := range rows {
+		if !row.System {
+			if res.User == nil {
+				res.User = make(map[string]string)
+			}
+			res.User[row.Key] = row.Value
+			continue
+		}
+
+		switch row.Key {
+		case metadataContentType:
+			res.ContentType = row.Value
+		case metadataSize:
+			res.Size, _ = strconv.ParseInt(row.Value, 10, 64)
+		case metadataUserAgent:
+			res.UserAgent = row.Value
+		case metadataClientAddress:
+			res.ClientAddress = row.Value
+		}
+	}
+
+	return res
+}
+
+// getFileMetadata returns the metadata of the file in the proto form.
+func (s *FileServer) getFileMetadata(ctx context.Context, fileID int64) (*pb.FileMetadata, error) {
+	rows, err := s.fileStore.ListFileMetadata(ctx, fileID)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot get file metadata: %v", err))
+	}
+
+	return metadataToProto(rows), nil
+}
+
+// blobHead returns the beginning of the plain blob content for type sniffing.
+func (s *FileServer) blobHead(ctx context.Context, blob db.Blob) ([]byte, error) {
+	content, err := s.openBlobContent(ctx, blob, false, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	buffer := make([]byte, sniffLen)
+	n, err := io.ReadFull(content, buffer)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+
+	return buffer[:n], nil
+}
+
+func (s *FileServer) SetFileMetadata(ctx context.Context, in *pb.SetFileMetadataRequest) (*pb.SetFileMetadataResponse, error) {
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Msgf("receive an SetFileMetadata request from user %s", caller.Username)
+
+	err = validateUserMetadata(in.GetMetadata())
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := s.updateFileMetadata(ctx, caller.AccountID, in.GetKey(), db.UpdateFileMetadataTxParams{
+		Set: in.GetMetadata(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SetFileMetadataResponse{Info: info}, nil
+}
+
+func (s *FileServer) DeleteFileMetadata(ctx context.Context, in *pb.DeleteFileMetadataRequest) (*pb.DeleteFileMetadataResponse, error) {
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Msgf("receive an DeleteFileMetadata request from user %s", caller.Username)
+
+	for _, key := range in.GetKeys() {
+		err = validateMetadataKey(key)
+		if err != nil {
+			return nil, logError(status.Errorf(codes.InvalidArgument, "invalid metadata: %v", err))
+		}
+	}
+
+	info, err := s.updateFileMetadata(ctx, caller.AccountID, in.GetKey(), db.UpdateFileMetadataTxParams{
+		Remove: in.GetKeys(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DeleteFileMetadataResponse{Info: info}, nil
+}
+
+// updateFileMetadata edits the user metadata of the file with the given key
+// and returns the file info with the resulting metadata.
+func (s *FileServer) updateFileMetadata(ctx context.Context, accountID int64, key *pb.FileInfo, arg db.UpdateFileMetadataTxParams) (*pb.FileInfo, error) {
+	file, err := s.getReadyFile(ctx, accountID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	arg.FileID = file.ID
+	arg.MaxEntries = maxMetadataEntries
+
+	rows, err := s.fileStore.UpdateFileMetadataTx(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, logError(status.Error(codes.NotFound, "cannot find file"))
+		}
+		if err == db.ErrMetadataLimit {
+			return nil, logError(status.Errorf(codes.ResourceExhausted, "metadata limit exceeded: %d entries allowed", maxMetadataEntries))
+		}
+		return nil, logError(status.Errorf(codes.Internal, "cannot update file metadata: %v", err))
+	}
+
+	info := fileToProto(file)
+	info.Metadata = metadataToProto(rows)
+	return info, nil
+}
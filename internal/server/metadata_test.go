@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestDetectContentType(t *testing.T) {
+	testCases := []struct {
+		filename string
+		head     []byte
+		expected string
+	}{
+		{"image.png", []byte("\x89PNG\x0D\x0A\x1A\x0A rest"), "image/png"},
+		{"notes", []byte("plain text"), "text/plain; charset=utf-8"},
+		{"page.txt", []byte("<html><body>"), "text/html; charset=utf-8"},
+		{"data.json", []byte{0x00, 0x01, 0x02}, "application/json"},
+		{"blob.unknown-ext", []byte{0x00, 0x01, 0x02}, "application/octet-stream"},
+		{"empty", []byte{}, "text/plain; charset=utf-8"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.filename, func(t *testing.T) {
+			require.Equal(t, tc.expected, detectContentType(tc.filename, tc.head))
+		})
+	}
+}
+
+func TestValidateUserMetadata(t *testing.T) {
+	require.NoError(t, validateUserMetadata(nil))
+	require.NoError(t, validateUserMetadata(map[string]string{"author": "me", "tag": ""}))
+
+	tooMany := make(map[string]string)
+	for i := 0; i <= maxMetadataEntries; i++ {
+		tooMany[strings.Repeat("k", i+1)] = "v"
+	}
+
+	for _, entries := range []map[string]string{
+		{"": "value"},
+		{strings.Repeat("k", maxMetadataKeyLen+1): "value"},
+		{"bad\nkey": "value"},
+		{"bad\xffkey": "value"},
+		{"key": strings.Repeat("v", maxMetadataValueLen+1)},
+		{"key": "bad\xffvalue"},
+		tooMany,
+	} {
+		err := validateUserMetadata(entries)
+		require.Error(t, err)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
+
+func TestUploadMetadata(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("user-agent", "keeper/1.0 grpc-go/1.50"))
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}})
+
+	entries := uploadMetadata(ctx, "notes.txt", []byte("hello"), 5)
+	require.Equal(t, map[string]string{
+		metadataContentType:   "text/plain; charset=utf-8",
+		metadataSize:          "5",
+		metadataUserAgent:     "keeper/1.0 grpc-go/1.50",
+		metadataClientAddress: "10.0.0.1:5000",
+	}, entries)
+
+	entries = uploadMetadata(context.Background(), "notes.txt", nil, 0)
+	require.NotContains(t, entries, metadataUserAgent)
+	require.NotContains(t, entries, metadataClientAddress)
+}
+
+func TestMetadataToProto(t *testing.T) {
+	res := metadataToProto([]db.FilesMetadatum{
+		{Key: metadataContentType, Value: "image/png", System: true},
+		{Key: metadataSize, Value: "42", System: true},
+		{Key: metadataUserAgent, Value: "keeper/1.0", System: true},
+		// A user entry may share its key with a recorded one.
+		{Key: metadataSize, Value: "large"},
+		{Key: "author", Value: "me"},
+	})
+
+	require.Equal(t, "image/png", res.GetContentType())
+	require.Equal(t, int64(42), res.GetSize())
+	require.Equal(t, "keeper/1.0", res.GetUserAgent())
+	require.Empty(t, res.GetClientAddress())
+	require.Equal(t, map[string]string{metadataSize: "large", "author": "me"}, res.GetUser())
+
+	require.Nil(t, metadataToProto(nil).GetUser())
+}
+
+func TestReceiveFileContentSniff(t *testing.T) {
+	chunks := [][]byte{[]byte(strings.Repeat("a", 300)), []byte(strings.Repeat("b", 300))}
+
+	content, err := receiveFileContent(context.Background(), func() ([]byte, error) {
+		if len(chunks) == 0 {
+			return nil, io.EOF
+		}
+		chunk := chunks[0]
+		chunks = chunks[1:]
+		return chunk, nil
+	}, -1)
+	require.NoError(t, err)
+	defer content.Close()
+
+	require.Equal(t, strings.Repeat("a", 300)+strings.Repeat("b", sniffLen-300), string(content.head()))
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxPasswordBytes is the most bcrypt hashes, longer passwords are refused by
+// the hashing.
+const maxPasswordBytes = 72
+
+// commonPasswordList holds the most used passwords of the public leaks, one
+// per line in lower case.
+//
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+var commonPasswords = func() map[string]bool {
+	passwords := make(map[string]bool)
+	for _, password := range strings.Split(commonPasswordList, "\n") {
+		if password != "" {
+			passwords[password] = true
+		}
+	}
+	return passwords
+}()
+
+// PasswordPolicy are the rules a new password has to follow. The passwords
+// of the existing accounts are not checked.
+type PasswordPolicy struct {
+	// MinLength is the least number of characters, zero disables the check.
+	MinLength int64
+	// MinClasses is the least number of the character classes used: lower
+	// case and upper case letters, digits and the rest.
+	MinClasses int64
+}
+
+func NewPasswordPolicy(cfg *Config) PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:  cfg.PasswordMinLength,
+		MinClasses: cfg.PasswordMinClasses,
+	}
+}
+
+func passwordClasses(password string) int64 {
+	var lower, upper, digit, other int64
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+	return lower + upper + digit + other
+}
+
+// Validate returns the reason the password of the user is refused, nil for a
+// good one.
+func (p PasswordPolicy) Validate(username string, password string) error {
+	if password == "" {
+		return fmt.Errorf("password is empty")
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("password is longer than %d bytes", maxPasswordBytes)
+	}
+	if int64(utf8.RuneCountInString(password)) < p.MinLength {
+		return fmt.Errorf("password is shorter than %d characters", p.MinLength)
+	}
+	if passwordClasses(password) < p.MinClasses {
+		return fmt.Errorf("password has to mix at least %d of lower case, upper case, digits and symbols", p.MinClasses)
+	}
+
+	lower := strings.ToLower(password)
+	if commonPasswords[lower] {
+		return fmt.Errorf("password is too common")
+	}
+	if username != "" && strings.Contains(lower, strings.ToLower(username)) {
+		return fmt.Errorf("password contains the username")
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 10, MinClasses: 3}
+
+	testCases := []struct {
+		name     string
+		password string
+		ok       bool
+	}{
+		{"empty", "", false},
+		{"short", "Ab1!", false},
+		{"two classes", "abcdefghij1", false},
+		{"three classes", "abcdefghiJ1", true},
+		{"unicode letters", "пароль-Длинный", true},
+		{"common", "Password123", false},
+		{"common any case", "PASSWORD123", false},
+		{"contains username", "xAlice-2024x", false},
+		{"too long for bcrypt", strings.Repeat("aB3", 25), false},
+	}
+
+	for _, tc := range testCases {
+		err := policy.Validate("alice", tc.password)
+		if tc.ok {
+			require.NoError(t, err, tc.name)
+		} else {
+			require.Error(t, err, tc.name)
+		}
+	}
+
+	// The zero policy still refuses an empty and a common password.
+	require.Error(t, PasswordPolicy{}.Validate("alice", ""))
+	require.Error(t, PasswordPolicy{}.Validate("alice", "qwerty"))
+	require.NoError(t, PasswordPolicy{}.Validate("alice", "x"))
+}
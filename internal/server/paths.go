@@ -0,0 +1,94 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxNameLength = 255
+	maxPathLength = 4096
+)
+
+// cleanDirPath returns the canonical form of a virtual directory path: names
+// separated by single slashes, without leading or trailing slashes, and the
+// empty string for the root. Paths are virtual and never reach the storage,
+// still "..", control characters and backslashes are rejected rather than
+// resolved, so a path means the same thing to every client.
+func cleanDirPath(p string) (string, error) {
+	var names []string
+	for _, name := range strings.Split(p, "/") {
+		if name == "" || name == "." {
+			continue
+		}
+
+		err := validateName(name)
+		if err != nil {
+			return "", fmt.Errorf("invalid path %q: %w", p, err)
+		}
+		names = append(names, name)
+	}
+
+	clean := strings.Join(names, "/")
+	if len(clean) > maxPathLength {
+		return "", fmt.Errorf("path is longer than %d bytes", maxPathLength)
+	}
+
+	return clean, nil
+}
+
+// validateName checks a single file or directory name.
+func validateName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("name is empty")
+	case name == "." || name == "..":
+		return fmt.Errorf("name %q is reserved", name)
+	case len(name) > maxNameLength:
+		return fmt.Errorf("name is longer than %d bytes", maxNameLength)
+	case !utf8.ValidString(name):
+		return fmt.Errorf("name is not valid UTF-8")
+	case strings.ContainsAny(name, "/\\"):
+		return fmt.Errorf("name %q contains a slash", name)
+	}
+
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return fmt.Errorf("name %q contains a control character", name)
+		}
+	}
+
+	return nil
+}
+
+// fileKey returns the canonical directory path and the name of the file the
+// info points to.
+func fileKey(info *pb.FileInfo) (string, string, error) {
+	dir, err := cleanDirPath(info.GetFilepath())
+	if err != nil {
+		return "", "", logError(status.Error(codes.InvalidArgument, err.Error()))
+	}
+
+	name := info.GetFilename()
+	err = validateName(name)
+	if err != nil {
+		return "", "", logError(status.Errorf(codes.InvalidArgument, "invalid file name: %v", err))
+	}
+
+	return dir, name, nil
+}
+
+// dirPath returns the canonical form of the directory path from a request.
+func dirPath(p string) (string, error) {
+	dir, err := cleanDirPath(p)
+	if err != nil {
+		return "", logError(status.Error(codes.InvalidArgument, err.Error()))
+	}
+
+	return dir, nil
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCleanDirPath(t *testing.T) {
+	valid := map[string]string{
+		"":                "",
+		"/":               "",
+		"docs":            "docs",
+		"/docs/":          "docs",
+		"docs//2023/./q1": "docs/2023/q1",
+		"./a/b":           "a/b",
+		"папка/файлы":     "папка/файлы",
+	}
+	for p, expected := range valid {
+		clean, err := cleanDirPath(p)
+		require.NoError(t, err, p)
+		require.Equal(t, expected, clean, p)
+	}
+
+	invalid := []string{
+		"..",
+		"../etc",
+		"docs/../../etc",
+		"docs/..",
+		`docs\..\etc`,
+		"docs/\x00",
+		"docs/\n",
+		"docs/" + strings.Repeat("a", maxNameLength+1),
+		strings.Repeat("a/", maxPathLength),
+		"docs/\xff",
+	}
+	for _, p := range invalid {
+		_, err := cleanDirPath(p)
+		require.Error(t, err, p)
+	}
+}
+
+func TestFileKey(t *testing.T) {
+	dir, name, err := fileKey(&pb.FileInfo{Filepath: "/docs/2023/", Filename: "report.txt"})
+	require.NoError(t, err)
+	require.Equal(t, "docs/2023", dir)
+	require.Equal(t, "report.txt", name)
+
+	for _, info := range []*pb.FileInfo{
+		{Filepath: "docs", Filename: ""},
+		{Filepath: "docs", Filename: ".."},
+		{Filepath: "docs", Filename: "a/b"},
+		{Filepath: "../docs", Filename: "a"},
+	} {
+		_, _, err := fileKey(info)
+		require.Equal(t, codes.InvalidArgument, status.Code(err), info.String())
+	}
+}
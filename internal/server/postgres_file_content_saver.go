@@ -0,0 +1,195 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+)
+
+// pgChunkSize is the amount of content kept in a single blob_chunks row.
+const pgChunkSize = 256 << 10
+
+// PostgresFileContentSaver keeps file content in the blob_chunks table, so a
+// small deployment needs nothing but the database and has a single backup.
+type PostgresFileContentSaver struct {
+	q db.Querier
+}
+
+func NewPostgresFileContentSaver(q db.Querier) FileContentSaver {
+	return &PostgresFileContentSaver{q: q}
+}
+
+// WithTx returns the saver writing through the transaction q, so the content
+// is committed or rolled back together with the files row.
+func (s *PostgresFileContentSaver) WithTx(q db.Querier) FileContentSaver {
+	return &PostgresFileContentSaver{q: q}
+}
+
+// Save splits the content into rows of pgChunkSize. The first row is written
+// even for empty content, it marks the blob as present.
+func (s *PostgresFileContentSaver) Save(ctx context.Context, hash string, content io.Reader, size int64) error {
+	if err := validateBlobHash(hash); err != nil {
+		return err
+	}
+
+	err := s.q.DeleteBlobChunks(ctx, hash)
+	if err != nil {
+		return fmt.Errorf("cannot remove old chunks: %w", err)
+	}
+
+	buffer := make([]byte, pgChunkSize)
+	written := int64(0)
+
+	for seq := int32(0); ; seq++ {
+		n, err := io.ReadFull(content, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return fmt.Errorf("cannot read content: %w", err)
+		}
+		if n == 0 && seq > 0 {
+			break
+		}
+
+		err = s.q.CreateBlobChunk(ctx, db.CreateBlobChunkParams{
+			Hash: hash,
+			Seq:  seq,
+			Data: buffer[:n],
+		})
+		if err != nil {
+			return fmt.Errorf("cannot write chunk: %w", err)
+		}
+
+		written += int64(n)
+		if n < pgChunkSize {
+			break
+		}
+	}
+
+	if written != size {
+		return fmt.Errorf("content size mismatch: %d != %d", written, size)
+	}
+
+	return nil
+}
+
+func (s *PostgresFileContentSaver) Open(ctx context.Context, hash string, offset int64) (io.ReadCloser, error) {
+	if err := validateBlobHash(hash); err != nil {
+		return nil, err
+	}
+
+	if offset < 0 {
+		return nil, fmt.Errorf("negative offset %d", offset)
+	}
+
+	// Reading starts at the chunk holding the offset.
+	r := &pgBlobReader{ctx: ctx, q: s.q, hash: hash, seq: int32(offset / pgChunkSize)}
+	skip := int(offset % pgChunkSize)
+
+	// Fetch the first chunk right away to report a missing blob on open.
+	err := r.next()
+	if errors.Is(err, io.EOF) && r.seq > 0 {
+		// The offset may point right past the last full chunk.
+		exists, existsErr := s.Exists(ctx, hash)
+		if existsErr != nil {
+			return nil, existsErr
+		}
+		if exists && skip == 0 {
+			return r, nil
+		}
+	}
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("cannot find blob '%s' at offset %d", hash, offset)
+		}
+		return nil, err
+	}
+
+	if skip > len(r.data) {
+		return nil, fmt.Errorf("offset %d is past the end of blob '%s'", offset, hash)
+	}
+	r.data = r.data[skip:]
+
+	return r, nil
+}
+
+func (s *PostgresFileContentSaver) Exists(ctx context.Context, hash string) (bool, error) {
+	if err := validateBlobHash(hash); err != nil {
+		return false, err
+	}
+
+	return s.q.BlobChunksExist(ctx, hash)
+}
+
+func (s *PostgresFileContentSaver) Delete(ctx context.Context, hash string) error {
+	if err := validateBlobHash(hash); err != nil {
+		return err
+	}
+
+	return s.q.DeleteBlobChunks(ctx, hash)
+}
+
+// Walk lists the blobs by their first chunk. Chunks carry no time, the content
+// is written in the transaction creating the blob row anyway.
+func (s *PostgresFileContentSaver) Walk(ctx context.Context, fn func(hash string, modTime time.Time) error) error {
+	hashes, err := s.q.ListBlobChunkHashes(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot list chunks: %w", err)
+	}
+
+	for _, hash := range hashes {
+		err = fn(hash, time.Time{})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pgBlobReader reads the blob one chunk at a time, never holding more than
+// a single chunk in memory.
+type pgBlobReader struct {
+	ctx  context.Context
+	q    db.Querier
+	hash string
+	seq  int32
+	data []byte
+}
+
+func (r *pgBlobReader) next() error {
+	data, err := r.q.GetBlobChunk(r.ctx, db.GetBlobChunkParams{
+		Hash: r.hash,
+		Seq:  r.seq,
+	})
+	if err == sql.ErrNoRows {
+		return io.EOF
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read chunk: %w", err)
+	}
+
+	r.seq++
+	r.data = data
+	return nil
+}
+
+func (r *pgBlobReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		err := r.next()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func (r *pgBlobReader) Close() error {
+	return nil
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RoleAdmin is the role of the users listed as admins in the config. The
+// listed names cannot be registered, an admin account is registered first and
+// added to the config after.
+const RoleAdmin = "admin"
+
+// Principal is the authenticated caller of a call. The AuthInterceptor puts
+// it in the context, the handlers never take the caller from the request
+// metadata the client controls.
+type Principal struct {
+	AccountID int64
+	Username  string
+	Roles     []string
+	// TokenID is the jti of the access token the call is authorized with,
+	// TokenExpiresAt the time the token stops working by itself. Both are
+	// empty for the calls authorized by a client certificate.
+	TokenID        string
+	TokenExpiresAt time.Time
+}
+
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// principalContextKey keys the Principal in the context of authorized
+// calls. The type is private, no other package can set it.
+type principalContextKey struct{}
+
+func contextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// principalFromContext returns the caller of an authorized call.
+func principalFromContext(ctx context.Context) (*Principal, error) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	if !ok {
+		return nil, logError(status.Error(codes.Unauthenticated, "call is not authorized"))
+	}
+	return principal, nil
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/Jay-T/go-devops-advanced-diploma/db/mock"
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// principalContext returns the context of a call authorized for the account.
+func principalContext(accountID int64, username string, roles ...string) context.Context {
+	return contextWithPrincipal(context.Background(), &Principal{AccountID: accountID, Username: username, Roles: roles})
+}
+
+func TestPrincipalFromContext(t *testing.T) {
+	_, err := principalFromContext(context.Background())
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// The username metadata is no caller.
+	_, err = principalFromContext(metadata.NewIncomingContext(context.Background(), metadata.Pairs("username", "admin")))
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	caller, err := principalFromContext(principalContext(1, "admin", RoleAdmin))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), caller.AccountID)
+	require.True(t, caller.HasRole(RoleAdmin))
+	require.False(t, (&Principal{}).HasRole(RoleAdmin))
+}
+
+func TestAuthorizeIgnoresUsernameHeader(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	jwtManager := newTestJWTManager(t, time.Minute)
+	accountStatus := NewAccountStatusCache(store, time.Minute)
+	interceptor := NewAuthInterceptor(jwtManager, accountStatus, NewTokenRevocationList(store), nil, map[string]bool{"/test/Method": true}, []string{"admin"})
+	server := NewAdminServer(store, nil, accountStatus, nil)
+
+	account := db.Account{ID: 2, Username: "user"}
+	token, err := jwtManager.GeneratetToken(&account)
+	require.NoError(t, err)
+
+	store.EXPECT().GetAccount(gomock.Any(), "user").Return(account, nil).AnyTimes()
+	// The client sends the username of an admin along with its own token.
+	ctx, err := interceptor.authorize(metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"username", "admin",
+		"authorization", token,
+		"username", "admin",
+	)), "/test/Method")
+	require.NoError(t, err)
+
+	caller, err := principalFromContext(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "user", caller.Username)
+	require.Equal(t, account.ID, caller.AccountID)
+	require.False(t, caller.HasRole(RoleAdmin))
+	require.NotEmpty(t, caller.TokenID)
+
+	_, err = server.ListAccounts(ctx, &pb.ListAccountsRequest{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestAuthorizeAdminRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	jwtManager := newTestJWTManager(t, time.Minute)
+	interceptor := NewAuthInterceptor(jwtManager, NewAccountStatusCache(store, time.Minute), NewTokenRevocationList(store), nil, map[string]bool{"/test/Method": true}, []string{"admin"})
+
+	account := db.Account{ID: 1, Username: "admin"}
+	token, err := jwtManager.GeneratetToken(&account)
+	require.NoError(t, err)
+
+	store.EXPECT().GetAccount(gomock.Any(), "admin").Return(account, nil)
+	ctx, err := interceptor.authorize(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", token)), "/test/Method")
+	require.NoError(t, err)
+	caller, err := principalFromContext(ctx)
+	require.NoError(t, err)
+	require.True(t, caller.HasRole(RoleAdmin))
+}
+
+func TestAuthorizeRecreatedAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	jwtManager := newTestJWTManager(t, time.Minute)
+	interceptor := NewAuthInterceptor(jwtManager, NewAccountStatusCache(store, time.Minute), NewTokenRevocationList(store), nil, map[string]bool{"/test/Method": true}, nil)
+
+	token, err := jwtManager.GeneratetToken(&db.Account{ID: 2, Username: "user"})
+	require.NoError(t, err)
+
+	// The account was deleted and registered again under the same name.
+	store.EXPECT().GetAccount(gomock.Any(), "user").Return(db.Account{ID: 3, Username: "user"}, nil)
+	_, err = interceptor.authorize(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", token)), "/test/Method")
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+)
+
+// Quota holds the storage limits of an account, zero means unlimited.
+type Quota struct {
+	Bytes   int64
+	Files   int64
+	Secrets int64
+}
+
+// NewQuota returns the default quota configured for all accounts.
+func NewQuota(cfg *Config) Quota {
+	return Quota{
+		Bytes:   cfg.QuotaBytes,
+		Files:   cfg.QuotaFiles,
+		Secrets: cfg.QuotaSecrets,
+	}
+}
+
+// forAccount applies the overrides stored in the account row to the default quota.
+func (q Quota) forAccount(acc db.Account) Quota {
+	if acc.QuotaBytes.Valid {
+		q.Bytes = acc.QuotaBytes.Int64
+	}
+	if acc.QuotaFiles.Valid {
+		q.Files = acc.QuotaFiles.Int64
+	}
+	if acc.QuotaSecrets.Valid {
+		q.Secrets = acc.QuotaSecrets.Int64
+	}
+
+	return q
+}
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"database/sql"
+	"testing"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaForAccount(t *testing.T) {
+	quota := NewQuota(&Config{
+		QuotaBytes:   1000,
+		QuotaFiles:   10,
+		QuotaSecrets: 5,
+	})
+
+	require.Equal(t, quota, quota.forAccount(db.Account{}))
+
+	acc := db.Account{
+		QuotaBytes:   sql.NullInt64{Int64: 0, Valid: true},
+		QuotaSecrets: sql.NullInt64{Int64: 50, Valid: true},
+	}
+	require.Equal(t, Quota{Bytes: 0, Files: 10, Secrets: 50}, quota.forAccount(acc))
+}
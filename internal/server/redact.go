@@ -0,0 +1,163 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const redactedValue = "[REDACTED]"
+
+func isSensitive(fd protoreflect.FieldDescriptor) bool {
+	opts, ok := fd.Options().(*descriptorpb.FieldOptions)
+	if !ok || opts == nil {
+		return false
+	}
+	return proto.GetExtension(opts, pb.E_Sensitive).(bool)
+}
+
+// redact returns a copy of the message with the fields marked sensitive in
+// the proto files masked. Strings read as redactedValue, the other kinds are
+// cleared. The message itself is not changed.
+func redact(msg proto.Message) proto.Message {
+	clone := proto.Clone(msg)
+	redactMessage(clone.ProtoReflect())
+	return clone
+}
+
+func redactMessage(m protoreflect.Message) {
+	// The fields are collected first, a message must not change while it is
+	// ranged over.
+	var fields []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		fields = append(fields, fd)
+		return true
+	})
+
+	for _, fd := range fields {
+		switch {
+		case isSensitive(fd):
+			redactField(m, fd)
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				m.Get(fd).Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+					redactMessage(v.Message())
+					return true
+				})
+			}
+		case fd.Message() != nil && fd.IsList():
+			list := m.Get(fd).List()
+			for i := 0; i < list.Len(); i++ {
+				redactMessage(list.Get(i).Message())
+			}
+		case fd.Message() != nil:
+			redactMessage(m.Mutable(fd).Message())
+		}
+	}
+}
+
+func redactField(m protoreflect.Message, fd protoreflect.FieldDescriptor) {
+	if fd.Kind() != protoreflect.StringKind || fd.IsMap() {
+		m.Clear(fd)
+		return
+	}
+
+	if !fd.IsList() {
+		m.Set(fd, protoreflect.ValueOfString(redactedValue))
+		return
+	}
+	list := m.Get(fd).List()
+	for i := 0; i < list.Len(); i++ {
+		list.Set(i, protoreflect.ValueOfString(redactedValue))
+	}
+}
+
+// redactedJSON renders the message for the logs with the sensitive fields
+// masked.
+func redactedJSON(msg interface{}) []byte {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return []byte("null")
+	}
+
+	data, err := protojson.Marshal(redact(m))
+	if err != nil {
+		return []byte("null")
+	}
+	return data
+}
+
+// LoggingInterceptor logs the gRPC calls with their requests. Only the
+// redacted requests are logged, never the passwords, tokens or content.
+type LoggingInterceptor struct{}
+
+func NewLoggingInterceptor() *LoggingInterceptor {
+	return &LoggingInterceptor{}
+}
+
+func (interceptor *LoggingInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+
+		log.Debug().
+			Str("method", info.FullMethod).
+			RawJSON("request", redactedJSON(req)).
+			Str("code", status.Code(err).String()).
+			Dur("duration", time.Since(start)).
+			Msg("gRPC call")
+
+		return res, err
+	}
+}
+
+func (interceptor *LoggingInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		start := time.Now()
+		err := handler(srv, &loggingServerStream{ServerStream: stream, method: info.FullMethod})
+
+		log.Debug().
+			Str("method", info.FullMethod).
+			Str("code", status.Code(err).String()).
+			Dur("duration", time.Since(start)).
+			Msg("gRPC stream")
+
+		return err
+	}
+}
+
+// loggingServerStream logs the received messages of a stream, the chunks of
+// a file are redacted like the fields of the unary requests.
+type loggingServerStream struct {
+	grpc.ServerStream
+	method string
+}
+
+func (stream *loggingServerStream) RecvMsg(m interface{}) error {
+	err := stream.ServerStream.RecvMsg(m)
+	if err == nil {
+		log.Trace().
+			Str("method", stream.method).
+			RawJSON("request", redactedJSON(m)).
+			Msg("gRPC stream message")
+	}
+	return err
+}
@@ -0,0 +1,239 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// minS3PartSize is the smallest part S3 accepts in a multipart upload.
+const minS3PartSize = 5 << 20
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	Prefix    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	// PartSize is the size of a multipart upload part. The content is
+	// uploaded in a single request when it fits into one part.
+	PartSize uint64
+	// Transport overrides the default HTTP transport, e.g. to trust a private CA.
+	Transport http.RoundTripper
+}
+
+// S3FileContentSaver keeps file content in an S3 compatible object storage,
+// so several server replicas can share it.
+type S3FileContentSaver struct {
+	client   *minio.Client
+	bucket   string
+	prefix   string
+	partSize uint64
+}
+
+func NewS3FileContentSaver(cfg S3Config) (FileContentSaver, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket is not set")
+	}
+
+	if cfg.PartSize < minS3PartSize {
+		return nil, fmt.Errorf("s3 part size must be at least %d bytes", minS3PartSize)
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:     credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure:    cfg.UseSSL,
+		Region:    cfg.Region,
+		Transport: cfg.Transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create s3 client: %w", err)
+	}
+
+	return &S3FileContentSaver{
+		client:   client,
+		bucket:   cfg.Bucket,
+		prefix:   cfg.Prefix,
+		partSize: cfg.PartSize,
+	}, nil
+}
+
+func (s *S3FileContentSaver) objectKey(hash string) (string, error) {
+	if err := validateBlobHash(hash); err != nil {
+		return "", err
+	}
+
+	return path.Join(s.prefix, hash[:2], hash), nil
+}
+
+// Save uploads the content in a single request when it fits into one part and
+// switches to a multipart upload otherwise.
+func (s *S3FileContentSaver) Save(ctx context.Context, hash string, content io.Reader, size int64) error {
+	key, err := s.objectKey(hash)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.PutObject(ctx, s.bucket, key, content, size, minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+		PartSize:    s.partSize,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot upload object: %w", err)
+	}
+
+	return nil
+}
+
+func (s *S3FileContentSaver) Open(ctx context.Context, hash string, offset int64) (io.ReadCloser, error) {
+	key, err := s.objectKey(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get object: %w", err)
+	}
+
+	// GetObject is lazy, stat the object to report a missing one right away.
+	info, err := object.Stat()
+	if err != nil {
+		object.Close()
+		return nil, fmt.Errorf("cannot get object: %w", err)
+	}
+
+	// A range starting at the end of the object is rejected by S3.
+	if offset == info.Size {
+		object.Close()
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+
+	// The object fetches the rest of the content with a range request.
+	_, err = object.Seek(offset, io.SeekStart)
+	if err != nil {
+		object.Close()
+		return nil, fmt.Errorf("cannot seek object: %w", err)
+	}
+
+	return object, nil
+}
+
+func (s *S3FileContentSaver) Exists(ctx context.Context, hash string) (bool, error) {
+	key, err := s.objectKey(hash)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return false, nil
+		}
+		return false, fmt.Errorf("cannot stat object: %w", err)
+	}
+
+	return true, nil
+}
+
+func (s *S3FileContentSaver) Delete(ctx context.Context, hash string) error {
+	key, err := s.objectKey(hash)
+	if err != nil {
+		return err
+	}
+
+	err = s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("cannot remove object: %w", err)
+	}
+
+	return nil
+}
+
+// listPrefix is the prefix shared by the keys of all blobs.
+func (s *S3FileContentSaver) listPrefix() string {
+	prefix := path.Join(s.prefix)
+	if prefix == "" {
+		return ""
+	}
+	return prefix + "/"
+}
+
+// Walk lists the two character "directories" under the prefix one by one,
+// the same layout the disk storage walks. Keys not made by objectKey are skipped.
+func (s *S3FileContentSaver) Walk(ctx context.Context, fn func(hash string, modTime time.Time) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	// Stops the listing when fn fails halfway.
+	defer cancel()
+
+	var dirs []string
+	for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: s.listPrefix()}) {
+		if object.Err != nil {
+			return fmt.Errorf("cannot list objects: %w", object.Err)
+		}
+		if strings.HasSuffix(object.Key, "/") {
+			dirs = append(dirs, object.Key)
+		}
+	}
+
+	for _, dir := range dirs {
+		for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: dir}) {
+			if object.Err != nil {
+				return fmt.Errorf("cannot list objects: %w", object.Err)
+			}
+
+			hash := path.Base(object.Key)
+			if key, err := s.objectKey(hash); err != nil || key != object.Key {
+				continue
+			}
+
+			err := fn(hash, object.LastModified)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// CleanPartial aborts the multipart uploads Save did not get to complete.
+func (s *S3FileContentSaver) CleanPartial(ctx context.Context, before time.Time, dryRun bool) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var removed []string
+	core := minio.Core{Client: s.client}
+
+	uploads := s.client.ListIncompleteUploads(ctx, s.bucket, s.listPrefix(), true)
+	for upload := range uploads {
+		if upload.Err != nil {
+			return removed, fmt.Errorf("cannot list uploads: %w", upload.Err)
+		}
+		if !upload.Initiated.Before(before) {
+			continue
+		}
+
+		// Only this upload is aborted, a newer one of the same object
+		// may still be running.
+		if !dryRun {
+			err := core.AbortMultipartUpload(ctx, s.bucket, upload.Key, upload.UploadID)
+			if err != nil {
+				return removed, fmt.Errorf("cannot abort upload: %w", err)
+			}
+		}
+
+		removed = append(removed, upload.Key)
+	}
+
+	return removed, nil
+}
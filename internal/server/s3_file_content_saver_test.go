@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+	"github.com/stretchr/testify/require"
+)
+
+const testS3Bucket = "gophkeeper"
+
+func TestS3FileContentSaver(t *testing.T) {
+	backend := s3mem.New()
+	require.NoError(t, backend.CreateBucket(testS3Bucket))
+
+	// Over plain HTTP the client signs payload chunks in the aws-chunked
+	// encoding the fake does not understand, TLS keeps the payload as is.
+	fake := httptest.NewTLSServer(gofakes3.New(backend).Server())
+	defer fake.Close()
+
+	saver, err := NewS3FileContentSaver(S3Config{
+		Endpoint:  strings.TrimPrefix(fake.URL, "https://"),
+		Region:    defaultS3Region,
+		Bucket:    testS3Bucket,
+		Prefix:    "fs",
+		AccessKey: "access",
+		SecretKey: "secret",
+		UseSSL:    true,
+		PartSize:  minS3PartSize,
+		Transport: fake.Client().Transport,
+	})
+	require.NoError(t, err)
+
+	testFileContentSaver(t, saver)
+}
+
+// TestS3FileContentSaverMinIO runs the suite against a real server, e.g.
+// docker run -p 9000:9000 minio/minio server /data
+// TEST_S3_ENDPOINT=localhost:9000 TEST_S3_BUCKET=gophkeeper go test ./internal/server
+func TestS3FileContentSaverMinIO(t *testing.T) {
+	endpoint := os.Getenv("TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("TEST_S3_ENDPOINT is not set")
+	}
+
+	saver, err := NewS3FileContentSaver(S3Config{
+		Endpoint:  endpoint,
+		Region:    defaultS3Region,
+		Bucket:    os.Getenv("TEST_S3_BUCKET"),
+		Prefix:    "test",
+		AccessKey: os.Getenv("TEST_S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("TEST_S3_SECRET_KEY"),
+		PartSize:  minS3PartSize,
+	})
+	require.NoError(t, err)
+
+	testFileContentSaver(t, saver)
+}
@@ -9,56 +9,50 @@ import (
 	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
-func getUsernameFromContext(ctx context.Context) (string, error) {
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return "", logError(status.Errorf(codes.Unauthenticated, "metadata is not provided"))
-	}
-
-	values := md["username"]
-	if len(values) == 0 {
-		return "", logError(status.Errorf(codes.Internal, "no username found in context"))
-	}
-
-	return values[0], nil
-}
-
 type SecretServer struct {
 	secretStore db.Store
+	quota       Quota
 	pb.UnimplementedSecretServer
 }
 
-func NewSecretServer(secretStore db.Store) *SecretServer {
-	return &SecretServer{secretStore, pb.UnimplementedSecretServer{}}
+func NewSecretServer(secretStore db.Store, quota Quota) *SecretServer {
+	return &SecretServer{secretStore, quota, pb.UnimplementedSecretServer{}}
 }
 
 func (s *SecretServer) CreateSecret(ctx context.Context, in *pb.CreateSecretRequest) (*pb.CreateSecretResponse, error) {
-	username, err := getUsernameFromContext(ctx)
+	caller, err := principalFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Info().Msgf("Got CreateSecret request for login '%s'", username)
+	log.Info().Msgf("Got CreateSecret request for login '%s'", caller.Username)
 
-	account, err := s.secretStore.GetAccount(ctx, username)
+	account, err := s.secretStore.GetAccountByID(ctx, caller.AccountID)
 	if err != nil {
 		return nil, logError(status.Errorf(codes.Internal, "cannot get account from db. Err :%s", err))
 	}
 
 	// TODO(): ADD VALUE ENCRYPTION HERE!
 
-	arg := db.CreateSecretParams{
-		AccountID: account.ID,
-		Key:       in.Data.Key,
-		Value:     in.Data.Value,
+	quota := s.quota.forAccount(account)
+
+	arg := db.CreateSecretTxParams{
+		CreateSecretParams: db.CreateSecretParams{
+			AccountID: account.ID,
+			Key:       in.Data.Key,
+			Value:     in.Data.Value,
+		},
+		MaxSecrets: quota.Secrets,
 	}
 
-	secret, err := s.secretStore.CreateSecret(ctx, arg)
+	secret, err := s.secretStore.CreateSecretTx(ctx, arg)
 	if err != nil {
+		if err == db.ErrQuotaExceeded {
+			return nil, logError(status.Errorf(codes.ResourceExhausted, "secrets quota exceeded: %d secrets allowed", quota.Secrets))
+		}
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
 			case "unique_violation":
@@ -83,21 +77,16 @@ func (s *SecretServer) UpdateSecret(context.Context, *pb.UpdateSecretRequest) (*
 }
 
 func (s *SecretServer) DeleteSecret(ctx context.Context, in *pb.DeleteSecretRequest) (*pb.DeleteSecretResponse, error) {
-	username, err := getUsernameFromContext(ctx)
+	caller, err := principalFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	account, err := s.secretStore.GetAccount(ctx, username)
-	if err != nil {
-		return nil, logError(status.Errorf(codes.Internal, "cannot get account from db. Err :%s", err))
-	}
-
-	log.Info().Msgf("Got DeleteSecret request for login '%s'", username)
+	log.Info().Msgf("Got DeleteSecret request for login '%s'", caller.Username)
 
 	arg := db.GetSecretParams{
 		Key:       in.Key,
-		AccountID: account.ID,
+		AccountID: caller.AccountID,
 	}
 	secret, err := s.secretStore.GetSecret(ctx, arg)
 	if err != nil {
@@ -110,7 +99,7 @@ func (s *SecretServer) DeleteSecret(ctx context.Context, in *pb.DeleteSecretRequ
 
 	arg2 := db.DeleteSecretParams{
 		Key:       secret.Key,
-		AccountID: account.ID,
+		AccountID: caller.AccountID,
 	}
 
 	err = s.secretStore.DeleteSecret(ctx, arg2)
@@ -2,21 +2,21 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
+	"time"
 
 	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
 	pb "github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 )
 
-const (
-	secretKey = "secret_key"
-)
-
 var _ Server = (*GRPCServer)(nil)
 
 type GenericService struct {
@@ -37,10 +37,16 @@ func NewGenericServer(ctx context.Context, cfg *Config) (*GenericService, error)
 type Server interface {
 	StartServer(ctx context.Context)
 	StopServer(ctx context.Context, cancel context.CancelFunc)
+	ReloadKeys() error
 }
 type GRPCServer struct {
 	*GenericService
-	store db.Store
+	store            db.Store
+	fileContentSaver FileContentSaver
+	keyWrapper       *KeyWrapper
+	shareSigner      *ShareSigner
+	jwtManager       *JWTManager
+	tlsConfig        *tls.Config
 }
 
 func NewServer(ctx context.Context, cfg *Config, store db.Store) (Server, error) {
@@ -49,27 +55,100 @@ func NewServer(ctx context.Context, cfg *Config, store db.Store) (Server, error)
 		return nil, err
 	}
 
+	fileContentSaver, err := NewFileContentSaver(cfg, store)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateCodec(cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without the key new content is stored in plaintext.
+	var keyWrapper *KeyWrapper
+	if cfg.EncryptionKey != "" {
+		keyWrapper, err = NewKeyWrapper(cfg.EncryptionKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	shareSigner, err := NewShareSigner(cfg.ShareKey)
+	if err != nil {
+		return nil, err
+	}
+
+	jwtKeys, err := LoadJWTKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+	jwtManager, err := NewJWTManager(jwtKeys, cfg.TokenLifeTime, cfg.JWTIssuer, cfg.JWTAudience)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := NewServerTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	return &GRPCServer{
 		genericService,
 		store,
+		fileContentSaver,
+		keyWrapper,
+		shareSigner,
+		jwtManager,
+		tlsConfig,
 	}, nil
 }
 func protectedMethods() map[string]bool {
 	const (
-		protectedSecretServicePath = "/go_devops_advanced_diploma.Secret/"
-		protectedFileServicePath   = "/go_devops_advanced_diploma.File/"
+		protectedSecretServicePath  = "/go_devops_advanced_diploma.Secret/"
+		protectedFileServicePath    = "/go_devops_advanced_diploma.File/"
+		protectedAccountServicePath = "/go_devops_advanced_diploma.Account/"
+		protectedShareServicePath   = "/go_devops_advanced_diploma.Share/"
+		protectedAdminServicePath   = "/go_devops_advanced_diploma.Admin/"
+		protectedAuthServicePath    = "/go_devops_advanced_diploma.Authentication/"
 	)
 	return map[string]bool{
-		protectedSecretServicePath + "CreateSecret": true,
-		protectedSecretServicePath + "DeleteSecret": true,
-		protectedSecretServicePath + "GetSecret":    true,
-		protectedSecretServicePath + "ListSecret":   true,
-		protectedSecretServicePath + "UpdateSecret": true,
-		protectedFileServicePath + "CreateFile":     true,
-		protectedFileServicePath + "DeleteFile":     true,
-		protectedFileServicePath + "GetFile":        true,
-		protectedFileServicePath + "ListFile":       true,
-		protectedFileServicePath + "UpdateFile":     true,
+		protectedSecretServicePath + "CreateSecret":           true,
+		protectedSecretServicePath + "DeleteSecret":           true,
+		protectedSecretServicePath + "GetSecret":              true,
+		protectedSecretServicePath + "ListSecret":             true,
+		protectedSecretServicePath + "UpdateSecret":           true,
+		protectedFileServicePath + "CreateFile":               true,
+		protectedFileServicePath + "DeleteFile":               true,
+		protectedFileServicePath + "GetFile":                  true,
+		protectedFileServicePath + "ListFile":                 true,
+		protectedFileServicePath + "UpdateFile":               true,
+		protectedFileServicePath + "ListFileVersions":         true,
+		protectedFileServicePath + "RestoreFileVersion":       true,
+		protectedFileServicePath + "MakeDirectory":            true,
+		protectedFileServicePath + "RemoveDirectory":          true,
+		protectedFileServicePath + "MoveFile":                 true,
+		protectedFileServicePath + "SetFileMetadata":          true,
+		protectedFileServicePath + "DeleteFileMetadata":       true,
+		protectedFileServicePath + "ArchiveDirectory":         true,
+		protectedAccountServicePath + "GetUsage":              true,
+		protectedShareServicePath + "CreateShareLink":         true,
+		protectedShareServicePath + "ListShareLinks":          true,
+		protectedShareServicePath + "RevokeShareLink":         true,
+		protectedAdminServicePath + "ListAccounts":            true,
+		protectedAdminServicePath + "BlockAccount":            true,
+		protectedAdminServicePath + "UnblockAccount":          true,
+		protectedAdminServicePath + "DeleteAccount":           true,
+		protectedAdminServicePath + "AddClientCertificate":    true,
+		protectedAdminServicePath + "ListClientCertificates":  true,
+		protectedAdminServicePath + "RemoveClientCertificate": true,
+		protectedAuthServicePath + "Logout":                   true,
+		protectedAuthServicePath + "RevokeAllSessions":        true,
+		protectedAuthServicePath + "Enroll2FA":                true,
+		protectedAuthServicePath + "Confirm2FA":               true,
+		protectedAuthServicePath + "Disable2FA":               true,
+		protectedAuthServicePath + "RegenerateRecoveryCodes":  true,
+		protectedAuthServicePath + "ChangePassword":           true,
 	}
 }
 
@@ -84,24 +163,63 @@ func (s *GRPCServer) StartServer(ctx context.Context) {
 	if err != nil {
 		log.Fatal().Msg("cannot seed users")
 	}
-	jwtManager := NewJWTManager(secretKey, s.Cfg.TokenLifeTime)
-	authServer := NewAuthServer(s.store, jwtManager)
-	interceptor := NewAuthInterceptor(jwtManager, protectedMethods())
+	accountStatus := NewAccountStatusCache(s.store, accountStatusTTL)
+	revocations := NewTokenRevocationList(s.store)
+	err = revocations.Load(ctx)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot load revoked tokens")
+	}
+	loginLimiter := NewLoginLimiter(s.store, NewLoginLimits(s.Cfg))
+	twoFactor := NewTwoFactor(s.store, s.keyWrapper, s.Cfg.TOTPIssuer)
+	authServer := NewAuthServer(s.store, s.jwtManager, accountStatus, revocations, loginLimiter, twoFactor, NewPasswordPolicy(s.Cfg), s.Cfg.AdminUsers, s.Cfg.RefreshTokenLifeTime)
+	certificates := NewClientCertificateCache(s.store, accountStatusTTL)
+	interceptor := NewAuthInterceptor(s.jwtManager, accountStatus, revocations, certificates, protectedMethods(), s.Cfg.AdminUsers)
 
-	secretServer := NewSecretServer(s.store)
-	fileServer := NewFileServer(s.store)
+	quota := NewQuota(s.Cfg)
+	secretServer := NewSecretServer(s.store, quota)
+	fileServer := NewFileServer(s.store, s.fileContentSaver, quota, s.Cfg.Compression, s.keyWrapper)
+	accountServer := NewAccountServer(s.store, quota)
 
+	shareBaseURL := s.Cfg.ShareBaseURL
+	if shareBaseURL == "" {
+		shareBaseURL = "http://" + s.Cfg.HTTPAddress
+	}
+	shareServer := NewShareServer(s.store, fileServer, s.shareSigner, accountStatus, loginLimiter, shareBaseURL)
+	adminServer := NewAdminServer(s.store, fileServer, accountStatus, certificates)
+
+	// The calls are logged before the authorization, the rejected ones too.
+	logging := NewLoggingInterceptor()
 	serverOptions := []grpc.ServerOption{
-		grpc.UnaryInterceptor(interceptor.Unary()),
-		grpc.StreamInterceptor(interceptor.Stream()),
+		grpc.ChainUnaryInterceptor(logging.Unary(), interceptor.Unary()),
+		grpc.ChainStreamInterceptor(logging.Stream(), interceptor.Stream()),
+	}
+	if s.tlsConfig != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(s.tlsConfig)))
 	}
 	server := grpc.NewServer(serverOptions...)
 
 	pb.RegisterSecretServer(server, secretServer)
 	pb.RegisterFileServer(server, fileServer)
 	pb.RegisterAuthenticationServer(server, authServer)
+	pb.RegisterAccountServer(server, accountServer)
+	pb.RegisterShareServer(server, shareServer)
+	pb.RegisterAdminServer(server, adminServer)
 	reflection.Register(server)
 
+	if s.Cfg.PruneInterval > 0 {
+		go fileServer.RunVersionRetention(ctx, NewVersionRetention(s.Cfg), s.Cfg.PruneInterval)
+	}
+	// The revocations of other instances are loaded even without the cleanup.
+	go revocations.Run(ctx, accountStatusTTL, s.Cfg.AuthCleanupInterval)
+	if s.Cfg.AuthCleanupInterval > 0 {
+		go authServer.RunRefreshTokenCleanup(ctx, s.Cfg.AuthCleanupInterval)
+		go loginLimiter.RunCleanup(ctx, s.Cfg.AuthCleanupInterval)
+		go twoFactor.RunCleanup(ctx, s.Cfg.AuthCleanupInterval)
+	}
+	if s.Cfg.FsckInterval > 0 {
+		go fileServer.RunFsck(ctx, NewFsckOptions(s.Cfg), s.Cfg.FsckInterval)
+	}
+
 	go func() {
 		log.Info().Msg(fmt.Sprintf("Starting GRPC server with following config: %+v", s.Cfg))
 		if err := server.Serve(listen); err != nil {
@@ -109,10 +227,53 @@ func (s *GRPCServer) StartServer(ctx context.Context) {
 		}
 	}()
 
+	if s.Cfg.HTTPAddress != "" {
+		httpServer := &http.Server{
+			Addr:              s.Cfg.HTTPAddress,
+			Handler:           newHTTPHandler(shareServer, s.jwtManager),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			log.Info().Msgf("Serving share links and jwks on %s", s.Cfg.HTTPAddress)
+			if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatal().Err(err).Str("func", "StartServer")
+			}
+		}()
+		defer httpServer.Shutdown(context.Background())
+	}
+
 	<-ctx.Done()
 	log.Info().Msg("Finished to serve gRPC requests")
 }
 
+func newHTTPHandler(shareServer *ShareServer, jwtManager *JWTManager) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(shareLinkPath, shareServer)
+	mux.Handle(jwksPath, JWKSHandler(jwtManager))
+	return mux
+}
+
+// ReloadKeys rereads the token signing key file. Tokens signed with the keys
+// removed from it stay valid until they expire.
+func (s *GRPCServer) ReloadKeys() error {
+	if s.Cfg.JWTKeyFile == "" {
+		return fmt.Errorf("jwt key file is not set, nothing to reload")
+	}
+
+	keys, err := LoadJWTKeyFile(s.Cfg.JWTKeyFile)
+	if err != nil {
+		return err
+	}
+
+	err = s.jwtManager.SetKeys(keys)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msgf("Reloaded jwt keys, signing with '%s'", s.jwtManager.SigningKeyID())
+	return nil
+}
+
 func (s *GRPCServer) StopServer(ctx context.Context, cancel context.CancelFunc) {
 	log.Info().Msg("Received a SIGINT! Stopping application")
 	cancel()
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+const shareLinkPath = "/s/"
+
+// ServeHTTP streams the shared file. Every GET counts as a download, HEAD
+// only checks the link. The links of a blocked account stop working like its
+// tokens, within accountStatusTTL.
+func (s *ShareServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	token := strings.TrimPrefix(r.URL.Path, shareLinkPath)
+
+	tokenID, err := s.signer.Parse(token, time.Now())
+	if err == errShareTokenExpired {
+		http.Error(w, "link is expired", http.StatusGone)
+		return
+	}
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	link, err := s.store.GetShareLink(ctx, tokenID)
+	if err == sql.ErrNoRows {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("cannot get share link")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if link.Revoked {
+		http.Error(w, "link is revoked", http.StatusGone)
+		return
+	}
+
+	owner, err := s.accountStatus.Get(ctx, link.Username)
+	if err != nil {
+		log.Error().Err(err).Msg("cannot get share link owner status")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if owner.Blocked {
+		http.Error(w, "link is disabled", http.StatusForbidden)
+		return
+	}
+
+	if link.PasswordHash.Valid && !s.checkSharePassword(w, r, link.ID, link.PasswordHash.String) {
+		return
+	}
+
+	file, err := s.store.GetFileByID(ctx, link.FileID)
+	if err != nil || !file.Ready || !file.BlobHash.Valid {
+		http.NotFound(w, r)
+		return
+	}
+
+	blob, err := s.store.GetBlob(ctx, file.BlobHash.String)
+	if err != nil {
+		log.Error().Err(err).Msg("cannot get file blob")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Method == http.MethodHead {
+		s.writeShareHeaders(w, r, file, blob)
+		return
+	}
+
+	// The download is counted before streaming, so parallel requests
+	// cannot go over the limit.
+	counted, err := s.store.CountShareLinkDownload(ctx, link.ID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "link is used up", http.StatusGone)
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("cannot count share link download")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	content, err := s.fileServer.openBlobContent(ctx, blob, false, 0)
+	if err != nil {
+		log.Error().Err(err).Msg("cannot open shared file content")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	defer content.Close()
+
+	s.writeShareHeaders(w, r, file, blob)
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(w, hash), content)
+	if err == nil && hex.EncodeToString(hash.Sum(nil)) != blob.Hash {
+		err = errCorrupted
+	}
+	if err != nil {
+		// The status is sent already, dropping the connection is the only
+		// way to tell the client the content is incomplete.
+		log.Error().Err(err).Msgf("cannot stream shared file '/%s/%s'", file.Filepath, file.Filename)
+		panic(http.ErrAbortHandler)
+	}
+
+	log.Info().Msgf("Shared file '/%s/%s' downloaded %d times", file.Filepath, file.Filename, counted.Downloads)
+}
+
+// checkSharePassword answers the request and returns false unless it carries
+// the password of the link. Wrong passwords count against the link and the
+// peer address like failed logins, a request without one does not, browsers
+// send it only once asked.
+func (s *ShareServer) checkSharePassword(w http.ResponseWriter, r *http.Request, linkID int64, passwordHash string) bool {
+	ctx := r.Context()
+	ip := requestIP(r)
+	now := time.Now()
+
+	// Only a request with a password counts, it is reserved before the
+	// check so parallel guesses cannot pass the limits together.
+	_, password, ok := r.BasicAuth()
+	var attempt *LoginAttempt
+	var lockedFor time.Duration
+	var err error
+	if ok {
+		attempt, lockedFor, err = s.loginLimiter.ReserveShareLink(ctx, linkID, ip, now)
+	} else {
+		lockedFor, err = s.loginLimiter.CheckShareLink(ctx, linkID, ip, now)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("cannot check failed share link passwords")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return false
+	}
+	if lockedFor > 0 {
+		retryDelay := (lockedFor + time.Second - 1).Truncate(time.Second)
+		w.Header().Set("Retry-After", strconv.FormatInt(int64(retryDelay/time.Second), 10))
+		http.Error(w, "too many wrong passwords", http.StatusTooManyRequests)
+		return false
+	}
+
+	if ok {
+		if util.CheckPassword(password, passwordHash) == nil {
+			attempt.Succeeded(ctx)
+			return true
+		}
+		attempt.Failed()
+	}
+
+	w.Header().Set("WWW-Authenticate", `Basic realm="shared file", charset="UTF-8"`)
+	http.Error(w, "password required", http.StatusUnauthorized)
+	return false
+}
+
+// requestIP returns the address of the client without the port. Forwarding
+// headers are not trusted, anyone could set them to dodge the limits.
+func requestIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func (s *ShareServer) writeShareHeaders(w http.ResponseWriter, r *http.Request, file db.File, blob db.Blob) {
+	contentType := "application/octet-stream"
+	rows, err := s.store.ListFileMetadata(r.Context(), file.ID)
+	if err == nil {
+		if recorded := metadataToProto(rows).GetContentType(); recorded != "" {
+			contentType = recorded
+		}
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Length", strconv.FormatInt(blob.Size, 10))
+	// Shared content is never rendered in the browser as a page of the server.
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Filename}))
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
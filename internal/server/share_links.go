@@ -0,0 +1,277 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultShareLinkTTL = 24 * time.Hour
+	maxShareLinkTTL     = 30 * 24 * time.Hour
+
+	shareTokenIDSize  = 16
+	shareKeySize      = 32
+	shareTokenPayload = shareTokenIDSize + 8
+)
+
+var (
+	errShareTokenInvalid = errors.New("invalid share token")
+	errShareTokenExpired = errors.New("share token is expired")
+)
+
+// ShareSigner issues share link tokens. A token carries a random id and the
+// expiry time signed with HMAC-SHA256, so forged and expired tokens are
+// turned away without a database lookup.
+type ShareSigner struct {
+	key []byte
+}
+
+// NewShareSigner takes a hex encoded 32 byte key. Without a key a random one
+// is used, the links then stop working when the server restarts.
+func NewShareSigner(hexKey string) (*ShareSigner, error) {
+	if hexKey == "" {
+		log.Warn().Msg("share key is not set, share links will not survive a restart")
+		key := make([]byte, shareKeySize)
+		_, err := rand.Read(key)
+		if err != nil {
+			return nil, err
+		}
+		return &ShareSigner{key: key}, nil
+	}
+
+	key, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("share key is not hex encoded: %w", err)
+	}
+	if len(key) != shareKeySize {
+		return nil, fmt.Errorf("share key must be %d bytes, got %d", shareKeySize, len(key))
+	}
+
+	return &ShareSigner{key: key}, nil
+}
+
+func (s *ShareSigner) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// NewToken returns a token expiring at expiresAt and the id stored with the link.
+func (s *ShareSigner) NewToken(expiresAt time.Time) (string, string, error) {
+	payload := make([]byte, shareTokenPayload)
+	_, err := rand.Read(payload[:shareTokenIDSize])
+	if err != nil {
+		return "", "", err
+	}
+	binary.BigEndian.PutUint64(payload[shareTokenIDSize:], uint64(expiresAt.Unix()))
+
+	token := append(payload, s.sign(payload)...)
+	return base64.RawURLEncoding.EncodeToString(token), hex.EncodeToString(payload[:shareTokenIDSize]), nil
+}
+
+// Parse checks the signature and the expiry of the token and returns its id.
+func (s *ShareSigner) Parse(token string, now time.Time) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) != shareTokenPayload+sha256.Size {
+		return "", errShareTokenInvalid
+	}
+
+	payload, signature := raw[:shareTokenPayload], raw[shareTokenPayload:]
+	if !hmac.Equal(signature, s.sign(payload)) {
+		return "", errShareTokenInvalid
+	}
+
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload[shareTokenIDSize:])), 0)
+	if !now.Before(expiresAt) {
+		return "", errShareTokenExpired
+	}
+
+	return hex.EncodeToString(payload[:shareTokenIDSize]), nil
+}
+
+// ShareServer manages the share links of files, the downloads are served over
+// HTTP by ServeHTTP.
+type ShareServer struct {
+	store         db.Store
+	fileServer    *FileServer
+	signer        *ShareSigner
+	accountStatus *AccountStatusCache
+	loginLimiter  *LoginLimiter
+	baseURL       string
+	pb.UnimplementedShareServer
+}
+
+func NewShareServer(store db.Store, fileServer *FileServer, signer *ShareSigner, accountStatus *AccountStatusCache, loginLimiter *LoginLimiter, baseURL string) *ShareServer {
+	return &ShareServer{
+		store,
+		fileServer,
+		signer,
+		accountStatus,
+		loginLimiter,
+		strings.TrimSuffix(baseURL, "/"),
+		pb.UnimplementedShareServer{},
+	}
+}
+
+func (s *ShareServer) CreateShareLink(ctx context.Context, in *pb.CreateShareLinkRequest) (*pb.CreateShareLinkResponse, error) {
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Msgf("receive an CreateShareLink request from user %s", caller.Username)
+
+	ttl := defaultShareLinkTTL
+	if in.GetTtl() != nil {
+		ttl = in.GetTtl().AsDuration()
+	}
+	if ttl <= 0 || ttl > maxShareLinkTTL {
+		return nil, logError(status.Errorf(codes.InvalidArgument, "ttl must be positive and at most %s", maxShareLinkTTL))
+	}
+
+	if in.GetMaxDownloads() < 0 {
+		return nil, logError(status.Error(codes.InvalidArgument, "max_downloads cannot be negative"))
+	}
+
+	file, err := s.fileServer.getReadyFile(ctx, caller.AccountID, in.GetKey())
+	if err != nil {
+		return nil, err
+	}
+
+	var passwordHash sql.NullString
+	if in.GetPassword() != "" {
+		hash, err := util.HashPassword(in.GetPassword())
+		if err != nil {
+			return nil, logError(status.Errorf(codes.Internal, "cannot hash password: %v", err))
+		}
+		passwordHash = sql.NullString{String: hash, Valid: true}
+	}
+
+	// The token keeps whole seconds, so does the row.
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+	token, tokenID, err := s.signer.NewToken(expiresAt)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot create token: %v", err))
+	}
+
+	link, err := s.store.CreateShareLink(ctx, db.CreateShareLinkParams{
+		AccountID:    caller.AccountID,
+		FileID:       file.ID,
+		TokenID:      tokenID,
+		PasswordHash: passwordHash,
+		MaxDownloads: in.GetMaxDownloads(),
+		ExpiresAt:    expiresAt,
+	})
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot create share link: %v", err))
+	}
+
+	log.Info().Msgf("Shared file '/%s/%s' until %s", file.Filepath, file.Filename, expiresAt)
+	return &pb.CreateShareLinkResponse{
+		Link:  shareLinkToProto(link, file.Filepath, file.Filename),
+		Token: token,
+		Url:   s.baseURL + "/s/" + token,
+	}, nil
+}
+
+func (s *ShareServer) ListShareLinks(ctx context.Context, in *pb.ListShareLinksRequest) (*pb.ListShareLinksResponse, error) {
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Msgf("receive an ListShareLinks request from user %s", caller.Username)
+
+	arg := db.ListShareLinksParams{AccountID: caller.AccountID}
+	if in.GetKey().GetFilename() != "" {
+		file, err := s.fileServer.getReadyFile(ctx, caller.AccountID, in.GetKey())
+		if err != nil {
+			return nil, err
+		}
+		arg.FileID = sql.NullInt64{Int64: file.ID, Valid: true}
+	}
+
+	links, err := s.store.ListShareLinks(ctx, arg)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot list share links: %v", err))
+	}
+
+	res := &pb.ListShareLinksResponse{}
+	for _, link := range links {
+		res.Links = append(res.Links, shareLinkToProto(db.ShareLink{
+			ID:           link.ID,
+			AccountID:    link.AccountID,
+			FileID:       link.FileID,
+			TokenID:      link.TokenID,
+			PasswordHash: link.PasswordHash,
+			MaxDownloads: link.MaxDownloads,
+			Downloads:    link.Downloads,
+			Revoked:      link.Revoked,
+			ExpiresAt:    link.ExpiresAt,
+			CreatedAt:    link.CreatedAt,
+		}, link.Filepath, link.Filename))
+	}
+
+	return res, nil
+}
+
+func (s *ShareServer) RevokeShareLink(ctx context.Context, in *pb.RevokeShareLinkRequest) (*pb.RevokeShareLinkResponse, error) {
+	caller, err := principalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Msgf("receive an RevokeShareLink request from user %s", caller.Username)
+
+	link, err := s.store.RevokeShareLink(ctx, db.RevokeShareLinkParams{
+		ID:        in.GetId(),
+		AccountID: caller.AccountID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, logError(status.Errorf(codes.NotFound, "cannot find share link %d", in.GetId()))
+		}
+		return nil, logError(status.Errorf(codes.Internal, "cannot revoke share link: %v", err))
+	}
+
+	file, err := s.store.GetFileByID(ctx, link.FileID)
+	if err != nil {
+		return nil, logError(status.Errorf(codes.Internal, "cannot get file: %v", err))
+	}
+
+	return &pb.RevokeShareLinkResponse{Link: shareLinkToProto(link, file.Filepath, file.Filename)}, nil
+}
+
+func shareLinkToProto(link db.ShareLink, filepath string, filename string) *pb.ShareLink {
+	return &pb.ShareLink{
+		Id: link.ID,
+		File: &pb.FileInfo{
+			Filepath: filepath,
+			Filename: filename,
+		},
+		ExpiresAt:    timestamppb.New(link.ExpiresAt),
+		MaxDownloads: link.MaxDownloads,
+		Downloads:    link.Downloads,
+		HasPassword:  link.PasswordHash.Valid,
+		Revoked:      link.Revoked,
+		CreatedAt:    timestamppb.New(link.CreatedAt),
+	}
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/Jay-T/go-devops-advanced-diploma/db/mock"
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareSigner(t *testing.T) {
+	signer, err := NewShareSigner(strings.Repeat("ab", shareKeySize))
+	require.NoError(t, err)
+
+	now := time.Now()
+	token, tokenID, err := signer.NewToken(now.Add(time.Hour))
+	require.NoError(t, err)
+
+	parsed, err := signer.Parse(token, now)
+	require.NoError(t, err)
+	require.Equal(t, tokenID, parsed)
+
+	_, err = signer.Parse(token, now.Add(2*time.Hour))
+	require.Equal(t, errShareTokenExpired, err)
+
+	tampered := []byte(token)
+	tampered[3] ^= 1
+	_, err = signer.Parse(string(tampered), now)
+	require.Equal(t, errShareTokenInvalid, err)
+
+	other, err := NewShareSigner("")
+	require.NoError(t, err)
+	_, err = other.Parse(token, now)
+	require.Equal(t, errShareTokenInvalid, err)
+
+	_, err = NewShareSigner("abcd")
+	require.Error(t, err)
+}
+
+func TestServeShareLink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	saver := NewDiskFileContentSaver(t.TempDir())
+	fileServer := NewFileServer(store, saver, Quota{}, CodecIdentity, nil)
+	signer, err := NewShareSigner("")
+	require.NoError(t, err)
+	accountStatus := NewAccountStatusCache(store, time.Minute)
+	handler := NewShareServer(store, fileServer, signer, accountStatus, NewLoginLimiter(store, LoginLimits{}), "http://localhost")
+
+	content, hash := randomContent(100)
+	require.NoError(t, saver.Save(context.Background(), hash, bytes.NewReader(content), int64(len(content))))
+
+	passwordHash, err := util.HashPassword("secret")
+	require.NoError(t, err)
+
+	token, tokenID, err := signer.NewToken(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	link := db.GetShareLinkRow{
+		ID:           1,
+		AccountID:    1,
+		FileID:       2,
+		TokenID:      tokenID,
+		PasswordHash: sql.NullString{String: passwordHash, Valid: true},
+		MaxDownloads: 1,
+		Username:     "owner",
+	}
+	file := db.File{
+		ID:       2,
+		Filename: "report.pdf",
+		Ready:    true,
+		BlobHash: sql.NullString{String: hash, Valid: true},
+	}
+
+	store.EXPECT().GetShareLink(gomock.Any(), tokenID).Return(link, nil).AnyTimes()
+	store.EXPECT().GetAccount(gomock.Any(), "owner").Return(db.Account{ID: 1, Username: "owner"}, nil)
+	store.EXPECT().GetFileByID(gomock.Any(), file.ID).Return(file, nil).AnyTimes()
+	store.EXPECT().GetBlob(gomock.Any(), hash).
+		Return(db.Blob{Hash: hash, Size: int64(len(content)), Codec: CodecIdentity}, nil).
+		AnyTimes()
+	store.EXPECT().ListFileMetadata(gomock.Any(), file.ID).
+		Return([]db.FilesMetadatum{{Key: metadataContentType, Value: "application/pdf", System: true}}, nil).
+		AnyTimes()
+
+	get := func(path string, password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if password != "" {
+			req.SetBasicAuth("", password)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/s/"+token, "")
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+
+	rec = get("/s/"+token, "wrong")
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = get("/s/not-a-token", "secret")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	store.EXPECT().CountShareLinkDownload(gomock.Any(), link.ID).Return(db.ShareLink{ID: link.ID, Downloads: 1}, nil)
+
+	rec = get("/s/"+token, "secret")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, content, rec.Body.Bytes())
+	require.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+	require.Equal(t, `attachment; filename=report.pdf`, rec.Header().Get("Content-Disposition"))
+	require.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+
+	// The limit is enforced by the database, the row is not updated anymore.
+	store.EXPECT().CountShareLinkDownload(gomock.Any(), link.ID).Return(db.ShareLink{}, sql.ErrNoRows)
+
+	rec = get("/s/"+token, "secret")
+	require.Equal(t, http.StatusGone, rec.Code)
+
+	expired, _, err := signer.NewToken(time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	rec = get("/s/"+expired, "secret")
+	require.Equal(t, http.StatusGone, rec.Code)
+
+	// Blocking the owner disables the links, even with the right password.
+	accountStatus.Invalidate("owner")
+	store.EXPECT().GetAccount(gomock.Any(), "owner").Return(db.Account{ID: 1, Username: "owner", Blocked: true}, nil)
+
+	rec = get("/s/"+token, "secret")
+	require.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestServeShareLinkPasswordLockout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	fileServer := NewFileServer(store, NewDiskFileContentSaver(t.TempDir()), Quota{}, CodecIdentity, nil)
+	signer, err := NewShareSigner("")
+	require.NoError(t, err)
+	limits := LoginLimits{MaxFailures: 2, MaxIPFailures: 10, Backoff: time.Second, Lockout: time.Minute}
+	handler := NewShareServer(store, fileServer, signer, NewAccountStatusCache(store, time.Minute), NewLoginLimiter(store, limits), "http://localhost")
+
+	passwordHash, err := util.HashPassword("secret")
+	require.NoError(t, err)
+	token, tokenID, err := signer.NewToken(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	link := db.GetShareLinkRow{ID: 7, FileID: 2, TokenID: tokenID, PasswordHash: sql.NullString{String: passwordHash, Valid: true}, Username: "owner"}
+	store.EXPECT().GetShareLink(gomock.Any(), tokenID).Return(link, nil).AnyTimes()
+	store.EXPECT().GetAccount(gomock.Any(), "owner").Return(db.Account{Username: "owner"}, nil)
+
+	get := func(password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/s/"+token, nil)
+		req.RemoteAddr = "192.0.2.1:40000"
+		if password != "" {
+			req.SetBasicAuth("", password)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	store.EXPECT().
+		ListLoginFailures(gomock.Any(), db.ListLoginFailuresParams{Scope: loginScopeShareLink, Key: "7", Ip: "192.0.2.1"}).
+		Return(nil, nil)
+
+	// The request a browser sends before asking for the password is free.
+	rec := get("")
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	var lockedUntil time.Time
+	store.EXPECT().
+		ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.ReserveLoginAttemptTxParams) (db.ReserveLoginAttemptTxResult, error) {
+			require.Equal(t, []db.LoginKey{{Scope: loginScopeShareLink, Key: "7"}, {Scope: loginScopeIP, Key: "192.0.2.1"}}, arg.Keys)
+			result := reservedLogin(arg, 2, 2)
+			lockedUntil = result.Reserved[0].LockedUntil
+			return result, nil
+		})
+
+	rec = get("wrong")
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.WithinDuration(t, time.Now().Add(limits.Lockout), lockedUntil, 5*time.Second)
+
+	// Locked out, even the right password is not checked.
+	store.EXPECT().
+		ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+		Return(db.ReserveLoginAttemptTxResult{LockedUntil: lockedUntil}, db.ErrLoginLocked)
+
+	rec = get("secret")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "60", rec.Header().Get("Retry-After"))
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/rs/zerolog/log"
+)
+
+// TokenRevocationList holds the ids of the revoked access tokens. The list is
+// kept in the database and mirrored in memory, the authorized calls only read
+// the memory.
+type TokenRevocationList struct {
+	store db.Store
+
+	mu sync.RWMutex
+	// revoked maps the jti to the expiry of the token.
+	revoked map[string]time.Time
+}
+
+func NewTokenRevocationList(store db.Store) *TokenRevocationList {
+	return &TokenRevocationList{
+		store:   store,
+		revoked: make(map[string]time.Time),
+	}
+}
+
+// Load adds the revocations made by other server instances. Revocations are
+// never undone, so the loaded ones are merged into the known ones.
+func (l *TokenRevocationList) Load(ctx context.Context) error {
+	rows, err := l.store.ListRevokedTokens(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, row := range rows {
+		l.revoked[row.Jti] = row.ExpiresAt
+	}
+	return nil
+}
+
+// Revoke stores the revocation of the token. It is in effect on this instance
+// right away and on the others after their next Load.
+func (l *TokenRevocationList) Revoke(ctx context.Context, jti string, accountID int64, expiresAt time.Time) error {
+	err := l.store.CreateRevokedToken(ctx, db.CreateRevokedTokenParams{
+		Jti:       jti,
+		AccountID: accountID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.revoked[jti] = expiresAt
+	l.mu.Unlock()
+
+	return nil
+}
+
+// IsRevoked reports whether the token with the jti is revoked.
+func (l *TokenRevocationList) IsRevoked(jti string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	_, ok := l.revoked[jti]
+	return ok
+}
+
+// prune forgets the tokens expired by now, those are rejected anyway.
+func (l *TokenRevocationList) prune(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for jti, expiresAt := range l.revoked {
+		if expiresAt.Before(now) {
+			delete(l.revoked, jti)
+		}
+	}
+}
+
+// Run loads the revocations of other instances every syncInterval and deletes
+// the expired ones every pruneInterval until ctx is done. A pruneInterval of
+// 0 keeps the expired revocations.
+func (l *TokenRevocationList) Run(ctx context.Context, syncInterval time.Duration, pruneInterval time.Duration) {
+	syncTicker := time.NewTicker(syncInterval)
+	defer syncTicker.Stop()
+
+	// A nil channel never fires.
+	var prune <-chan time.Time
+	if pruneInterval > 0 {
+		pruneTicker := time.NewTicker(pruneInterval)
+		defer pruneTicker.Stop()
+		prune = pruneTicker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-syncTicker.C:
+			err := l.Load(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("cannot load revoked tokens")
+			}
+		case <-prune:
+			now := time.Now()
+			l.prune(now)
+
+			deleted, err := l.store.DeleteExpiredRevokedTokens(ctx, now)
+			if err != nil {
+				log.Error().Err(err).Msg("cannot delete expired revoked tokens")
+			}
+			if deleted > 0 {
+				log.Info().Msgf("Deleted %d expired revoked tokens", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/Jay-T/go-devops-advanced-diploma/db/mock"
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestLogout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	jwtManager := newTestJWTManager(t, time.Minute)
+	accountStatus := NewAccountStatusCache(store, time.Hour)
+	revocations := NewTokenRevocationList(store)
+	interceptor := NewAuthInterceptor(jwtManager, accountStatus, revocations, nil, map[string]bool{"/test/Method": true}, nil)
+	server := NewAuthServer(store, jwtManager, accountStatus, revocations, NewLoginLimiter(store, LoginLimits{}), NewTwoFactor(store, nil, "test"), PasswordPolicy{}, nil, time.Hour)
+
+	account := db.Account{ID: 2, Username: "user"}
+	token, err := jwtManager.GeneratetToken(&account)
+	require.NoError(t, err)
+	other, err := jwtManager.GeneratetToken(&account)
+	require.NoError(t, err)
+
+	store.EXPECT().GetAccount(gomock.Any(), "user").Return(account, nil).AnyTimes()
+	ctx, err := interceptor.authorize(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", token)), "/test/Method")
+	require.NoError(t, err)
+
+	caller, err := principalFromContext(ctx)
+	require.NoError(t, err)
+	store.EXPECT().
+		RevokeRefreshTokenFamilyOfToken(gomock.Any(), db.RevokeRefreshTokenFamilyOfTokenParams{
+			TokenHash: hashRefreshToken("refresh"),
+			AccountID: account.ID,
+		}).
+		Return(int64(1), nil)
+	store.EXPECT().
+		CreateRevokedToken(gomock.Any(), db.CreateRevokedTokenParams{
+			Jti:       caller.TokenID,
+			AccountID: account.ID,
+			ExpiresAt: caller.TokenExpiresAt,
+		}).
+		Return(nil)
+
+	_, err = server.Logout(ctx, &pb.LogoutRequest{RefreshToken: "refresh"})
+	require.NoError(t, err)
+
+	_, err = interceptor.authorize(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", token)), "/test/Method")
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Other sessions of the account are left alone.
+	_, err = interceptor.authorize(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", other)), "/test/Method")
+	require.NoError(t, err)
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	jwtManager := newTestJWTManager(t, time.Minute)
+	accountStatus := NewAccountStatusCache(store, time.Hour)
+	revocations := NewTokenRevocationList(store)
+	interceptor := NewAuthInterceptor(jwtManager, accountStatus, revocations, nil, map[string]bool{"/test/Method": true}, nil)
+	server := NewAuthServer(store, jwtManager, accountStatus, revocations, NewLoginLimiter(store, LoginLimits{}), NewTwoFactor(store, nil, "test"), PasswordPolicy{}, nil, time.Hour)
+
+	account := db.Account{ID: 2, Username: "user"}
+	token, err := jwtManager.GeneratetToken(&account)
+	require.NoError(t, err)
+
+	store.EXPECT().GetAccount(gomock.Any(), "user").Return(account, nil)
+	ctx, err := interceptor.authorize(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", token)), "/test/Method")
+	require.NoError(t, err)
+
+	store.EXPECT().
+		RevokeAllSessionsTx(gomock.Any(), db.RevokeAllSessionsTxParams{AccountID: account.ID}).
+		Return(int64(1), nil)
+	_, err = server.RevokeAllSessions(ctx, &pb.RevokeAllSessionsRequest{})
+	require.NoError(t, err)
+
+	revoked := account
+	revoked.SessionEpoch = 1
+	store.EXPECT().GetAccount(gomock.Any(), "user").Return(revoked, nil)
+
+	// The cached status is dropped, the old token stops working at once.
+	_, err = interceptor.authorize(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", token)), "/test/Method")
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// A new login gets a token of the new epoch.
+	fresh, err := jwtManager.GeneratetToken(&revoked)
+	require.NoError(t, err)
+	_, err = interceptor.authorize(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", fresh)), "/test/Method")
+	require.NoError(t, err)
+}
+
+func TestTokenRevocationList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	revocations := NewTokenRevocationList(store)
+	now := time.Now()
+
+	// Revocations of other instances come from the database.
+	store.EXPECT().ListRevokedTokens(gomock.Any(), gomock.Any()).
+		Return([]db.RevokedToken{{Jti: "remote", ExpiresAt: now.Add(time.Hour)}}, nil)
+	require.NoError(t, revocations.Load(context.Background()))
+
+	store.EXPECT().CreateRevokedToken(gomock.Any(), gomock.Any()).Return(nil)
+	require.NoError(t, revocations.Revoke(context.Background(), "local", 1, now.Add(time.Minute)))
+
+	require.True(t, revocations.IsRevoked("remote"))
+	require.True(t, revocations.IsRevoked("local"))
+	require.False(t, revocations.IsRevoked("other"))
+
+	revocations.prune(now.Add(2 * time.Minute))
+	require.True(t, revocations.IsRevoked("remote"))
+	require.False(t, revocations.IsRevoked("local"))
+}
+
+func TestTokenRevocationListRunWithoutPrune(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	revocations := NewTokenRevocationList(store)
+
+	// The revocations are still loaded with the cleanup turned off.
+	store.EXPECT().ListRevokedTokens(gomock.Any(), gomock.Any()).Return(nil, nil).MinTimes(1)
+	store.EXPECT().DeleteExpiredRevokedTokens(gomock.Any(), gomock.Any()).Times(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	revocations.Run(ctx, 10*time.Millisecond, 0)
+}
@@ -0,0 +1,296 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	recoveryCodeCount = 10
+	recoveryCodeSize  = 10
+	// loginChallengeTTL is the time the user has to enter the code.
+	loginChallengeTTL = 5 * time.Minute
+	// loginChallengeAttempts is the number of codes tried per challenge, a
+	// new one needs the password again.
+	loginChallengeAttempts = 5
+)
+
+var totpCodePattern = regexp.MustCompile(`^[0-9]{6}$`)
+
+// TwoFactor keeps the TOTP secrets and the recovery codes of the accounts.
+// The secrets are sealed with the server key when one is configured.
+type TwoFactor struct {
+	store      db.Store
+	keyWrapper *KeyWrapper
+	issuer     string
+}
+
+func NewTwoFactor(store db.Store, keyWrapper *KeyWrapper, issuer string) *TwoFactor {
+	return &TwoFactor{store: store, keyWrapper: keyWrapper, issuer: issuer}
+}
+
+// TOTPEnrollment is what the user needs to set up the authenticator app.
+type TOTPEnrollment struct {
+	ProvisioningURI string
+	Secret          string
+	RecoveryCodes   []string
+}
+
+func totpSecretAAD(accountID int64) string {
+	return fmt.Sprintf("totp:%d", accountID)
+}
+
+// newRecoveryCode returns a random code like abcd-efgh-ijkl-mnop.
+func newRecoveryCode() (string, error) {
+	raw := make([]byte, recoveryCodeSize)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw))
+	var groups []string
+	for len(code) > 4 {
+		groups = append(groups, code[:4])
+		code = code[4:]
+	}
+	return strings.Join(append(groups, code), "-"), nil
+}
+
+// newRecoveryCodes returns a fresh set of recovery codes with their hashes.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes the code the way it was handed out, whatever the
+// case and the separators the user types it with.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return hashRefreshToken(code)
+}
+
+// Enroll starts the enrollment of the account. Two-factor authentication is
+// enabled by Confirm with the first code of the app.
+func (t *TwoFactor) Enroll(ctx context.Context, acc db.Account) (TOTPEnrollment, error) {
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+
+	stored, sealed := secret, false
+	if t.keyWrapper != nil {
+		stored, err = t.keyWrapper.Seal(secret, totpSecretAAD(acc.ID))
+		if err != nil {
+			return TOTPEnrollment{}, err
+		}
+		sealed = true
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	enrollment := TOTPEnrollment{
+		ProvisioningURI: totpProvisioningURI(t.issuer, acc.Username, secret),
+		Secret:          totpEncoding.EncodeToString(secret),
+		RecoveryCodes:   codes,
+	}
+
+	_, err = t.store.EnrollTotpTx(ctx, db.EnrollTotpTxParams{
+		AccountID:          acc.ID,
+		Secret:             stored,
+		Sealed:             sealed,
+		RecoveryCodeHashes: hashes,
+	})
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+
+	return enrollment, nil
+}
+
+func (t *TwoFactor) secret(totp db.AccountTotp) ([]byte, error) {
+	if !totp.Sealed {
+		return totp.Secret, nil
+	}
+	if t.keyWrapper == nil {
+		return nil, fmt.Errorf("totp secret is sealed but the encryption key is not set")
+	}
+	return t.keyWrapper.Open(totp.Secret, totpSecretAAD(totp.AccountID))
+}
+
+// Enabled reports whether the account logs in with a second factor.
+func (t *TwoFactor) Enabled(ctx context.Context, accountID int64) (bool, error) {
+	totp, err := t.store.GetAccountTotp(ctx, accountID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return totp.Enabled, nil
+}
+
+// Confirm enables two-factor authentication once the code of the enrolled
+// secret matches. It reports false for a wrong code and for an account
+// without a pending enrollment.
+func (t *TwoFactor) Confirm(ctx context.Context, accountID int64, code string, now time.Time) (bool, error) {
+	totp, err := t.store.GetAccountTotp(ctx, accountID)
+	if err == sql.ErrNoRows || (err == nil && totp.Enabled) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	secret, err := t.secret(totp)
+	if err != nil {
+		return false, err
+	}
+
+	step, ok := matchTOTP(secret, code, now)
+	if !ok {
+		return false, nil
+	}
+
+	enabled, err := t.store.EnableAccountTotp(ctx, db.EnableAccountTotpParams{
+		AccountID: accountID,
+		LastStep:  step,
+	})
+	return enabled == 1, err
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of an account with
+// two-factor authentication enabled, otherwise it gives db.ErrTotpNotEnabled.
+func (t *TwoFactor) RegenerateRecoveryCodes(ctx context.Context, accountID int64) ([]string, error) {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = t.store.ReplaceRecoveryCodesTx(ctx, db.ReplaceRecoveryCodesTxParams{
+		AccountID:          accountID,
+		RecoveryCodeHashes: hashes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable removes the secret and the recovery codes, the login asks for the
+// password only from then on. It gives db.ErrTotpNotEnabled for an account
+// without two-factor authentication.
+func (t *TwoFactor) Disable(ctx context.Context, accountID int64) error {
+	return t.store.DisableTotpTx(ctx, accountID)
+}
+
+// Verify checks the second factor of a login, a code of the app or one of
+// the recovery codes. Each of them works once.
+func (t *TwoFactor) Verify(ctx context.Context, accountID int64, code string, now time.Time) (bool, error) {
+	if !totpCodePattern.MatchString(code) {
+		used, err := t.store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+			AccountID: accountID,
+			CodeHash:  hashRecoveryCode(code),
+			UsedAt:    sql.NullTime{Time: now, Valid: true},
+		})
+		if used == 1 {
+			log.Info().Msgf("Recovery code used by account %d", accountID)
+		}
+		return used == 1, err
+	}
+
+	totp, err := t.store.GetAccountTotp(ctx, accountID)
+	if err != nil {
+		return false, err
+	}
+
+	secret, err := t.secret(totp)
+	if err != nil {
+		return false, err
+	}
+
+	step, ok := matchTOTP(secret, code, now)
+	if !ok {
+		return false, nil
+	}
+
+	// The step only moves forward, a code seen once is rejected.
+	accepted, err := t.store.UseAccountTotpStep(ctx, db.UseAccountTotpStepParams{
+		AccountID: accountID,
+		LastStep:  step,
+	})
+	return accepted == 1, err
+}
+
+// NewChallenge returns the challenge a login completes with the second
+// factor. Only its hash is stored.
+func (t *TwoFactor) NewChallenge(ctx context.Context, accountID int64, now time.Time) (string, error) {
+	challenge, hash, err := newRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = t.store.CreateLoginChallenge(ctx, db.CreateLoginChallengeParams{
+		ChallengeHash: hash,
+		AccountID:     accountID,
+		ExpiresAt:     now.Add(loginChallengeTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return challenge, nil
+}
+
+// AttemptChallenge counts an attempt of the challenge and returns it. Expired
+// and used up challenges give sql.ErrNoRows.
+func (t *TwoFactor) AttemptChallenge(ctx context.Context, challenge string, now time.Time) (db.LoginChallenge, error) {
+	return t.store.AttemptLoginChallenge(ctx, db.AttemptLoginChallengeParams{
+		ChallengeHash: hashRefreshToken(challenge),
+		Now:           now,
+		MaxAttempts:   loginChallengeAttempts,
+	})
+}
+
+// RunCleanup deletes expired login challenges every interval until ctx is
+// done.
+func (t *TwoFactor) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := t.store.DeleteExpiredLoginChallenges(ctx, time.Now())
+			if err != nil {
+				log.Error().Err(err).Msg("cannot delete expired login challenges")
+			}
+			if deleted > 0 {
+				log.Info().Msgf("Deleted %d expired login challenges", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/Jay-T/go-devops-advanced-diploma/db/mock"
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/pb"
+	"github.com/Jay-T/go-devops-advanced-diploma/internal/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// The SHA1 vectors of RFC 6238, cut to six digits.
+	secret := []byte("12345678901234567890")
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.code, totpCode(secret, totpStep(time.Unix(tc.unix, 0))))
+	}
+
+	now := time.Unix(1111111109, 0)
+	step, ok := matchTOTP(secret, "081804", now.Add(totpPeriod*time.Second))
+	require.True(t, ok)
+	require.Equal(t, totpStep(now), step)
+
+	_, ok = matchTOTP(secret, "081804", now.Add(3*totpPeriod*time.Second))
+	require.False(t, ok)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(totpProvisioningURI("GophKeeper", "user@example.com", []byte("12345678901234567890")))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/GophKeeper:user@example.com", uri.Path)
+	require.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", uri.Query().Get("secret"))
+	require.Equal(t, "GophKeeper", uri.Query().Get("issuer"))
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	keyWrapper := newTestKeyWrapper(t)
+	jwtManager := newTestJWTManager(t, time.Minute)
+	accountStatus := NewAccountStatusCache(store, time.Hour)
+	revocations := NewTokenRevocationList(store)
+	interceptor := NewAuthInterceptor(jwtManager, accountStatus, revocations, nil, protectedMethods(), nil)
+	server := NewAuthServer(
+		store,
+		jwtManager,
+		accountStatus,
+		revocations,
+		NewLoginLimiter(store, LoginLimits{}),
+		NewTwoFactor(store, keyWrapper, "test"),
+		PasswordPolicy{},
+		nil,
+		time.Hour,
+	)
+
+	hash, err := util.HashPassword("secret")
+	require.NoError(t, err)
+	account := db.Account{ID: 3, Username: "user", Passhash: hash}
+	store.EXPECT().GetAccount(gomock.Any(), "user").Return(account, nil).AnyTimes()
+	store.EXPECT().GetAccountByID(gomock.Any(), account.ID).Return(account, nil).AnyTimes()
+	store.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(db.RefreshToken{}, nil).AnyTimes()
+
+	totp := db.AccountTotp{AccountID: account.ID}
+	store.EXPECT().GetAccountTotp(gomock.Any(), account.ID).
+		DoAndReturn(func(context.Context, int64) (db.AccountTotp, error) {
+			if totp.Secret == nil {
+				return db.AccountTotp{}, sql.ErrNoRows
+			}
+			return totp, nil
+		}).
+		AnyTimes()
+
+	res, err := server.Login(context.Background(), &pb.LoginRequest{Login: "user", Password: "secret"})
+	require.NoError(t, err)
+	require.Empty(t, res.GetChallenge())
+
+	ctx, err := interceptor.authorize(
+		metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", res.GetToken())),
+		"/go_devops_advanced_diploma.Authentication/Enroll2FA",
+	)
+	require.NoError(t, err)
+
+	// The access token alone is not enough.
+	_, err = server.Enroll2FA(ctx, &pb.Enroll2FARequest{Password: "wrong"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	var recoveryHashes []string
+	store.EXPECT().EnrollTotpTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.EnrollTotpTxParams) (db.AccountTotp, error) {
+			totp.Secret, totp.Sealed = arg.Secret, arg.Sealed
+			recoveryHashes = arg.RecoveryCodeHashes
+			return totp, nil
+		})
+
+	enrollment, err := server.Enroll2FA(ctx, &pb.Enroll2FARequest{Password: "secret"})
+	require.NoError(t, err)
+	require.Len(t, enrollment.GetRecoveryCodes(), recoveryCodeCount)
+	require.Len(t, recoveryHashes, recoveryCodeCount)
+	require.Contains(t, enrollment.GetProvisioningUri(), "secret="+enrollment.GetSecret())
+	// The secret is sealed in the database.
+	require.True(t, totp.Sealed)
+	secret, err := totpEncoding.DecodeString(enrollment.GetSecret())
+	require.NoError(t, err)
+	require.NotContains(t, string(totp.Secret), string(secret))
+
+	now := time.Now()
+	_, err = server.Confirm2FA(ctx, &pb.Confirm2FARequest{Code: totpCode(secret, totpStep(now)+10)})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	store.EXPECT().EnableAccountTotp(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.EnableAccountTotpParams) (int64, error) {
+			totp.Enabled, totp.LastStep = true, arg.LastStep
+			return 1, nil
+		})
+	_, err = server.Confirm2FA(ctx, &pb.Confirm2FARequest{Code: totpCode(secret, totpStep(now))})
+	require.NoError(t, err)
+
+	// The password alone gets a challenge, no tokens.
+	var challengeHash string
+	store.EXPECT().CreateLoginChallenge(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.CreateLoginChallengeParams) error {
+			challengeHash = arg.ChallengeHash
+			return nil
+		}).
+		Times(2)
+	res, err = server.Login(context.Background(), &pb.LoginRequest{Login: "user", Password: "secret"})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.GetChallenge())
+	require.Empty(t, res.GetToken())
+	require.Empty(t, res.GetRefreshToken())
+	require.Equal(t, hashRefreshToken(res.GetChallenge()), challengeHash)
+
+	store.EXPECT().AttemptLoginChallenge(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.AttemptLoginChallengeParams) (db.LoginChallenge, error) {
+			if arg.ChallengeHash != challengeHash {
+				return db.LoginChallenge{}, sql.ErrNoRows
+			}
+			return db.LoginChallenge{ID: 1, AccountID: account.ID}, nil
+		}).
+		AnyTimes()
+
+	_, err = server.CompleteLogin(context.Background(), &pb.CompleteLoginRequest{Challenge: "unknown", Code: "123456"})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// The code confirming the enrollment cannot be replayed.
+	store.EXPECT().UseAccountTotpStep(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.UseAccountTotpStepParams) (int64, error) {
+			if arg.LastStep <= totp.LastStep {
+				return 0, nil
+			}
+			totp.LastStep = arg.LastStep
+			return 1, nil
+		}).
+		AnyTimes()
+	_, err = server.CompleteLogin(context.Background(), &pb.CompleteLoginRequest{
+		Challenge: res.GetChallenge(),
+		Code:      totpCode(secret, totp.LastStep),
+	})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	store.EXPECT().DeleteLoginChallenge(gomock.Any(), int64(1)).Return(nil).Times(2)
+	completed, err := server.CompleteLogin(context.Background(), &pb.CompleteLoginRequest{
+		Challenge: res.GetChallenge(),
+		Code:      totpCode(secret, totp.LastStep+1),
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, completed.GetToken())
+	require.NotEmpty(t, completed.GetRefreshToken())
+
+	// A recovery code works instead of the app, typed in any case.
+	res, err = server.Login(context.Background(), &pb.LoginRequest{Login: "user", Password: "secret"})
+	require.NoError(t, err)
+	store.EXPECT().
+		UseRecoveryCode(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.UseRecoveryCodeParams) (int64, error) {
+			require.Equal(t, recoveryHashes[0], arg.CodeHash)
+			return 1, nil
+		})
+	_, err = server.CompleteLogin(context.Background(), &pb.CompleteLoginRequest{
+		Challenge: res.GetChallenge(),
+		Code:      strings.ToUpper(enrollment.GetRecoveryCodes()[0]),
+	})
+	require.NoError(t, err)
+
+	// An enabled second factor is not replaced by a new enrollment.
+	store.EXPECT().EnrollTotpTx(gomock.Any(), gomock.Any()).Return(db.AccountTotp{}, db.ErrTotpEnabled)
+	_, err = server.Enroll2FA(ctx, &pb.Enroll2FARequest{Password: "secret"})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// New recovery codes take the password and a second factor.
+	store.EXPECT().
+		UseRecoveryCode(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.UseRecoveryCodeParams) (int64, error) {
+			if arg.CodeHash == recoveryHashes[1] {
+				return 1, nil
+			}
+			return 0, nil
+		}).
+		Times(2)
+	_, err = server.RegenerateRecoveryCodes(ctx, &pb.RegenerateRecoveryCodesRequest{Password: "wrong", Code: enrollment.GetRecoveryCodes()[1]})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = server.RegenerateRecoveryCodes(ctx, &pb.RegenerateRecoveryCodesRequest{Password: "secret", Code: "not-a-code"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	store.EXPECT().
+		ReplaceRecoveryCodesTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.ReplaceRecoveryCodesTxParams) error {
+			require.Equal(t, account.ID, arg.AccountID)
+			require.Len(t, arg.RecoveryCodeHashes, recoveryCodeCount)
+			require.NotContains(t, arg.RecoveryCodeHashes, recoveryHashes[2])
+			recoveryHashes = arg.RecoveryCodeHashes
+			return nil
+		})
+	regenerated, err := server.RegenerateRecoveryCodes(ctx, &pb.RegenerateRecoveryCodesRequest{Password: "secret", Code: enrollment.GetRecoveryCodes()[1]})
+	require.NoError(t, err)
+	require.Len(t, regenerated.GetRecoveryCodes(), recoveryCodeCount)
+	require.Equal(t, recoveryHashes[0], hashRecoveryCode(regenerated.GetRecoveryCodes()[0]))
+
+	// Disabling takes the password and a second factor too.
+	_, err = server.Disable2FA(ctx, &pb.Disable2FARequest{Password: "wrong", Code: regenerated.GetRecoveryCodes()[0]})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	store.EXPECT().
+		UseRecoveryCode(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, arg db.UseRecoveryCodeParams) (int64, error) {
+			require.Equal(t, recoveryHashes[0], arg.CodeHash)
+			return 1, nil
+		})
+	store.EXPECT().
+		DisableTotpTx(gomock.Any(), account.ID).
+		DoAndReturn(func(context.Context, int64) error {
+			totp = db.AccountTotp{AccountID: account.ID}
+			return nil
+		})
+	_, err = server.Disable2FA(ctx, &pb.Disable2FARequest{Password: "secret", Code: regenerated.GetRecoveryCodes()[0]})
+	require.NoError(t, err)
+
+	res, err = server.Login(context.Background(), &pb.LoginRequest{Login: "user", Password: "secret"})
+	require.NoError(t, err)
+	require.Empty(t, res.GetChallenge())
+	require.NotEmpty(t, res.GetToken())
+
+	_, err = server.Disable2FA(ctx, &pb.Disable2FARequest{Password: "secret", Code: regenerated.GetRecoveryCodes()[1]})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
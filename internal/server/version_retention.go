@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/rs/zerolog/log"
+)
+
+// pruneBatchSize is the number of expired versions removed per query.
+const pruneBatchSize = 100
+
+// VersionRetention decides which old file versions are kept. A version is
+// removed once it is neither among the Keep newest versions of the file nor
+// younger than MaxAge.
+type VersionRetention struct {
+	Keep   int64
+	MaxAge time.Duration
+}
+
+func NewVersionRetention(cfg *Config) VersionRetention {
+	return VersionRetention{
+		Keep:   cfg.VersionsKeep,
+		MaxAge: cfg.VersionsAge,
+	}
+}
+
+// PruneFileVersions removes the versions the retention does not keep and
+// returns how many were removed.
+func (s *FileServer) PruneFileVersions(ctx context.Context, retention VersionRetention) (int, error) {
+	pruned := 0
+	before := time.Now().Add(-retention.MaxAge)
+
+	for {
+		versions, err := s.fileStore.ListExpiredFileVersions(ctx, db.ListExpiredFileVersionsParams{
+			Keep:    retention.Keep,
+			Before:  before,
+			MaxRows: pruneBatchSize,
+		})
+		if err != nil {
+			return pruned, err
+		}
+
+		for _, version := range versions {
+			releaser := newBlobReleaser(ctx, s.fileStore, s.fileContentSaver)
+			_, err = s.fileStore.DeleteFileVersionTx(ctx, db.DeleteFileVersionTxParams{
+				AccountID:    version.AccountID,
+				ID:           version.ID,
+				AfterRelease: releaser.AfterRelease,
+			})
+			// The version is gone already when the file was deleted meanwhile.
+			if err == sql.ErrNoRows {
+				continue
+			}
+			if err != nil {
+				return pruned, err
+			}
+			releaser.Committed()
+			pruned++
+		}
+
+		if len(versions) < pruneBatchSize {
+			return pruned, nil
+		}
+	}
+}
+
+// RunVersionRetention prunes old file versions every interval until ctx is done.
+func (s *FileServer) RunVersionRetention(ctx context.Context, retention VersionRetention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pruned, err := s.PruneFileVersions(ctx, retention)
+			if err != nil {
+				log.Error().Err(err).Msg("cannot prune file versions")
+			}
+			if pruned > 0 {
+				log.Info().Msgf("Pruned %d old file versions", pruned)
+			}
+		}
+	}
+}
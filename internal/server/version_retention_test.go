@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/Jay-T/go-devops-advanced-diploma/db/mock"
+	db "github.com/Jay-T/go-devops-advanced-diploma/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneFileVersions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := NewFileServer(store, NewDiskFileContentSaver(t.TempDir()), Quota{}, CodecIdentity, nil)
+	retention := VersionRetention{Keep: 3, MaxAge: time.Hour}
+
+	batch := make([]db.ListExpiredFileVersionsRow, pruneBatchSize)
+	for i := range batch {
+		batch[i] = db.ListExpiredFileVersionsRow{ID: int64(i + 1), AccountID: 1}
+	}
+
+	store.EXPECT().
+		ListExpiredFileVersions(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, arg db.ListExpiredFileVersionsParams) ([]db.ListExpiredFileVersionsRow, error) {
+			require.Equal(t, retention.Keep, arg.Keep)
+			require.WithinDuration(t, time.Now().Add(-retention.MaxAge), arg.Before, time.Minute)
+			return batch, nil
+		})
+	store.EXPECT().
+		ListExpiredFileVersions(gomock.Any(), gomock.Any()).
+		Return(batch[:2], nil)
+
+	store.EXPECT().
+		DeleteFileVersionTx(gomock.Any(), gomock.Any()).
+		Times(pruneBatchSize+1).
+		Return(db.FileVersion{}, nil)
+	// A version of a file deleted in the meantime is skipped.
+	store.EXPECT().
+		DeleteFileVersionTx(gomock.Any(), gomock.Any()).
+		Return(db.FileVersion{}, sql.ErrNoRows)
+
+	pruned, err := server.PruneFileVersions(context.Background(), retention)
+	require.NoError(t, err)
+	require.Equal(t, pruneBatchSize+1, pruned)
+}